@@ -0,0 +1,102 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/cmd/db"
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+var applyFile string
+var applyPrune bool
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "spec file in yaml or json describing the databases, - reads from stdin")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "also remove databases and regions that are not in the spec")
+	db.AddOverrideProtectionFlag(applyCmd)
+}
+
+// guardPrune checks the protection policy before --prune removes a database or one of its regions
+var guardPrune = func(a spec.Action) error {
+	name := a.Database
+	return db.GuardDb(astraops.Database{Id: a.ID, Info: astraops.DatabaseInfo{Name: &name}}, "prune")
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <spec>",
+	Short: "Changes the databases to match the spec",
+	Long:  `Computes the same plan as the plan command then executes it, waiting for each change to complete. Databases and regions are only removed with --prune, protected databases are left alone unless --override-protection is passed`,
+	Args:  cobra.NoArgs,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executeApply(creds.Login)
+		if msg != "" {
+			fmt.Println(msg)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func executeApply(makeClient func() (pkg.Client, error)) (string, error) {
+	var client pkg.Client
+	plan, err := makePlan(applyFile, applyPrune, func() (pkg.Client, error) {
+		var err error
+		client, err = makeClient()
+		return client, err
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Println(formatPlan(plan))
+	if len(plan.Actions) == 0 {
+		return "nothing to apply", nil
+	}
+	results := spec.Apply(client, plan, os.Stdout, guardPrune)
+	rows := [][]string{{"action", "result", "duration"}}
+	var failed int
+	for _, r := range results {
+		result := "ok"
+		switch {
+		case r.DryRun:
+			result = "dry run, not sent"
+		case r.Skipped:
+			result = r.Err.Error()
+		case r.Err != nil:
+			failed++
+			result = fmt.Sprintf("failed: %v", r.Err)
+		}
+		rows = append(rows, []string{r.Action.Description(), result, r.Duration.Round(time.Second).String()})
+	}
+	var buf bytes.Buffer
+	if err := pkg.WriteRows(&buf, rows); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	if failed > 0 {
+		return buf.String(), fmt.Errorf("%v of %v action(s) failed", failed, len(results))
+	}
+	return buf.String(), nil
+}
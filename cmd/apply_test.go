@@ -0,0 +1,132 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestApply(t *testing.T) {
+	applyFile = writeSpec(t, testSpec)
+	mockClient := &tests.MockClient{}
+	msg, err := executeApply(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(msg, "create database new (serverless GCP us-east1, keyspace ks1) ok") {
+		t.Errorf("expected summary of the create but was '%v'", msg)
+	}
+	creates := 0
+	for _, c := range mockClient.Calls() {
+		if _, ok := c.(astraops.DatabaseInfoCreate); ok {
+			creates++
+		}
+	}
+	if creates != 2 {
+		t.Errorf("expected 2 creates but was %v", creates)
+	}
+}
+
+func TestApplyFails(t *testing.T) {
+	applyFile = writeSpec(t, testSpec)
+	mockClient := &tests.MockClient{
+		ErrorQueue: []error{nil, errors.New("quota exceeded")},
+	}
+	msg, err := executeApply(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "1 of 2 action(s) failed"
+	if err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	if !strings.Contains(msg, "failed: quota exceeded") {
+		t.Errorf("expected the failure in the summary but was '%v'", msg)
+	}
+}
+
+func TestApplyPruneProtected(t *testing.T) {
+	applyFile = writeSpec(t, `
+databases:
+  - name: app
+    keyspaces: [ks1]
+    regions: [us-east1]
+`)
+	applyPrune = true
+	// setting package variables by hand, there be dragons
+	oldGuard := guardPrune
+	var guarded []string
+	guardPrune = func(a spec.Action) error {
+		guarded = append(guarded, a.ID)
+		return errors.New("database 'def' is protected")
+	}
+	t.Cleanup(func() {
+		applyPrune = false
+		guardPrune = oldGuard
+	})
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{
+			{Id: "abc", Status: astraops.StatusEnumACTIVE, Info: astraops.DatabaseInfo{Name: astraops.StringPtr("app"), Keyspace: astraops.StringPtr("ks1"), Region: astraops.StringPtr("us-east1")}},
+			{Id: "def", Status: astraops.StatusEnumACTIVE, Info: astraops.DatabaseInfo{Name: astraops.StringPtr("old"), Keyspace: astraops.StringPtr("ks1"), Region: astraops.StringPtr("us-east1")}},
+		},
+	}
+	msg, err := executeApply(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(guarded) != 1 || guarded[0] != "def" {
+		t.Errorf("expected the protection policy to be checked for def but was %v", guarded)
+	}
+	if !strings.Contains(msg, "failed: database 'def' is protected") {
+		t.Errorf("expected the protection failure in the summary but was '%v'", msg)
+	}
+	for _, c := range mockClient.Calls() {
+		if c == "def" {
+			t.Error("expected def not to be terminated")
+		}
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	applyFile = writeSpec(t, testSpec)
+	env.DryRun = true
+	t.Cleanup(func() {
+		env.DryRun = false
+	})
+	msg, err := executeApply(func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(msg, "create database new (serverless GCP us-east1, keyspace ks1) dry run, not sent") || strings.Contains(msg, " ok ") {
+		t.Errorf("expected the actions to be marked as dry run but was '%v'", msg)
+	}
+}
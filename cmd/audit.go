@@ -0,0 +1,122 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/audit"
+	"github.com/datastax-labs/astra-cli/pkg/bulk"
+	"github.com/spf13/cobra"
+)
+
+var auditSince string
+var auditFmt string
+
+func init() {
+	auditListCmd.Flags().StringVar(&auditSince, "since", "24h", "only show records this recent, a duration like 24h or 7d, a date like 2021-06-01 or an RFC3339 time")
+	auditListCmd.Flags().StringVarP(&auditFmt, "output", "o", pkg.TextFormat, "Output format for report default is text, can also be json")
+	auditCmd.AddCommand(auditListCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Shows the audit commands",
+	Long:  `Every call that changes a database is recorded in the audit log of the config directory with who made it, when, and how it went`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := cobraCmd.Usage(); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the audit records",
+	Long:  `Lists the records of the local audit log, including the rotated logs, oldest first`,
+	Args:  cobra.NoArgs,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		msg, warnings, err := executeAuditList(os.UserHomeDir, time.Now())
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, w)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(msg)
+	},
+}
+
+func executeAuditList(getHome func() (string, error), now time.Time) (string, []string, error) {
+	since, err := parseSince(auditSince, now)
+	if err != nil {
+		return "", nil, err
+	}
+	confDir, _, err := pkg.GetHome(getHome)
+	if err != nil {
+		return "", nil, err
+	}
+	records, warnings, err := audit.NewLog(confDir).Read(since)
+	if err != nil {
+		return "", nil, err
+	}
+	switch auditFmt {
+	case pkg.JSONFormat:
+		if records == nil {
+			records = []audit.Record{}
+		}
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to marshal audit records with error %v", err)
+		}
+		return string(b), warnings, nil
+	case pkg.TextFormat:
+		rows := [][]string{{"time", "user", "env", "command", "operation", "target", "outcome", "duration", "error"}}
+		for _, r := range records {
+			duration := (time.Duration(r.DurationMs) * time.Millisecond).Round(time.Second).String()
+			rows = append(rows, []string{r.Time.Local().Format(time.RFC3339), r.User, r.Env, r.Command, r.Operation, r.Target, r.Outcome, duration, r.Error})
+		}
+		var out bytes.Buffer
+		if err := pkg.WriteRows(&out, rows); err != nil {
+			return "", nil, fmt.Errorf("unexpected error writing text output %v", err)
+		}
+		return out.String(), warnings, nil
+	default:
+		return "", nil, fmt.Errorf("-o %q is not valid option", auditFmt)
+	}
+}
+
+// parseSince accepts a duration back from now, a date or an RFC3339 time
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := bulk.ParseAge(strings.TrimSpace(since))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse --since '%v', expected a duration like 24h or 7d, a date like 2021-06-01 or an RFC3339 time", since)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,139 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/audit"
+)
+
+func writeAudit(t *testing.T, home string, records ...audit.Record) {
+	confDir, _, err := pkg.GetHome(func() (string, error) { return home, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := audit.NewLog(confDir)
+	for _, r := range records {
+		if err := l.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuditList(t *testing.T) {
+	home := t.TempDir()
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+	writeAudit(t, home,
+		audit.Record{Time: now.Add(-48 * time.Hour), User: "old", Operation: "Park", Target: "abc", Outcome: audit.OutcomeSuccess},
+		audit.Record{Time: now.Add(-time.Hour), User: "jdoe", Operation: "Terminate", Target: "def", Outcome: audit.OutcomeFailure, Error: "timeout"},
+	)
+	// setting package variables by hand, there be dragons
+	auditSince = "24h"
+	auditFmt = pkg.JSONFormat
+	defer func() {
+		auditSince = "24h"
+		auditFmt = pkg.TextFormat
+	}()
+	msg, _, err := executeAuditList(func() (string, error) { return home, nil }, now)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var records []audit.Record
+	if err := json.Unmarshal([]byte(msg), &records); err != nil {
+		t.Fatalf("unable to parse output '%v' with error %v", msg, err)
+	}
+	if len(records) != 1 || records[0].User != "jdoe" {
+		t.Errorf("expected only the recent record but was %v", records)
+	}
+
+	auditFmt = pkg.TextFormat
+	auditSince = "2021-06-01"
+	msg, _, err = executeAuditList(func() (string, error) { return home, nil }, now)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lines := strings.Split(msg, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 records but was '%v'", msg)
+	}
+	if !strings.Contains(lines[2], "timeout") {
+		t.Errorf("expected the error in the output but was '%v'", lines[2])
+	}
+}
+
+func TestAuditListCorruptLine(t *testing.T) {
+	home := t.TempDir()
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+	writeAudit(t, home, audit.Record{Time: now.Add(-time.Hour), User: "jdoe", Operation: "Park", Target: "abc", Outcome: audit.OutcomeSuccess})
+	confDir, _, err := pkg.GetHome(func() (string, error) { return home, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path.Join(confDir, audit.FileName), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{\"time\":\"2021-06"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	msg, warnings, err := executeAuditList(func() (string, error) { return home, nil }, now)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(msg, "jdoe") {
+		t.Errorf("expected the good record in the output but was '%v'", msg)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "line 2 ") {
+		t.Errorf("expected a warning for line 2 but was %v", warnings)
+	}
+}
+
+func TestAuditListEmpty(t *testing.T) {
+	auditFmt = pkg.JSONFormat
+	defer func() {
+		auditFmt = pkg.TextFormat
+	}()
+	msg, _, err := executeAuditList(func() (string, error) { return path.Join(t.TempDir(), "nohome"), nil }, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if msg != "[]" {
+		t.Errorf("expected an empty array but was '%v'", msg)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+	since, err := parseSince("2d", now)
+	if err != nil || !since.Equal(now.Add(-48*time.Hour)) {
+		t.Errorf("unexpected since %v with error %v", since, err)
+	}
+	since, err = parseSince("2021-06-01T10:00:00Z", now)
+	if err != nil || !since.Equal(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected since %v with error %v", since, err)
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,50 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/cmd/data"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	dataCmd.AddCommand(data.RowsCmd)
+	dataCmd.AddCommand(data.QueryCmd)
+	dataCmd.AddCommand(data.GraphQLCmd)
+	dataCmd.AddCommand(data.LoadCmd)
+	dataCmd.AddCommand(data.UnloadCmd)
+}
+
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Shows all the data commands",
+	Long:  `Shows all the data commands. Read and change the data of your databases through the Stargate REST and GraphQL APIs with your token`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeData(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeData(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
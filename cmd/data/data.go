@@ -0,0 +1,217 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+var dataKeyspace string
+var dataTable string
+var dataFields string
+var dataPageSize int
+var dataPageState string
+var dataFmt = pkg.TextFormat
+var dataBody string
+
+// storedToken is the application token of astra-cli login, the data APIs do not take service accounts
+var storedToken = func() (string, error) {
+	creds := &pkg.Creds{}
+	token, _, err := creds.Stored()
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", errors.New("the data commands need a token, login with astra-cli login --token")
+	}
+	return token, nil
+}
+
+// addKeyspaceFlag adds the keyspace
+func addKeyspaceFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&dataKeyspace, "keyspace", "k", "", "keyspace to use, the keyspace of the database by default")
+}
+
+// addTableFlags adds the keyspace and the required table
+func addTableFlags(cmd *cobra.Command) {
+	addKeyspaceFlag(cmd)
+	cmd.Flags().StringVarP(&dataTable, "table", "t", "", "table to read or change, required")
+}
+
+// addReadFlags adds the columns and paging of commands returning rows
+func addReadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dataFields, "fields", "", "comma separated columns to return, all by default")
+	cmd.Flags().IntVar(&dataPageSize, "page-size", 0, "rows per page, the API default when not set")
+	cmd.Flags().StringVar(&dataPageState, "page-state", "", "page to return, printed after a page when there are more rows")
+}
+
+// addOutputFlag adds the output format of commands printing rows
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&dataFmt, "output", "o", pkg.TextFormat, "Output format for report default is text, can also be json")
+}
+
+// addBodyFlag adds the json row of the commands changing a row
+func addBodyFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dataBody, "data", "", "row as a json object, @file reads it from the file, required")
+}
+
+// openTable looks up the database and returns a client of its REST API with the keyspace of the table
+func openTable(idOrName string, makeClient func() (pkg.Client, error)) (*stargate.Client, string, error) {
+	if dataFmt != pkg.TextFormat && dataFmt != pkg.JSONFormat {
+		return nil, "", fmt.Errorf("-o %q is not valid option", dataFmt)
+	}
+	if dataTable == "" {
+		return nil, "", errors.New("--table is required")
+	}
+	client, keyspace, err := openDatabase(idOrName, makeClient)
+	if err != nil {
+		return nil, "", err
+	}
+	if keyspace == "" {
+		return nil, "", fmt.Errorf("database '%s' has no keyspace, pass one with -k", idOrName)
+	}
+	return client, keyspace, nil
+}
+
+// openDatabase looks up the database and returns a client of its APIs with -k or the keyspace of the database, which
+// can be empty
+func openDatabase(idOrName string, makeClient func() (pkg.Client, error)) (*stargate.Client, string, error) {
+	token, err := storedToken()
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := makeClient()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, idOrName)
+	if err != nil {
+		return nil, "", err
+	}
+	baseURL, err := stargate.BaseURL(db, pkg.Env)
+	if err != nil {
+		return nil, "", err
+	}
+	keyspace := dataKeyspace
+	if keyspace == "" && db.Info.Keyspace != nil {
+		keyspace = *db.Info.Keyspace
+	}
+	return stargate.NewClient(baseURL, token), keyspace, nil
+}
+
+// queryOptions are the read flags
+func queryOptions() stargate.QueryOptions {
+	var fields []string
+	for _, f := range strings.Split(dataFields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return stargate.QueryOptions{Fields: fields, PageSize: dataPageSize, PageState: dataPageState}
+}
+
+// readBody returns the json of --data, reading the file when it starts with @
+func readBody() ([]byte, error) {
+	if dataBody == "" {
+		return nil, errors.New("--data is required")
+	}
+	body := []byte(dataBody)
+	if strings.HasPrefix(dataBody, "@") {
+		b, err := os.ReadFile(dataBody[1:])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read '%v' with error %v", dataBody[1:], err)
+		}
+		body = b
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal(body, &row); err != nil {
+		return nil, fmt.Errorf("--data is not a json object with error %v", err)
+	}
+	return body, nil
+}
+
+// writeRows prints the rows as a table with a column per field sorted by name, or as json. The page state of the
+// next page follows the table
+func writeRows(rows stargate.Rows) (string, error) {
+	if dataFmt == pkg.JSONFormat {
+		b, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		return string(b), nil
+	}
+	if len(rows.Data) == 0 {
+		return "no rows found", nil
+	}
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows.Data {
+		for c := range row {
+			if !seen[c] {
+				seen[c] = true
+				columns = append(columns, c)
+			}
+		}
+	}
+	sort.Strings(columns)
+	table := [][]string{columns}
+	for _, row := range rows.Data {
+		var values []string
+		for _, c := range columns {
+			values = append(values, formatValue(row[c]))
+		}
+		table = append(table, values)
+	}
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, table); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	if rows.PageState != "" {
+		fmt.Fprintf(&out, "\nmore rows, pass --page-state %v for the next page", rows.PageState)
+	}
+	return out.String(), nil
+}
+
+// writeRow prints a single row returned by a change
+func writeRow(row map[string]interface{}) (string, error) {
+	return writeRows(stargate.Rows{Count: 1, Data: []map[string]interface{}{row}})
+}
+
+// formatValue prints text as is and everything else as json
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(b)
+	}
+}
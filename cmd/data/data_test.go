@@ -0,0 +1,249 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// restCall is a request received by the stand-in REST API
+type restCall struct {
+	Method string
+	Path   string
+	Query  string
+	Token  string
+	Body   string
+}
+
+// withData serves the REST API of the database from a stand-in answering every request with status and response
+func withData(t *testing.T, status int, response string) (*[]restCall, *tests.MockClient) {
+	return withDataAnswer(t, func(restCall) (int, string) {
+		return status, response
+	})
+}
+
+// withDataAnswer serves the REST API of the database from a stand-in answering each request with answer
+func withDataAnswer(t *testing.T, answer func(restCall) (int, string)) (*[]restCall, *tests.MockClient) {
+	// setting package variables by hand, there be dragons
+	var calls []restCall
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body %v", err)
+		}
+		call := restCall{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get(stargate.TokenHeader), string(body)}
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+		status, response := answer(call)
+		w.WriteHeader(status)
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Errorf("unable to write response %v", err)
+		}
+	}))
+	endpoint := ts.URL + "/api/rest"
+	keyspace := "ks1"
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{
+			Id:              "abc",
+			Info:            astraops.DatabaseInfo{Keyspace: &keyspace},
+			DataEndpointUrl: &endpoint,
+		}},
+	}
+	originalToken := storedToken
+	storedToken = func() (string, error) {
+		return "AstraCS:secret", nil
+	}
+	dataTable = "users"
+	t.Cleanup(func() {
+		ts.Close()
+		storedToken = originalToken
+		dataKeyspace = ""
+		dataTable = ""
+		dataFields = ""
+		dataPageSize = 0
+		dataPageState = ""
+		dataFmt = pkg.TextFormat
+		dataBody = ""
+		queryWhere = ""
+		loadFile = ""
+		loadFormat = ""
+		loadParallel = 4
+		loadChunkSize = 100
+		loadMap = []string{}
+		loadRejectFile = ""
+		unloadFile = ""
+		unloadFormat = ""
+		unloadBatchSize = 100
+		unloadMap = []string{}
+	})
+	return &calls, mockClient
+}
+
+func TestOpenTableUsesDatabaseKeyspace(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	client, keyspace, err := openTable("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if keyspace != "ks1" {
+		t.Errorf("expected ks1 but was %v", keyspace)
+	}
+	if !strings.HasPrefix(client.URL(""), "http://127.0.0.1:") || strings.HasSuffix(client.URL(""), "/api/rest") {
+		t.Errorf("expected the base url without /api/rest but was %v", client.URL(""))
+	}
+}
+
+func TestOpenTableKeyspaceFlag(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	dataKeyspace = "other"
+	_, keyspace, err := openTable("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if keyspace != "other" {
+		t.Errorf("expected other but was %v", keyspace)
+	}
+}
+
+func TestOpenTableNoKeyspace(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	mockClient.Databases[0].Info.Keyspace = nil
+	_, _, err := openTable("abc", func() (pkg.Client, error) { return mockClient, nil })
+	expected := "database 'abc' has no keyspace, pass one with -k"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestOpenTableRequiresTable(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	dataTable = ""
+	_, _, err := openTable("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err == nil || err.Error() != "--table is required" {
+		t.Errorf("expected table error but was '%v'", err)
+	}
+}
+
+func TestOpenTableInvalidFormat(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	dataFmt = "yaml"
+	_, _, err := openTable("abc", func() (pkg.Client, error) { return mockClient, nil })
+	expected := `-o "yaml" is not valid option`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestOpenTableNoToken(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	storedToken = func() (string, error) {
+		return "", errors.New("no token")
+	}
+	_, _, err := openTable("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err == nil || err.Error() != "no token" {
+		t.Errorf("expected no token but was '%v'", err)
+	}
+}
+
+func TestOpenTableLoginFails(t *testing.T) {
+	withData(t, http.StatusOK, "")
+	_, _, err := openTable("abc", func() (pkg.Client, error) { return nil, errors.New("bad creds") })
+	expected := "unable to login with error bad creds"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestQueryOptions(t *testing.T) {
+	withData(t, http.StatusOK, "")
+	dataFields = "id, name,,"
+	dataPageSize = 10
+	dataPageState = "abc"
+	opts := queryOptions()
+	if strings.Join(opts.Fields, "|") != "id|name" || opts.PageSize != 10 || opts.PageState != "abc" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}
+
+func TestReadBodyFromFile(t *testing.T) {
+	withData(t, http.StatusOK, "")
+	file := path.Join(t.TempDir(), "row.json")
+	if err := os.WriteFile(file, []byte(`{"id":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dataBody = "@" + file
+	body, err := readBody()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(body) != `{"id":1}` {
+		t.Errorf("unexpected body %s", body)
+	}
+}
+
+func TestReadBodyInvalid(t *testing.T) {
+	withData(t, http.StatusOK, "")
+	dataBody = `[1]`
+	if _, err := readBody(); err == nil || !strings.HasPrefix(err.Error(), "--data is not a json object") {
+		t.Errorf("expected json error but was '%v'", err)
+	}
+	dataBody = ""
+	if _, err := readBody(); err == nil || err.Error() != "--data is required" {
+		t.Errorf("expected required error but was '%v'", err)
+	}
+}
+
+func TestWriteRowsText(t *testing.T) {
+	withData(t, http.StatusOK, "")
+	out, err := writeRows(stargate.Rows{
+		Count:     2,
+		PageState: "next",
+		Data:      []map[string]interface{}{{"name": "ann", "id": 1.0}, {"id": 2.0, "tags": []interface{}{"a"}, "name": nil}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "id name tags\n1  ann  null\n2  null [\"a\"]\nmore rows, pass --page-state next for the next page"
+	if out != expected {
+		t.Errorf("expected\n%q\nbut was\n%q", expected, out)
+	}
+}
+
+func TestWriteRowsEmpty(t *testing.T) {
+	withData(t, http.StatusOK, "")
+	out, err := writeRows(stargate.Rows{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out != "no rows found" {
+		t.Errorf("expected no rows found but was %q", out)
+	}
+}
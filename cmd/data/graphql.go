@@ -0,0 +1,191 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+var graphqlQuery string
+var graphqlFile string
+var graphqlVars []string
+var graphqlSchema bool
+
+// stdin is where -f - reads the query from, tests replace it
+var stdin io.Reader = os.Stdin
+
+func init() {
+	addKeyspaceFlag(GraphQLCmd)
+	GraphQLCmd.Flags().StringVarP(&graphqlQuery, "query", "q", "", "GraphQL query or mutation")
+	GraphQLCmd.Flags().StringVarP(&graphqlFile, "file", "f", "", "file of the GraphQL query or mutation, - reads stdin")
+	GraphQLCmd.Flags().StringArrayVar(&graphqlVars, "var", []string{}, "variable as key=value, json values like 1, true or {} keep their type, can be repeated")
+	GraphQLCmd.Flags().BoolVar(&graphqlSchema, "schema", false, "send to the schema endpoint that creates and changes keyspaces and tables")
+}
+
+// GraphQLCmd sends a GraphQL document to the database
+var GraphQLCmd = &cobra.Command{
+	Use:   "graphql <id|name> [-k keyspace] -q query",
+	Short: "sends a GraphQL query",
+	Long: `sends the GraphQL query or mutation of -q or of the file of -f to the GraphQL API of the keyspace, or with --schema to
+the schema endpoint, and prints the json response. Fails when the response has errors, after printing it`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeGraphQL(args, creds.Login)
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// executeGraphQL returns the response, also when it has errors so it can be printed before failing
+func executeGraphQL(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	request, err := graphqlRequest()
+	if err != nil {
+		return "", err
+	}
+	client, keyspace, err := openDatabase(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	path := stargate.GraphQLSchemaPath
+	if !graphqlSchema {
+		if keyspace == "" {
+			return "", fmt.Errorf("database '%s' has no keyspace, pass one with -k", args[0])
+		}
+		path = stargate.GraphQLPath(keyspace)
+	}
+	// queries only read, they are sent like other read only requests
+	if env.DryRun && (graphqlSchema || isMutation(request.Query)) {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode request with error %v", err)
+		}
+		return "dry run, request not sent: " + stargate.Request(http.MethodPost, client.URL(path), body), nil
+	}
+	resp, err := client.GraphQL(path, request)
+	if err != nil {
+		return "", fmt.Errorf("unable to send GraphQL request with error %v", err)
+	}
+	b, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("unexpected error marshaling to json: '%v'", err)
+	}
+	return string(b), resp.Err()
+}
+
+// isMutation is true when an operation of the document is a mutation. Only the names outside of braces and
+// parentheses are looked at, these are the operation keywords and names, comments and strings are skipped
+func isMutation(document string) bool {
+	depth := 0
+	for i := 0; i < len(document); i++ {
+		c := document[i]
+		switch {
+		case c == '#':
+			for i < len(document) && document[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(document[i:], `"""`):
+			end := strings.Index(document[i+3:], `"""`)
+			if end < 0 {
+				return false
+			}
+			i += end + 5
+		case c == '"':
+			for i++; i < len(document) && document[i] != '"'; i++ {
+				if document[i] == '\\' {
+					i++
+				}
+			}
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+		case depth == 0 && isNameStart(c):
+			start := i
+			for i+1 < len(document) && (isNameStart(document[i+1]) || document[i+1] >= '0' && document[i+1] <= '9') {
+				i++
+			}
+			if document[start:i+1] == "mutation" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// graphqlRequest reads the query and parses the variables
+func graphqlRequest() (stargate.GraphQLRequest, error) {
+	var request stargate.GraphQLRequest
+	switch {
+	case graphqlQuery != "" && graphqlFile != "":
+		return request, errors.New("pass either -q or -f, not both")
+	case graphqlQuery != "":
+		request.Query = graphqlQuery
+	case graphqlFile == "-":
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return request, fmt.Errorf("unable to read stdin with error %v", err)
+		}
+		request.Query = string(b)
+	case graphqlFile != "":
+		b, err := os.ReadFile(graphqlFile)
+		if err != nil {
+			return request, fmt.Errorf("unable to read '%v' with error %v", graphqlFile, err)
+		}
+		request.Query = string(b)
+	default:
+		return request, errors.New("pass the query with -q or -f")
+	}
+	for _, v := range graphqlVars {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return request, fmt.Errorf("--var %q is not key=value", v)
+		}
+		key, value := parts[0], parts[1]
+		if request.Variables == nil {
+			request.Variables = make(map[string]interface{})
+		}
+		// numbers are kept as json.Number so bigint values keep their digits
+		var typed interface{}
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.UseNumber()
+		if err := dec.Decode(&typed); err != nil || dec.More() {
+			typed = value
+		}
+		request.Variables[key] = typed
+	}
+	return request, nil
+}
@@ -0,0 +1,210 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+)
+
+// withGraphQL resets the graphql flags after the test
+func withGraphQL(t *testing.T, status int, response string) (*[]restCall, pkg.Client) {
+	calls, mockClient := withData(t, status, response)
+	dataTable = ""
+	originalStdin := stdin
+	t.Cleanup(func() {
+		graphqlQuery = ""
+		graphqlFile = ""
+		graphqlVars = []string{}
+		graphqlSchema = false
+		stdin = originalStdin
+	})
+	return calls, mockClient
+}
+
+func TestGraphQL(t *testing.T) {
+	calls, mockClient := withGraphQL(t, http.StatusOK, `{"data":{"users":{"values":[{"name":"ann"}]}}}`)
+	graphqlQuery = "query($id: Int) { users(value: {id: $id}) { values { name } } }"
+	graphqlVars = []string{"id=1", "name=ann", "filter={\"a\":true}"}
+	out, err := executeGraphQL([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "{\n  \"data\": {\n    \"users\": {\n      \"values\": [\n        {\n          \"name\": \"ann\"\n        }\n      ]\n    }\n  }\n}"
+	if out != expected {
+		t.Errorf("expected %q but was %q", expected, out)
+	}
+	call := (*calls)[0]
+	if call.Method != http.MethodPost || call.Path != "/api/graphql/ks1" || call.Token != "AstraCS:secret" {
+		t.Errorf("unexpected call %+v", call)
+	}
+	body := `{"query":"query($id: Int) { users(value: {id: $id}) { values { name } } }","variables":{"filter":{"a":true},"id":1,"name":"ann"}}`
+	if call.Body != body {
+		t.Errorf("expected body %v but was %v", body, call.Body)
+	}
+}
+
+func TestGraphQLSchemaFromStdin(t *testing.T) {
+	calls, mockClient := withGraphQL(t, http.StatusOK, `{"data":{"keyspaces":[]}}`)
+	graphqlFile = "-"
+	graphqlSchema = true
+	stdin = strings.NewReader("{ keyspaces { name } }")
+	if _, err := executeGraphQL([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	call := (*calls)[0]
+	if call.Path != "/api/graphql-schema" || call.Body != `{"query":"{ keyspaces { name } }"}` {
+		t.Errorf("unexpected call %+v", call)
+	}
+}
+
+func TestGraphQLFromFile(t *testing.T) {
+	calls, mockClient := withGraphQL(t, http.StatusOK, `{"data":{}}`)
+	dataKeyspace = "other"
+	graphqlFile = path.Join(t.TempDir(), "users.graphql")
+	if err := os.WriteFile(graphqlFile, []byte("{ users { values { id } } }"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeGraphQL([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if (*calls)[0].Path != "/api/graphql/other" {
+		t.Errorf("unexpected call %+v", (*calls)[0])
+	}
+}
+
+func TestGraphQLResponseErrors(t *testing.T) {
+	_, mockClient := withGraphQL(t, http.StatusOK, `{"errors":[{"message":"Validation error of type FieldUndefined"}]}`)
+	graphqlQuery = "{ nope }"
+	out, err := executeGraphQL([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+	expected := "1 error(s) in response: Validation error of type FieldUndefined"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	if !strings.Contains(out, `"message": "Validation error of type FieldUndefined"`) {
+		t.Errorf("expected the response to be printed but was %q", out)
+	}
+}
+
+func TestGraphQLDryRun(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		schema bool
+		sent   bool
+	}{
+		{"mutation", "mutation { deleteusers(value: {id: 1}) { applied } }", false, false},
+		{"query", "query { users { values { id } } }", false, true},
+		{"schema", "{ keyspaces { name } }", true, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withGraphQL(t, http.StatusOK, `{"data":{}}`)
+			env.DryRun = true
+			t.Cleanup(func() {
+				env.DryRun = false
+			})
+			graphqlQuery = c.query
+			graphqlSchema = c.schema
+			out, err := executeGraphQL([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if c.sent {
+				if len(*calls) != 1 || strings.HasPrefix(out, "dry run") {
+					t.Errorf("expected the query to be sent but was %q with calls %v", out, *calls)
+				}
+				return
+			}
+			if !strings.HasPrefix(out, "dry run, request not sent: POST ") {
+				t.Errorf("unexpected output %q", out)
+			}
+			if len(*calls) != 0 {
+				t.Errorf("expected no calls but was %v", *calls)
+			}
+		})
+	}
+}
+
+func TestIsMutation(t *testing.T) {
+	cases := map[string]bool{
+		"mutation { deleteusers(value: {id: 1}) { applied } }":                              true,
+		"  mutation Remove($id: Int) { deleteusers(value: {id: $id}) { applied } }":         true,
+		"query Get { users { values { id } } }\nmutation { a }":                             true,
+		"{ users { values { id } } }":                                                       false,
+		"query { mutation: users { values { id } } }":                                       false,
+		"# mutation\nquery { users(filter: {name: {eq: \"mutation\"}}) { values { id } } }": false,
+		`query { users(filter: {name: {eq: """mutation"""}}) { values { id } } }`:           false,
+	}
+	for document, expected := range cases {
+		if isMutation(document) != expected {
+			t.Errorf("expected %v for %q", expected, document)
+		}
+	}
+}
+
+func TestGraphQLRequestErrors(t *testing.T) {
+	withGraphQL(t, http.StatusOK, "")
+	cases := []struct {
+		query    string
+		file     string
+		vars     []string
+		expected string
+	}{
+		{"", "", nil, "pass the query with -q or -f"},
+		{"{ a }", "a.graphql", nil, "pass either -q or -f, not both"},
+		{"{ a }", "", []string{"novalue"}, `--var "novalue" is not key=value`},
+		{"{ a }", "", []string{"=1"}, `--var "=1" is not key=value`},
+	}
+	for _, c := range cases {
+		graphqlQuery, graphqlFile, graphqlVars = c.query, c.file, c.vars
+		if _, err := graphqlRequest(); err == nil || err.Error() != c.expected {
+			t.Errorf("expected '%v' but was '%v'", c.expected, err)
+		}
+	}
+}
+
+func TestGraphQLVariableTypes(t *testing.T) {
+	withGraphQL(t, http.StatusOK, "")
+	graphqlQuery = "{ a }"
+	graphqlVars = []string{"n=1.5", "b=false", "s=plain text", "q=\"1\"", "e=", "big=9007199254740993", "two=1 2"}
+	request, err := graphqlRequest()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := map[string]interface{}{"n": json.Number("1.5"), "b": false, "s": "plain text", "q": "1", "e": "", "big": json.Number("9007199254740993"), "two": "1 2"}
+	if !reflect.DeepEqual(request.Variables, expected) {
+		t.Errorf("expected %v but was %v", expected, request.Variables)
+	}
+}
+
+func TestGraphQLFails(t *testing.T) {
+	_, mockClient := withGraphQL(t, http.StatusUnauthorized, `{"description":"invalid token"}`)
+	graphqlQuery = "{ a }"
+	_, err := executeGraphQL([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+	expected := "unable to send GraphQL request with error invalid token (status 401)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
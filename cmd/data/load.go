@@ -0,0 +1,187 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/transfer"
+	"github.com/spf13/cobra"
+)
+
+var loadFile string
+var loadFormat string
+var loadParallel int
+var loadChunkSize int
+var loadMap []string
+var loadRejectFile string
+
+func init() {
+	addTableFlags(LoadCmd)
+	LoadCmd.Flags().StringVarP(&loadFile, "file", "f", "", "csv or json lines file to load, - reads stdin, required")
+	LoadCmd.Flags().StringVar(&loadFormat, "format", "", "csv or jsonl, by default the format of the file extension")
+	LoadCmd.Flags().IntVar(&loadParallel, "parallel", 4, "rows inserted at a time")
+	LoadCmd.Flags().IntVar(&loadChunkSize, "chunk-size", 100, "records read ahead of the inserts, progress is reported every chunk-size records")
+	LoadCmd.Flags().StringArrayVar(&loadMap, "map", []string{}, "field=column, loads the field of the file into the column, only mapped fields are loaded, can be repeated")
+	LoadCmd.Flags().StringVar(&loadRejectFile, "reject-file", "", "file the rejected records are written to in the format of the input, to fix and load again")
+}
+
+// LoadCmd inserts the records of a file into a table
+var LoadCmd = &cobra.Command{
+	Use:   "load <id|name> -t table -f file",
+	Short: "loads a csv or json lines file into a table",
+	Long: `inserts a row per record of the csv or json lines file into the table, --parallel rows at a time. Csv needs a header
+line with the field names and its values are sent as text, empty values leave the column unset. Records the table rejects, csv
+records with another number of fields than the header and json lines that do not parse are reported and written to --reject-file,
+and the command fails when there are any. Progress is printed to stderr every --chunk-size records`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeLoad(args, creds.Login, os.Stderr)
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// executeLoad returns the summary, also when rows were rejected so it can be printed before failing
+func executeLoad(args []string, makeClient func() (pkg.Client, error), progress io.Writer) (string, error) {
+	if loadFile == "" {
+		return "", errors.New("--file is required")
+	}
+	format, err := transfer.Format(loadFormat, loadFile)
+	if err != nil {
+		return "", err
+	}
+	mapping, err := transfer.ParseMapping(loadMap)
+	if err != nil {
+		return "", err
+	}
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	in, closeIn, err := openLoadFile()
+	if err != nil {
+		return "", err
+	}
+	defer closeIn()
+	reader, err := transfer.NewReader(in, format)
+	if err != nil {
+		return "", err
+	}
+	insert := func(row map[string]interface{}) error {
+		body, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("unable to encode row with error %v", err)
+		}
+		_, err = client.AddRow(keyspace, dataTable, body)
+		return err
+	}
+	if env.DryRun {
+		insert = func(map[string]interface{}) error { return nil }
+	}
+	rejects := &rejectFile{path: loadRejectFile, format: format, header: reader.Header(), out: progress}
+	p, err := transfer.Load(reader, mapping, transfer.LoadOptions{ChunkSize: loadChunkSize, Parallel: loadParallel}, insert, rejects.write, func(p transfer.Progress) {
+		fmt.Fprintf(progress, "loaded %v\n", p)
+	})
+	if closeErr := rejects.close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to load '%v' with error %v", loadFile, err)
+	}
+	if env.DryRun {
+		return fmt.Sprintf("dry run, %v rows read would be loaded into %v.%v", p.Rows, keyspace, dataTable), nil
+	}
+	summary := fmt.Sprintf("loaded %v into %v.%v", p, keyspace, dataTable)
+	if p.Rejected > 0 {
+		return summary, rejects.failure(p.Rejected)
+	}
+	return summary, nil
+}
+
+// openLoadFile opens the file of -f or stdin
+func openLoadFile() (io.Reader, func(), error) {
+	if loadFile == "-" {
+		return stdin, func() {}, nil
+	}
+	f, err := os.Open(loadFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read '%v' with error %v", loadFile, err)
+	}
+	return f, func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "warn unable to close '%v' with error %v\n", loadFile, err)
+		}
+	}, nil
+}
+
+// rejectFile reports rejected records and writes them to the reject file, created with the first one
+type rejectFile struct {
+	path   string
+	format string
+	header []string
+	out    io.Writer
+	file   *os.File
+	writer transfer.Writer
+}
+
+func (r *rejectFile) write(record transfer.Record, cause error) error {
+	fmt.Fprintf(r.out, "line %v rejected with error %v\n", record.Line, cause)
+	if r.path == "" {
+		return nil
+	}
+	if r.writer == nil {
+		f, err := os.OpenFile(r.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		r.file = f
+		r.writer = transfer.NewWriter(f, r.format, r.header)
+	}
+	if record.Fields == nil {
+		return r.writer.WriteRaw(record.Raw)
+	}
+	return r.writer.Write(record.Fields)
+}
+
+func (r *rejectFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+	if err := r.writer.Flush(); err != nil {
+		return fmt.Errorf("unable to write '%v' with error %v", r.path, err)
+	}
+	return r.file.Close()
+}
+
+func (r *rejectFile) failure(rejected int) error {
+	if r.path == "" {
+		return fmt.Errorf("%v rows rejected, pass --reject-file to keep them", rejected)
+	}
+	return fmt.Errorf("%v rows rejected, written to '%v'", rejected, r.path)
+}
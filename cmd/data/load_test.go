@@ -0,0 +1,212 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+)
+
+// rejectBad answers inserts of rows named bad with an error
+func rejectBad(call restCall) (int, string) {
+	if strings.Contains(call.Body, `"bad"`) {
+		return http.StatusBadRequest, `{"description":"invalid value"}`
+	}
+	return http.StatusCreated, `{"id":"1"}`
+}
+
+// writeLoadFile writes the file to load and points -f at it
+func writeLoadFile(t *testing.T, name, content string) {
+	loadFile = path.Join(t.TempDir(), name)
+	if err := os.WriteFile(loadFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	calls, mockClient := withDataAnswer(t, rejectBad)
+	writeLoadFile(t, "users.csv", "uid,name,extra\n1,ann,x\n2,bob,y\n3,cat,z\n")
+	loadMap = []string{"uid=id", "name=name"}
+	loadChunkSize = 2
+	var progress bytes.Buffer
+	out, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &progress)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(out, "loaded 3 rows, 0 rejected in ") || !strings.HasSuffix(out, " into ks1.users") {
+		t.Errorf("unexpected summary %q", out)
+	}
+	var bodies []string
+	for _, call := range *calls {
+		if call.Method != http.MethodPost || call.Path != "/api/rest/v2/keyspaces/ks1/users" {
+			t.Errorf("unexpected call %+v", call)
+		}
+		bodies = append(bodies, call.Body)
+	}
+	sort.Strings(bodies)
+	expected := []string{`{"id":"1","name":"ann"}`, `{"id":"2","name":"bob"}`, `{"id":"3","name":"cat"}`}
+	if strings.Join(bodies, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v but was %v", expected, bodies)
+	}
+	if lines := strings.Split(strings.TrimSpace(progress.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "loaded 2 rows, 0 rejected") {
+		t.Errorf("expected progress after each batch but was %q", progress.String())
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	_, mockClient := withDataAnswer(t, rejectBad)
+	writeLoadFile(t, "users.csv", "id,name\n1,ann\n2,bad\n3,\"bad\"\n")
+	loadRejectFile = path.Join(t.TempDir(), "rejected.csv")
+	var progress bytes.Buffer
+	out, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &progress)
+	expected := "2 rows rejected, written to '" + loadRejectFile + "'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	if !strings.HasPrefix(out, "loaded 1 rows, 2 rejected") {
+		t.Errorf("unexpected summary %q", out)
+	}
+	if !strings.Contains(progress.String(), "line 3 rejected with error invalid value (status 400)") {
+		t.Errorf("expected the rejected line to be reported but was %q", progress.String())
+	}
+	rejected, readErr := os.ReadFile(loadRejectFile)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if string(rejected) != "id,name\n2,bad\n3,bad\n" {
+		t.Errorf("unexpected reject file %q", rejected)
+	}
+}
+
+func TestLoadRejectsWithoutFile(t *testing.T) {
+	_, mockClient := withDataAnswer(t, rejectBad)
+	writeLoadFile(t, "users.jsonl", "{\"id\":1,\"name\":\"bad\"}\n")
+	_, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	expected := "1 rows rejected, pass --reject-file to keep them"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestLoadJSONLinesFromStdin(t *testing.T) {
+	calls, mockClient := withDataAnswer(t, rejectBad)
+	loadFile = "-"
+	loadFormat = pkg.JSONLinesFormat
+	originalStdin := stdin
+	t.Cleanup(func() {
+		stdin = originalStdin
+	})
+	stdin = strings.NewReader("{\"id\":1,\"tags\":[\"a\"]}\n")
+	if _, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0].Body != `{"id":1,"tags":["a"]}` {
+		t.Errorf("unexpected calls %v", *calls)
+	}
+}
+
+func TestLoadDryRun(t *testing.T) {
+	calls, mockClient := withDataAnswer(t, rejectBad)
+	env.DryRun = true
+	t.Cleanup(func() {
+		env.DryRun = false
+	})
+	writeLoadFile(t, "users.csv", "id,name\n1,ann\n2,bad\n")
+	out, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out != "dry run, 2 rows read would be loaded into ks1.users" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected no calls but was %v", *calls)
+	}
+}
+
+func TestLoadInvalidFlags(t *testing.T) {
+	_, mockClient := withDataAnswer(t, rejectBad)
+	cases := []struct {
+		file     string
+		format   string
+		mapping  []string
+		expected string
+	}{
+		{"", "", nil, "--file is required"},
+		{"users.txt", "", nil, "unable to tell the format of 'users.txt', pass --format csv or jsonl"},
+		{"users.csv", "", []string{"id"}, `--map "id" is not field=column`},
+		{"missing.csv", "", nil, "unable to read 'missing.csv' with error open missing.csv: no such file or directory"},
+	}
+	for _, c := range cases {
+		loadFile, loadFormat, loadMap = c.file, c.format, c.mapping
+		_, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("expected '%v' but was '%v'", c.expected, err)
+		}
+	}
+}
+
+func TestLoadRejectsUnparseableRecords(t *testing.T) {
+	cases := []struct {
+		file     string
+		content  string
+		rejected string
+		reported string
+		count    int
+	}{
+		{"users.csv", "id,name\n1,ann\n2,bob,extra\n3\n", "id,name\n2,bob,extra\n3\n", "line 3 rejected with error found 3 fields but the header has 2", 2},
+		{"users.jsonl", "{\"id\":1}\nnot json\n{\"id\":3}\n", "not json\n", "line 2 rejected with error unable to parse json object", 1},
+	}
+	for _, c := range cases {
+		_, mockClient := withDataAnswer(t, rejectBad)
+		writeLoadFile(t, c.file, c.content)
+		loadRejectFile = path.Join(t.TempDir(), "rejected"+path.Ext(c.file))
+		var progress bytes.Buffer
+		_, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &progress)
+		expected := fmt.Sprintf("%v rows rejected, written to '%v'", c.count, loadRejectFile)
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected '%v' but was '%v'", expected, err)
+		}
+		if !strings.Contains(progress.String(), c.reported) {
+			t.Errorf("expected '%v' in %q", c.reported, progress.String())
+		}
+		rejected, readErr := os.ReadFile(loadRejectFile)
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		if string(rejected) != c.rejected {
+			t.Errorf("expected reject file %q but was %q", c.rejected, rejected)
+		}
+	}
+}
+
+func TestLoadUnreadableFile(t *testing.T) {
+	_, mockClient := withDataAnswer(t, rejectBad)
+	writeLoadFile(t, "users.csv", "id,name\n1,\"ann\n")
+	_, err := executeLoad([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "unable to read csv with error") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
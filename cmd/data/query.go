@@ -0,0 +1,67 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+var queryWhere string
+
+func init() {
+	addTableFlags(QueryCmd)
+	addReadFlags(QueryCmd)
+	addOutputFlag(QueryCmd)
+	QueryCmd.Flags().StringVarP(&queryWhere, "where", "w", "", `where clause as json, for example {"name":{"$eq":"ann"}}, required`)
+}
+
+// QueryCmd reads the rows matching a where clause
+var QueryCmd = &cobra.Command{
+	Use:   "query <id|name> -t table --where '{json}'",
+	Short: "queries the rows of a table",
+	Long: `returns the rows of the table matching the json where clause of the REST API, each column maps operators like $eq, $gt,
+$lt and $in to values. The where clause has to restrict the partition key unless the table has an index on the column`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeQuery(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeQuery(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	if queryWhere == "" {
+		return "", errors.New("--where is required")
+	}
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	rows, err := client.QueryRows(keyspace, dataTable, queryWhere, queryOptions())
+	if err != nil {
+		return "", fmt.Errorf("unable to query %v.%v with error %v", keyspace, dataTable, err)
+	}
+	return writeRows(rows)
+}
@@ -0,0 +1,70 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+)
+
+func TestQuery(t *testing.T) {
+	calls, mockClient := withData(t, http.StatusOK, `{"count":1,"pageState":"p2","data":[{"id":1,"name":"ann"}]}`)
+	queryWhere = `{"name":{"$eq":"ann"}}`
+	dataPageSize = 1
+	out, err := executeQuery([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "id name\n1  ann\nmore rows, pass --page-state p2 for the next page"
+	if out != expected {
+		t.Errorf("expected %q but was %q", expected, out)
+	}
+	call := (*calls)[0]
+	if call.Method != http.MethodGet || call.Path != "/api/rest/v2/keyspaces/ks1/users" {
+		t.Errorf("unexpected call %+v", call)
+	}
+	query, err := url.ParseQuery(call.Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("where") != queryWhere || query.Get("page-size") != "1" {
+		t.Errorf("unexpected query %v", call.Query)
+	}
+}
+
+func TestQueryRequiresWhere(t *testing.T) {
+	_, mockClient := withData(t, http.StatusOK, "")
+	_, err := executeQuery([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+	if err == nil || err.Error() != "--where is required" {
+		t.Errorf("expected where error but was '%v'", err)
+	}
+}
+
+func TestQueryInvalidWhere(t *testing.T) {
+	calls, mockClient := withData(t, http.StatusOK, "")
+	queryWhere = `{"name":`
+	_, err := executeQuery([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil })
+	expected := `unable to query ks1.users with error where clause is not valid json: {"name":`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected no calls but was %v", *calls)
+	}
+}
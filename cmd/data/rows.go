@@ -0,0 +1,50 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RowsCmd.AddCommand(RowsGetCmd)
+	RowsCmd.AddCommand(RowsPutCmd)
+	RowsCmd.AddCommand(RowsPatchCmd)
+	RowsCmd.AddCommand(RowsDeleteCmd)
+}
+
+// RowsCmd is the parent command for reading and changing rows by primary key
+var RowsCmd = &cobra.Command{
+	Use:   "rows",
+	Short: "Shows all the rows commands",
+	Long: `Shows all the rows commands. Get, put, patch and delete the rows of a table by primary key. Key values are passed in
+primary key order after the database, partition key first then clustering columns`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeRows(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeRows(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addTableFlags(RowsDeleteCmd)
+}
+
+// RowsDeleteCmd deletes the rows of a primary key
+var RowsDeleteCmd = &cobra.Command{
+	Use:   "delete <id|name> <key>... -t table",
+	Short: "deletes the rows of a primary key",
+	Long:  `deletes the row of the primary key, or every row of the partition when only the partition key is passed`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeRowsDelete(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeRowsDelete(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	key := args[1:]
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(http.MethodDelete, client.URL(stargate.RowsPath(keyspace, dataTable, key...)), nil), nil
+	}
+	if err := client.DeleteRows(keyspace, dataTable, key); err != nil {
+		return "", fmt.Errorf("unable to delete rows of %v.%v with error %v", keyspace, dataTable, err)
+	}
+	return fmt.Sprintf("deleted rows of %v.%v with key %v", keyspace, dataTable, strings.Join(key, ", ")), nil
+}
@@ -0,0 +1,59 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addTableFlags(RowsGetCmd)
+	addReadFlags(RowsGetCmd)
+	addOutputFlag(RowsGetCmd)
+}
+
+// RowsGetCmd reads the rows of a primary key
+var RowsGetCmd = &cobra.Command{
+	Use:   "get <id|name> <key>... -t table",
+	Short: "gets the rows of a primary key",
+	Long:  `gets the rows of the primary key, or of the partition when only the partition key is passed`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeRowsGet(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeRowsGet(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	rows, err := client.GetRows(keyspace, dataTable, args[1:], queryOptions())
+	if err != nil {
+		return "", fmt.Errorf("unable to get rows of %v.%v with error %v", keyspace, dataTable, err)
+	}
+	return writeRows(rows)
+}
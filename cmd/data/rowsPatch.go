@@ -0,0 +1,70 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addTableFlags(RowsPatchCmd)
+	addBodyFlag(RowsPatchCmd)
+	addOutputFlag(RowsPatchCmd)
+}
+
+// RowsPatchCmd changes some columns of a row
+var RowsPatchCmd = &cobra.Command{
+	Use:   "patch <id|name> <key>... -t table --data '{json}'",
+	Short: "changes columns of a row",
+	Long:  `sets the columns of --data on the row of the primary key, the other columns are left as they are`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeRowsPatch(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeRowsPatch(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	body, err := readBody()
+	if err != nil {
+		return "", err
+	}
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	key := args[1:]
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(http.MethodPatch, client.URL(stargate.RowsPath(keyspace, dataTable, key...)), body), nil
+	}
+	row, err := client.UpdateRow(keyspace, dataTable, key, body)
+	if err != nil {
+		return "", fmt.Errorf("unable to patch row in %v.%v with error %v", keyspace, dataTable, err)
+	}
+	return writeRow(row)
+}
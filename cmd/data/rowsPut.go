@@ -0,0 +1,80 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addTableFlags(RowsPutCmd)
+	addBodyFlag(RowsPutCmd)
+	addOutputFlag(RowsPutCmd)
+}
+
+// RowsPutCmd writes a whole row
+var RowsPutCmd = &cobra.Command{
+	Use:   "put <id|name> [key...] -t table --data '{json}'",
+	Short: "adds or replaces a row",
+	Long: `without key values adds the row of --data, which has to contain the primary key. With key values replaces every column
+of the row of the primary key, columns left out of --data are removed. Use patch to change only some columns`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeRowsPut(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeRowsPut(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	body, err := readBody()
+	if err != nil {
+		return "", err
+	}
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	key := args[1:]
+	method := http.MethodPut
+	if len(key) == 0 {
+		method = http.MethodPost
+	}
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(method, client.URL(stargate.RowsPath(keyspace, dataTable, key...)), body), nil
+	}
+	var row map[string]interface{}
+	if len(key) == 0 {
+		row, err = client.AddRow(keyspace, dataTable, body)
+	} else {
+		row, err = client.ReplaceRow(keyspace, dataTable, key, body)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to put row in %v.%v with error %v", keyspace, dataTable, err)
+	}
+	return writeRow(row)
+}
@@ -0,0 +1,164 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+)
+
+// rowsCommand runs one of the rows commands with the client
+type rowsCommand func(args []string, makeClient func() (pkg.Client, error)) (string, error)
+
+func TestRows(t *testing.T) {
+	cases := []struct {
+		name     string
+		run      rowsCommand
+		args     []string
+		status   int
+		response string
+		setup    func()
+		expected string
+		call     restCall
+	}{
+		{
+			name: "get", run: executeRowsGet, args: []string{"abc", "1"},
+			status: http.StatusOK, response: `{"count":1,"data":[{"id":1,"name":"ann"}]}`,
+			setup:    func() { dataFields = "id,name" },
+			expected: "id name\n1  ann",
+			call:     restCall{Method: http.MethodGet, Path: "/api/rest/v2/keyspaces/ks1/users/1", Query: "fields=id%2Cname", Token: "AstraCS:secret"},
+		},
+		{
+			name: "get json", run: executeRowsGet, args: []string{"abc", "1"},
+			status: http.StatusOK, response: `{"count":1,"data":[{"id":1}]}`,
+			setup:    func() { dataFmt = pkg.JSONFormat },
+			expected: "{\n  \"count\": 1,\n  \"data\": [\n    {\n      \"id\": 1\n    }\n  ]\n}",
+			call:     restCall{Method: http.MethodGet, Path: "/api/rest/v2/keyspaces/ks1/users/1", Token: "AstraCS:secret"},
+		},
+		{
+			name: "delete", run: executeRowsDelete, args: []string{"abc", "1", "a b"},
+			status:   http.StatusNoContent,
+			expected: "deleted rows of ks1.users with key 1, a b",
+			call:     restCall{Method: http.MethodDelete, Path: "/api/rest/v2/keyspaces/ks1/users/1/a b", Token: "AstraCS:secret"},
+		},
+		{
+			name: "patch", run: executeRowsPatch, args: []string{"abc", "1"},
+			status: http.StatusOK, response: `{"data":{"name":"bob"}}`,
+			setup:    func() { dataBody = `{"name":"bob"}` },
+			expected: "name\nbob",
+			call:     restCall{Method: http.MethodPatch, Path: "/api/rest/v2/keyspaces/ks1/users/1", Token: "AstraCS:secret", Body: `{"name":"bob"}`},
+		},
+		{
+			name: "put adds", run: executeRowsPut, args: []string{"abc"},
+			status: http.StatusCreated, response: `{"id":"1"}`,
+			setup:    func() { dataBody = `{"id":1,"name":"ann"}` },
+			expected: "id\n1",
+			call:     restCall{Method: http.MethodPost, Path: "/api/rest/v2/keyspaces/ks1/users", Token: "AstraCS:secret", Body: `{"id":1,"name":"ann"}`},
+		},
+		{
+			name: "put replaces", run: executeRowsPut, args: []string{"abc", "1"},
+			status: http.StatusOK, response: `{"data":{"name":"bob"}}`,
+			setup:    func() { dataBody = `{"name":"bob"}` },
+			expected: "name\nbob",
+			call:     restCall{Method: http.MethodPut, Path: "/api/rest/v2/keyspaces/ks1/users/1", Token: "AstraCS:secret", Body: `{"name":"bob"}`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withData(t, c.status, c.response)
+			if c.setup != nil {
+				c.setup()
+			}
+			out, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if out != c.expected {
+				t.Errorf("expected %q but was %q", c.expected, out)
+			}
+			if len(*calls) != 1 || (*calls)[0] != c.call {
+				t.Errorf("expected call %+v but was %+v", c.call, *calls)
+			}
+		})
+	}
+}
+
+func TestRowsDryRun(t *testing.T) {
+	cases := []struct {
+		name   string
+		run    rowsCommand
+		args   []string
+		body   string
+		prefix string
+		suffix string
+	}{
+		{"delete", executeRowsDelete, []string{"abc", "1"}, "", "DELETE ", "/api/rest/v2/keyspaces/ks1/users/1"},
+		{"patch", executeRowsPatch, []string{"abc", "1"}, `{"name":"bob"}`, "PATCH ", `/users/1 {"name":"bob"}`},
+		{"put", executeRowsPut, []string{"abc"}, `{"id":1}`, "POST http://", `/api/rest/v2/keyspaces/ks1/users {"id":1}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withData(t, http.StatusOK, "")
+			env.DryRun = true
+			t.Cleanup(func() {
+				env.DryRun = false
+			})
+			dataBody = c.body
+			out, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !strings.HasPrefix(out, "dry run, request not sent: "+c.prefix) || !strings.HasSuffix(out, c.suffix) {
+				t.Errorf("unexpected output %q", out)
+			}
+			if len(*calls) != 0 {
+				t.Errorf("expected no calls but was %v", *calls)
+			}
+		})
+	}
+}
+
+func TestRowsFails(t *testing.T) {
+	cases := []struct {
+		name     string
+		run      rowsCommand
+		args     []string
+		body     string
+		status   int
+		response string
+		expected string
+	}{
+		{"get", executeRowsGet, []string{"abc", "1"}, "", http.StatusNotFound, `{"description":"table not found","code":404}`, "unable to get rows of ks1.users with error table not found (status 404)"},
+		{"delete", executeRowsDelete, []string{"abc", "1"}, "", http.StatusUnauthorized, `{"description":"invalid token"}`, "unable to delete rows of ks1.users with error invalid token (status 401)"},
+		{"patch", executeRowsPatch, []string{"abc", "1"}, `{"age":1}`, http.StatusBadRequest, `{"description":"unknown column"}`, "unable to patch row in ks1.users with error unknown column (status 400)"},
+		{"put", executeRowsPut, []string{"abc"}, `{"name":"ann"}`, http.StatusBadRequest, `{"description":"missing primary key"}`, "unable to put row in ks1.users with error missing primary key (status 400)"},
+		{"put without data", executeRowsPut, []string{"abc"}, "", http.StatusOK, "", "--data is required"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, mockClient := withData(t, c.status, c.response)
+			dataBody = c.body
+			_, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("expected '%v' but was '%v'", c.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/datastax-labs/astra-cli/pkg/transfer"
+	"github.com/spf13/cobra"
+)
+
+var unloadFile string
+var unloadFormat string
+var unloadBatchSize int
+var unloadMap []string
+
+// stdout is where -f - writes the rows, tests replace it
+var stdout io.Writer = os.Stdout
+
+func init() {
+	addTableFlags(UnloadCmd)
+	UnloadCmd.Flags().StringVarP(&unloadFile, "file", "f", "", "csv or json lines file to write, - writes stdout, required")
+	UnloadCmd.Flags().StringVar(&unloadFormat, "format", "", "csv or jsonl, by default the format of the file extension")
+	UnloadCmd.Flags().IntVar(&unloadBatchSize, "batch-size", 100, "rows read per request, progress is reported after each one")
+	UnloadCmd.Flags().StringVar(&dataFields, "fields", "", "comma separated columns to unload, all by default")
+	UnloadCmd.Flags().StringArrayVar(&unloadMap, "map", []string{}, "field=column, writes the column as the field of the file, only mapped columns are written, can be repeated")
+}
+
+// UnloadCmd writes the rows of a table to a file
+var UnloadCmd = &cobra.Command{
+	Use:   "unload <id|name> -t table -f file",
+	Short: "unloads a table into a csv or json lines file",
+	Long: `writes every row of the table to the csv or json lines file, reading --batch-size rows per request. Csv has a header line
+with the columns, text as is and other values as json. Progress is printed to stderr after each request`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeUnload(args, creds.Login, os.Stderr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if unloadFile == "-" {
+			fmt.Fprintln(os.Stderr, out)
+			return
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeUnload(args []string, makeClient func() (pkg.Client, error), progress io.Writer) (string, error) {
+	if unloadFile == "" {
+		return "", errors.New("--file is required")
+	}
+	format, err := transfer.Format(unloadFormat, unloadFile)
+	if err != nil {
+		return "", err
+	}
+	mapping, err := transfer.ParseMapping(unloadMap)
+	if err != nil {
+		return "", err
+	}
+	client, keyspace, err := openTable(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	out, closeOut, err := createUnloadFile()
+	if err != nil {
+		return "", err
+	}
+	p, err := unloadRows(client, keyspace, format, mapping, out, progress)
+	if closeErr := closeOut(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to unload %v.%v with error %v", keyspace, dataTable, err)
+	}
+	return fmt.Sprintf("unloaded %v rows from %v.%v in %v (%.0f rows/s)", p.Rows, keyspace, dataTable, p.Elapsed.Round(time.Millisecond), p.RowsPerSecond()), nil
+}
+
+// unloadRows writes the rows a page at a time, the csv columns are --fields or the columns of the first page
+func unloadRows(client *stargate.Client, keyspace, format string, mapping transfer.Mapping, out io.Writer, progress io.Writer) (transfer.Progress, error) {
+	start := time.Now()
+	var p transfer.Progress
+	opts := queryOptions()
+	opts.PageSize = unloadBatchSize
+	var writer transfer.Writer
+	for {
+		rows, err := client.ListRows(keyspace, dataTable, opts)
+		if err != nil {
+			return p, err
+		}
+		if writer == nil {
+			columns := opts.Fields
+			if len(columns) == 0 {
+				columns = transfer.Columns(rows.Data)
+			}
+			writer = transfer.NewWriter(out, format, mapping.FieldNames(columns))
+		}
+		for _, row := range rows.Data {
+			if err := writer.Write(mapping.Fields(row)); err != nil {
+				return p, err
+			}
+			p.Rows++
+		}
+		if err := writer.Flush(); err != nil {
+			return p, err
+		}
+		p.Elapsed = time.Since(start)
+		fmt.Fprintf(progress, "unloaded %v rows (%.0f rows/s)\n", p.Rows, p.RowsPerSecond())
+		if rows.PageState == "" {
+			return p, nil
+		}
+		opts.PageState = rows.PageState
+	}
+}
+
+// createUnloadFile creates the file of -f or returns stdout
+func createUnloadFile() (io.Writer, func() error, error) {
+	if unloadFile == "-" {
+		return stdout, func() error { return nil }, nil
+	}
+	f, err := os.OpenFile(unloadFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create '%v' with error %v", unloadFile, err)
+	}
+	return f, f.Close, nil
+}
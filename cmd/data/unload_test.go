@@ -0,0 +1,146 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package data provides the sub-commands for the data command
+package data
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+)
+
+// tablePages answers reads of all rows with two pages
+func tablePages(call restCall) (int, string) {
+	query, err := url.ParseQuery(call.Query)
+	if err != nil {
+		return http.StatusBadRequest, `{"description":"bad query"}`
+	}
+	if query.Get("page-state") == "p2" {
+		return http.StatusOK, `{"count":1,"data":[{"id":3,"name":null,"tags":["a"]}]}`
+	}
+	return http.StatusOK, `{"count":2,"pageState":"p2","data":[{"id":1,"name":"ann","tags":[]},{"id":2,"name":"bob, jr","tags":null}]}`
+}
+
+// withStdout captures what is written to stdout
+func withStdout(t *testing.T) *bytes.Buffer {
+	var out bytes.Buffer
+	original := stdout
+	stdout = &out
+	t.Cleanup(func() {
+		stdout = original
+	})
+	return &out
+}
+
+func TestUnloadCSV(t *testing.T) {
+	calls, mockClient := withDataAnswer(t, tablePages)
+	unloadFile = path.Join(t.TempDir(), "users.csv")
+	unloadBatchSize = 2
+	var progress bytes.Buffer
+	out, err := executeUnload([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &progress)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(out, "unloaded 3 rows from ks1.users in ") {
+		t.Errorf("unexpected summary %q", out)
+	}
+	written, err := os.ReadFile(unloadFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,name,tags\n1,ann,[]\n2,\"bob, jr\",\n3,,\"[\"\"a\"\"]\"\n"
+	if string(written) != expected {
+		t.Errorf("expected %q but was %q", expected, written)
+	}
+	if len(*calls) != 2 || (*calls)[0].Path != "/api/rest/v2/keyspaces/ks1/users/rows" || (*calls)[0].Query != "page-size=2" {
+		t.Errorf("unexpected calls %v", *calls)
+	}
+	if lines := strings.Split(strings.TrimSpace(progress.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "unloaded 3 rows") {
+		t.Errorf("expected progress after each page but was %q", progress.String())
+	}
+}
+
+func TestUnloadJSONLinesToStdout(t *testing.T) {
+	_, mockClient := withDataAnswer(t, tablePages)
+	out := withStdout(t)
+	unloadFile = "-"
+	unloadFormat = pkg.JSONLinesFormat
+	unloadMap = []string{"user_id=id", "user_name=name"}
+	if _, err := executeUnload([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `{"user_id":1,"user_name":"ann"}
+{"user_id":2,"user_name":"bob, jr"}
+{"user_id":3,"user_name":null}
+`
+	if out.String() != expected {
+		t.Errorf("expected %q but was %q", expected, out.String())
+	}
+}
+
+func TestUnloadFields(t *testing.T) {
+	calls, mockClient := withDataAnswer(t, tablePages)
+	out := withStdout(t)
+	unloadFile = "-"
+	unloadFormat = pkg.CSVFormat
+	dataFields = "name,id"
+	if _, err := executeUnload([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "name,id\nann,1\n") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if !strings.Contains((*calls)[0].Query, "fields=name%2Cid") {
+		t.Errorf("unexpected query %v", (*calls)[0].Query)
+	}
+}
+
+func TestUnloadFails(t *testing.T) {
+	_, mockClient := withData(t, http.StatusNotFound, `{"description":"table not found"}`)
+	withStdout(t)
+	unloadFile = "-"
+	unloadFormat = pkg.CSVFormat
+	_, err := executeUnload([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	expected := "unable to unload ks1.users with error table not found (status 404)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestUnloadInvalidFlags(t *testing.T) {
+	_, mockClient := withDataAnswer(t, tablePages)
+	cases := []struct {
+		file     string
+		format   string
+		expected string
+	}{
+		{"", "", "--file is required"},
+		{"-", "", "unable to tell the format of '-', pass --format csv or jsonl"},
+		{"users.csv", "yaml", `--format "yaml" is not valid option, use csv or jsonl`},
+	}
+	for _, c := range cases {
+		unloadFile, unloadFormat = c.file, c.format
+		_, err := executeUnload([]string{"abc"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("expected '%v' but was '%v'", c.expected, err)
+		}
+	}
+}
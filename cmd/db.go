@@ -33,6 +33,15 @@ func init() {
 	dbCmd.AddCommand(db.ListCmd)
 	dbCmd.AddCommand(db.TiersCmd)
 	dbCmd.AddCommand(db.SecBundleCmd)
+	dbCmd.AddCommand(db.RegionCmd)
+	dbCmd.AddCommand(db.ExportCmd)
+	dbCmd.AddCommand(db.GcCmd)
+	dbCmd.AddCommand(db.ConnectConfigCmd)
+	dbCmd.AddCommand(db.CqlshCmd)
+	dbCmd.AddCommand(db.CqlCmd)
+	dbCmd.AddCommand(db.MigrateCmd)
+	dbCmd.AddCommand(db.SchemaCmd)
+	dbCmd.AddCommand(db.PingCmd)
 }
 
 var dbCmd = &cobra.Command{
@@ -0,0 +1,220 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bulk"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+// defaultParallel is how many databases bulk operations work on at the same time
+const defaultParallel = 4
+
+// dryRunResult is the result of the databases an operation was only printed for because of --dry-run
+const dryRunResult = "dry run, not sent"
+
+var (
+	bulkSelector string
+	bulkParallel int
+)
+
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&bulkSelector, "selector", "", "select databases instead of passing ids, for example name=loadtest-*,status=ACTIVE,region=us-east1,tier=serverless,age>24h")
+	cmd.Flags().IntVar(&bulkParallel, "parallel", defaultParallel, "number of databases to work on at the same time")
+}
+
+// bulkArgs accepts the fixed arguments of the command plus at least one id, or no id when --selector is used
+func bulkArgs(fixed int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if bulkSelector != "" {
+			return cobra.MinimumNArgs(fixed)(cmd, args)
+		}
+		return cobra.MinimumNArgs(fixed+1)(cmd, args)
+	}
+}
+
+// isBulk is true when the command has to go through the bulk path instead of the single database one
+func isBulk(ids []string) bool {
+	return bulkSelector != "" || len(ids) > 1
+}
+
+// bulkOperation is one of the commands that can run on many databases
+type bulkOperation struct {
+	name    string // delete, park
+	done    string // deleted, parked
+	confirm bool   // ask before running, only delete does
+	exempt  bool   // not covered by the protection policy, only unpark is
+	run     func(client pkg.Client, db astraops.Database) error
+}
+
+// selectDatabases resolves the ids or the selector to databases. Ids that cannot be found are returned as failed results
+func selectDatabases(client pkg.Client, ids []string) ([]astraops.Database, []bulk.Result, error) {
+	if bulkSelector != "" {
+		if len(ids) > 0 {
+			return nil, nil, fmt.Errorf("pass either database ids or --selector, not both")
+		}
+		sel, err := bulk.ParseSelector(bulkSelector)
+		if err != nil {
+			return nil, nil, err
+		}
+		dbs, err := client.ListDb("", "", "", pkg.MaxListLimit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to list databases with error %v", err)
+		}
+		selected := sel.Select(dbs, time.Now())
+		if len(selected) == 0 {
+			return nil, nil, fmt.Errorf("no databases match selector '%v'", bulkSelector)
+		}
+		return selected, nil, nil
+	}
+	var dbs []astraops.Database
+	var failed []bulk.Result
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		db, err := pkg.ResolveDb(client, id)
+		if err != nil {
+			failed = append(failed, bulk.Failed(id, err))
+			continue
+		}
+		// an id and the name of the same database only select it once
+		if seen[db.Id] && db.Id != id {
+			continue
+		}
+		seen[db.Id] = true
+		dbs = append(dbs, db)
+	}
+	return dbs, failed, nil
+}
+
+// executeBulk runs the operation on every database selected and returns the result table. The error
+// says how many operations failed, the table has the details
+func executeBulk(client pkg.Client, ids []string, op bulkOperation) (string, error) {
+	dbs, results, err := selectDatabases(client, ids)
+	if err != nil {
+		return "", err
+	}
+	results, err = runBulk(client, dbs, results, op)
+	if len(results) == 0 {
+		return "", err
+	}
+	var out bytes.Buffer
+	if writeErr := bulk.WriteResults(&out, results, doneResult(op.done)); writeErr != nil {
+		return "", fmt.Errorf("unable to write results with error %v", writeErr)
+	}
+	return out.String(), err
+}
+
+// runBulk checks the protection policy, confirms if the operation asks for it and runs the operation on the databases.
+// The results include the failed ones passed in, the error says how many failed
+func runBulk(client pkg.Client, dbs []astraops.Database, results []bulk.Result, op bulkOperation) ([]bulk.Result, error) {
+	allowed, results, err := allowedDatabases(dbs, results, op)
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) > 0 && op.confirm {
+		if err := confirmBulk(allowed, op); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case len(allowed) > 0 && env.DryRun:
+		fmt.Printf("dry run, would %v %v database(s)\n", op.name, len(allowed))
+	case len(allowed) > 0:
+		fmt.Printf("starting to %v %v database(s)\n", op.name, len(allowed))
+	}
+	results = append(bulk.Run(allowed, bulkParallel, func(db astraops.Database) error {
+		return op.run(client, db)
+	}), results...)
+	if failed := bulk.CountFailed(results); failed > 0 {
+		return results, fmt.Errorf("%v of %v database(s) failed to %v", failed, len(results), op.name)
+	}
+	return results, nil
+}
+
+// doneResult is the result shown for the databases the operation succeeded on
+func doneResult(done string) string {
+	if env.DryRun {
+		return dryRunResult
+	}
+	return done
+}
+
+// allowedDatabases drops the databases the protection policy refuses the operation on and adds them to the results
+func allowedDatabases(dbs []astraops.Database, results []bulk.Result, op bulkOperation) ([]astraops.Database, []bulk.Result, error) {
+	if op.exempt {
+		return dbs, results, nil
+	}
+	policy, logFile, err := loadPolicy()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load protection policy with error %v", err)
+	}
+	var allowed []astraops.Database
+	for _, db := range dbs {
+		if err := checkPolicy(policy, logFile, db, op.name); err != nil {
+			results = append(results, bulk.Result{ID: db.Id, Name: bulk.Name(db), Err: err})
+			continue
+		}
+		allowed = append(allowed, db)
+	}
+	return allowed, results, nil
+}
+
+// confirmBulk lists the databases and asks for their number to be typed, --yes skips it
+func confirmBulk(dbs []astraops.Database, op bulkOperation) error {
+	rows := [][]string{{"id", "name", "status", "region"}}
+	for _, db := range dbs {
+		var region string
+		if db.Info.Region != nil {
+			region = *db.Info.Region
+		}
+		rows = append(rows, []string{db.Id, bulk.Name(db), string(db.Status), region})
+	}
+	fmt.Printf("the following %v database(s) will be %v:\n", len(dbs), op.done)
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, rows); err != nil {
+		return fmt.Errorf("unable to write databases with error %v", err)
+	}
+	fmt.Println(out.String())
+	if deleteYes || env.DryRun {
+		return nil
+	}
+	if !isTerminal() {
+		return fmt.Errorf("refusing to %v %v database(s) without confirmation when not running in a terminal, pass --yes to %v anyway", op.name, len(dbs), op.name)
+	}
+	fmt.Printf("type the number of databases (%v) to confirm: ", len(dbs))
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && strings.TrimSpace(answer) == "" {
+		return fmt.Errorf("unable to read confirmation with error %v", err)
+	}
+	if strings.TrimSpace(answer) != strconv.Itoa(len(dbs)) {
+		return fmt.Errorf("'%v' does not match the number of databases %v, nothing was %v", strings.TrimSpace(answer), len(dbs), op.done)
+	}
+	return nil
+}
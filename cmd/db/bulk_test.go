@@ -0,0 +1,248 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func namedDb(id, name string) astraops.Database {
+	return astraops.Database{
+		Id:           id,
+		Status:       astraops.StatusEnumACTIVE,
+		CreationTime: astraops.StringPtr(time.Now().Add(-48 * time.Hour).Format(time.RFC3339)),
+		Info:         astraops.DatabaseInfo{Name: astraops.StringPtr(name)},
+	}
+}
+
+func withSelector(t *testing.T, selector string) {
+	bulkSelector = selector
+	bulkParallel = 1
+	t.Cleanup(func() {
+		bulkSelector = ""
+		bulkParallel = defaultParallel
+	})
+}
+
+func TestParkMany(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "")
+	mockClient := &tests.MockClient{
+		Databases:  []astraops.Database{namedDb("a", "one"), namedDb("b", "two")},
+		ErrorQueue: []error{nil, nil, nil, errors.New("not parkable")},
+	}
+	msg, err := executePark([]string{"a", "b"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || err.Error() != "1 of 2 database(s) failed to park" {
+		t.Errorf("unexpected error '%v'", err)
+	}
+	if !strings.Contains(msg, "parked") || !strings.Contains(msg, "failed: not parkable") {
+		t.Errorf("unexpected result table '%v'", msg)
+	}
+	if len(mockClient.Calls()) != 4 {
+		t.Errorf("expected 2 lookups and 2 parks but was %v", mockClient.Calls())
+	}
+}
+
+func TestUnparkSelector(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "name=loadtest-*,age>1d")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "loadtest-1"), namedDb("b", "prod"), namedDb("c", "loadtest-2")},
+	}
+	err := executeUnpark([]string{}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	calls := mockClient.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected a list and 2 unparks but was %v", calls)
+	}
+	if calls[1] != "a" || calls[2] != "c" {
+		t.Errorf("expected a and c to be unparked but was %v", calls[1:])
+	}
+}
+
+func TestSelectorNoMatch(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "name=nothing-*")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "one")},
+	}
+	err := executeResize([]string{"2"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "no databases match selector") {
+		t.Errorf("unexpected error '%v'", err)
+	}
+}
+
+func TestSelectorAndIds(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "name=one")
+	_, err := executePark([]string{"a"}, func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err == nil {
+		t.Error("expected an error passing both ids and a selector")
+	}
+}
+
+func TestResizeMany(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "one"), namedDb("b", "two")},
+	}
+	err := executeResize([]string{"a", "b", "3"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	resize := mockClient.Call(3).([]interface{})
+	if resize[0] != "b" || resize[1] != 3 {
+		t.Errorf("expected b to be resized to 3 but was %v", resize)
+	}
+}
+
+func TestDeleteManyConfirmsCount(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "")
+	withTerminal(t, true, "2\n")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "one"), namedDb("b", "two")},
+	}
+	_, err := executeDelete([]string{"a", "b"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 4 {
+		t.Errorf("expected 2 lookups and 2 deletes but was %v", mockClient.Calls())
+	}
+}
+
+func TestDeleteManyWrongCount(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "")
+	withTerminal(t, true, "1\n")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "one"), namedDb("b", "two")},
+	}
+	_, err := executeDelete([]string{"a", "b"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(mockClient.Calls()) != 2 {
+		t.Errorf("expected only the lookups but was %v", mockClient.Calls())
+	}
+}
+
+func TestDeleteManySkipsProtected(t *testing.T) {
+	withPolicy(t, prodPolicy)
+	withSelector(t, "")
+	deleteYes = true
+	defer func() {
+		deleteYes = false
+	}()
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "prod-users"), namedDb("b", "two")},
+	}
+	msg, err := executeDelete([]string{"a", "b"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || err.Error() != "1 of 2 database(s) failed to delete" {
+		t.Errorf("unexpected error '%v'", err)
+	}
+	if !strings.Contains(msg, "is protected") {
+		t.Errorf("expected the protected database in the results but was '%v'", msg)
+	}
+	if len(mockClient.Calls()) != 3 || mockClient.Call(2) != "b" {
+		t.Errorf("expected only b to be deleted but was %v", mockClient.Calls())
+	}
+}
+
+func TestUnparkManyIgnoresProtection(t *testing.T) {
+	withPolicy(t, prodPolicy)
+	withSelector(t, "")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "prod-users"), namedDb("b", "two")},
+	}
+	err := executeUnpark([]string{"a", "b"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 4 {
+		t.Errorf("expected 2 lookups and 2 unparks but was %v", mockClient.Calls())
+	}
+}
+
+func TestParkManyDryRun(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "")
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{namedDb("a", "one"), namedDb("b", "two")},
+	}
+	msg, err := executePark([]string{"a", "b"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if strings.Count(msg, "dry run, not sent") != 2 || strings.Contains(msg, "parked") {
+		t.Errorf("expected every database to be reported as not sent but was '%v'", msg)
+	}
+}
+
+func TestDeleteManyByNameDryRun(t *testing.T) {
+	withPolicy(t, "")
+	withSelector(t, "")
+	env.DryRun = true
+	t.Cleanup(func() { env.DryRun = false })
+	mockClient := &tests.MockClient{
+		// the name is looked up as an id first, that lookup consumes the first database
+		Databases:  []astraops.Database{namedDb("a", "one"), {}, namedDb("b", "two")},
+		ErrorQueue: []error{nil, errors.New("not found")},
+	}
+	msg, err := executeDelete([]string{"a", "two"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if !strings.Contains(msg, "b ") || strings.Contains(msg, "failed") {
+		t.Errorf("expected b to be selected by its name but was '%v'", msg)
+	}
+}
@@ -0,0 +1,266 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// tokenEnv is where the generated configs read an application token from
+	tokenEnv = "ASTRA_DB_APPLICATION_TOKEN"
+	// clientIDEnv is where the generated configs read the client id of a service account from
+	clientIDEnv = "ASTRA_DB_CLIENT_ID"
+	// clientSecretEnv is where the generated configs read the client secret of a service account from
+	clientSecretEnv = "ASTRA_DB_CLIENT_SECRET"
+)
+
+var connectLang string
+var connectBundle string
+
+func init() {
+	ConnectConfigCmd.Flags().StringVar(&connectLang, "lang", "", "driver to generate the configuration for: "+strings.Join(connectLangs(), ", "))
+	ConnectConfigCmd.Flags().StringVarP(&connectBundle, "bundle", "b", "secureBundle.zip", "path of the secure bundle downloaded with db secBundle")
+}
+
+// ConnectConfigCmd prints the driver configuration to connect to a database
+var ConnectConfigCmd = &cobra.Command{
+	Use:   "connect-config <id> --lang java|python|go|node|cqlshrc",
+	Short: "prints the driver configuration to connect to the database",
+	Long: `prints a configuration file or code snippet for the driver with the keyspace of the database, the secure bundle path and
+the credentials read from environment variables, ASTRA_DB_APPLICATION_TOKEN when logged in with a token or ASTRA_DB_CLIENT_ID and
+ASTRA_DB_CLIENT_SECRET when logged in with a service account. Secrets are never written in the output`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeConnectConfig(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+// connectSettings fill the driver templates
+type connectSettings struct {
+	ID          string
+	Name        string
+	Region      string
+	Keyspace    string
+	BundlePath  string
+	Username    string // literal username, token for application tokens
+	UsernameEnv string // environment variable of the username when it is not literal
+	PasswordEnv string
+}
+
+var connectTemplates = map[string]string{
+	"java": `# application.conf for the DataStax Java driver 4.x
+# database {{.Name}} ({{.ID}}) in region {{.Region}}
+datastax-java-driver {
+  basic {
+    session-keyspace = {{.Keyspace}}
+    cloud {
+      secure-connect-bundle = {{jsonString .BundlePath}}
+    }
+  }
+  advanced {
+    auth-provider {
+      class = PlainTextAuthProvider
+{{- if .Username}}
+      username = {{jsonString .Username}}
+{{- else}}
+      username = ${ {{- .UsernameEnv -}} }
+{{- end}}
+      password = ${ {{- .PasswordEnv -}} }
+    }
+  }
+}`,
+	"python": `# database {{.Name}} ({{.ID}}) in region {{.Region}}
+import os
+
+from cassandra.auth import PlainTextAuthProvider
+from cassandra.cluster import Cluster
+
+cloud_config = {"secure_connect_bundle": {{jsonString .BundlePath}}}
+auth_provider = PlainTextAuthProvider(
+{{- if .Username}}{{jsonString .Username}}{{else}}os.environ["{{.UsernameEnv}}"]{{end}}, os.environ["{{.PasswordEnv}}"])
+cluster = Cluster(cloud=cloud_config, auth_provider=auth_provider)
+session = cluster.connect({{jsonString .Keyspace}})`,
+	"go": `// database {{.Name}} ({{.ID}}) in region {{.Region}}
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	gocqlastra "github.com/datastax/gocql-astra"
+	"github.com/gocql/gocql"
+)
+
+func main() {
+	cluster, err := gocqlastra.NewClusterFromBundle({{goString .BundlePath}},
+		{{if .Username}}{{goString .Username}}{{else}}os.Getenv("{{.UsernameEnv}}"){{end}}, os.Getenv("{{.PasswordEnv}}"), 10*time.Second)
+	if err != nil {
+		log.Fatalf("unable to load bundle with error %v", err)
+	}
+	cluster.Keyspace = {{goString .Keyspace}}
+	session, err := gocql.NewSession(*cluster)
+	if err != nil {
+		log.Fatalf("unable to connect with error %v", err)
+	}
+	defer session.Close()
+}`,
+	"node": `// database {{.Name}} ({{.ID}}) in region {{.Region}}
+const cassandra = require('cassandra-driver');
+
+const client = new cassandra.Client({
+  cloud: { secureConnectBundle: {{jsonString .BundlePath}} },
+  credentials: {
+    username: {{if .Username}}{{jsonString .Username}}{{else}}process.env.{{.UsernameEnv}}{{end}},
+    password: process.env.{{.PasswordEnv}},
+  },
+  keyspace: {{jsonString .Keyspace}},
+});`,
+	"cqlshrc": `; database {{.Name}} ({{.ID}}) in region {{.Region}}
+{{- if .UsernameEnv}}
+; cqlsh asks for the username, the value of {{.UsernameEnv}}
+{{- end}}
+; cqlsh asks for the password, the value of {{.PasswordEnv}}
+[authentication]
+{{- if .Username}}
+username = {{iniValue .Username}}
+{{- end}}
+keyspace = {{iniValue .Keyspace}}
+
+[connection]
+secure_connect_bundle = {{iniValue .BundlePath}}`,
+}
+
+// connectFuncs quote the values for the language of the template, a Windows path or a quote in the bundle path
+// must not end the string early
+var connectFuncs = template.FuncMap{
+	// jsonString is a double quoted string literal that HOCON, Python and JavaScript all read the same way
+	"jsonString": func(s string) (string, error) {
+		var out bytes.Buffer
+		enc := json.NewEncoder(&out)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(s); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(out.String(), "\n"), nil
+	},
+	"goString": strconv.Quote,
+	"iniValue": iniValue,
+}
+
+// iniValue escapes % which cqlsh reads as interpolation, the rest of the line is taken as is
+func iniValue(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// connectLangs are the --lang values sorted
+func connectLangs() []string {
+	var langs []string
+	for lang := range connectTemplates {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func executeConnectConfig(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	text, ok := connectTemplates[connectLang]
+	if !ok {
+		return "", fmt.Errorf("--lang %q is not valid, valid options are %v", connectLang, strings.Join(connectLangs(), ", "))
+	}
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	id := args[0]
+	db, err := client.FindDb(id)
+	if err != nil {
+		return "", fmt.Errorf("unable to get '%s' with error %v", id, err)
+	}
+	bundlePath, err := filepath.Abs(connectBundle)
+	if err != nil {
+		return "", fmt.Errorf("unable to find path of bundle '%v' with error %v", connectBundle, err)
+	}
+	if _, err := os.Stat(bundlePath); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: bundle %v not found, download it with astra-cli db secBundle %v -l %v\n", bundlePath, id, connectBundle)
+	}
+	settings, err := newConnectSettings(db, bundlePath)
+	if err != nil {
+		return "", err
+	}
+	return renderConnectConfig(text, settings)
+}
+
+// newConnectSettings reads which credentials were used to login so the config reads the matching environment variables
+func newConnectSettings(db astraops.Database, bundlePath string) (connectSettings, error) {
+	token, _, err := storedCreds()
+	if err != nil {
+		return connectSettings{}, fmt.Errorf("unable to read credentials with error %v", err)
+	}
+	settings := connectSettings{
+		ID:         db.Id,
+		Name:       deref(db.Info.Name),
+		Region:     deref(db.Info.Region),
+		Keyspace:   deref(db.Info.Keyspace),
+		BundlePath: bundlePath,
+	}
+	if token != "" {
+		settings.Username = "token"
+		settings.PasswordEnv = tokenEnv
+	} else {
+		settings.UsernameEnv = clientIDEnv
+		settings.PasswordEnv = clientSecretEnv
+	}
+	return settings, nil
+}
+
+func renderConnectConfig(text string, settings connectSettings) (string, error) {
+	t, err := template.New("config").Funcs(connectFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse template with error %v", err)
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, settings); err != nil {
+		return "", fmt.Errorf("unable to render config with error %v", err)
+	}
+	return out.String(), nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
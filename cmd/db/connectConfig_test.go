@@ -0,0 +1,143 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+func connectDb() astraops.Database {
+	return astraops.Database{
+		Id: "2c3bc0d6-5e3e-4d77-81c8-d95a35bdc58b",
+		Info: astraops.DatabaseInfo{
+			Name:     astraops.StringPtr("app"),
+			Region:   astraops.StringPtr("us-east1"),
+			Keyspace: astraops.StringPtr("ks1"),
+		},
+	}
+}
+
+func checkGolden(t *testing.T, name, actual string) {
+	golden := filepath.Join("testdata", "connect-config", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("unable to read golden file, run go test with -update to create it, error %v", err)
+	}
+	if string(expected) != actual {
+		t.Errorf("output does not match %v, expected\n%v\nbut was\n%v", golden, string(expected), actual)
+	}
+}
+
+func withConnectConfig(t *testing.T, lang, token string) {
+	// setting package variables by hand, there be dragons
+	connectLang = lang
+	connectBundle = "/opt/app/secureBundle.zip"
+	original := storedCreds
+	storedCreds = func() (string, pkg.ClientInfo, error) {
+		if token == "" {
+			return "", pkg.ClientInfo{ClientID: "id", ClientSecret: "secret"}, nil
+		}
+		return token, pkg.ClientInfo{}, nil
+	}
+	t.Cleanup(func() {
+		connectLang = ""
+		connectBundle = "secureBundle.zip"
+		storedCreds = original
+	})
+}
+
+func TestConnectConfigGolden(t *testing.T) {
+	for _, lang := range connectLangs() {
+		for _, login := range []struct {
+			name  string
+			token string
+		}{{"token", "AstraCS:abc"}, {"sa", ""}} {
+			t.Run(lang+"-"+login.name, func(t *testing.T) {
+				withConnectConfig(t, lang, login.token)
+				out, err := executeConnectConfig([]string{"2c3bc0d6-5e3e-4d77-81c8-d95a35bdc58b"}, func() (pkg.Client, error) {
+					return &tests.MockClient{Databases: []astraops.Database{connectDb()}}, nil
+				})
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				checkGolden(t, lang+"-"+login.name, out)
+			})
+		}
+	}
+}
+
+func TestConnectConfigQuotesBundlePath(t *testing.T) {
+	for _, lang := range connectLangs() {
+		t.Run(lang, func(t *testing.T) {
+			settings := connectSettings{
+				ID:          "2c3bc0d6-5e3e-4d77-81c8-d95a35bdc58b",
+				Name:        "app",
+				Region:      "us-east1",
+				Keyspace:    "ks1",
+				BundlePath:  `C:\Users\o'brien\100% "astra"\secureBundle.zip`,
+				UsernameEnv: clientIDEnv,
+				PasswordEnv: clientSecretEnv,
+			}
+			out, err := renderConnectConfig(connectTemplates[lang], settings)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			checkGolden(t, lang+"-windows", out)
+		})
+	}
+}
+
+func TestConnectConfigInvalidLang(t *testing.T) {
+	withConnectConfig(t, "rust", "AstraCS:abc")
+	_, err := executeConnectConfig([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	expected := `--lang "rust" is not valid, valid options are cqlshrc, go, java, node, python`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestConnectConfigNeverContainsSecrets(t *testing.T) {
+	withConnectConfig(t, "java", "AstraCS:verysecret")
+	out, err := executeConnectConfig([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{Databases: []astraops.Database{connectDb()}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if strings.Contains(out, "verysecret") {
+		t.Error("expected the token not to be in the config")
+	}
+}
@@ -0,0 +1,46 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CqlCmd.AddCommand(CqlExecCmd)
+}
+
+// CqlCmd is the parent command for running CQL without cqlsh
+var CqlCmd = &cobra.Command{
+	Use:   "cql",
+	Short: "Shows all the cql commands",
+	Long:  `Shows all the cql commands. Run CQL statements against a database over a native connection, no cqlsh needed`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeCql(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeCql(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
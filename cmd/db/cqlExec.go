@@ -0,0 +1,284 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/spf13/cobra"
+)
+
+// statementWidth is how much of a statement the text output shows
+const statementWidth = 60
+
+var cqlExecFile string
+var cqlExecStatement string
+var cqlExecKeyspace string
+var cqlExecStopOnError bool
+var cqlExecFmt string
+var cqlExecTimeout time.Duration
+
+func init() {
+	CqlExecCmd.Flags().StringVarP(&cqlExecFile, "file", "f", "", "file of statements separated by semicolons, - reads stdin")
+	CqlExecCmd.Flags().StringVarP(&cqlExecStatement, "execute", "e", "", "statements to run separated by semicolons")
+	CqlExecCmd.Flags().StringVarP(&cqlExecKeyspace, "keyspace", "k", "", "keyspace to use, the keyspace of the database by default")
+	CqlExecCmd.Flags().BoolVar(&cqlExecStopOnError, "stop-on-error", false, "skip the remaining statements after one fails")
+	CqlExecCmd.Flags().StringVarP(&cqlExecFmt, "output", "o", "text", "Output format for report default is text, can also be json")
+	CqlExecCmd.Flags().DurationVar(&cqlExecTimeout, "timeout", cql.DefaultTimeout, "timeout of connecting and of each statement")
+}
+
+// CqlExecCmd runs a CQL script against a database
+var CqlExecCmd = &cobra.Command{
+	Use:   "exec <id|name> -f schema.cql | -e statement",
+	Short: "runs CQL statements against the database",
+	Long: `runs the statements of a file or of -e one after the other over a native connection made with the secure bundle and the
+credentials of astra-cli login, so cqlsh does not need to be installed. Every statement is reported with its result and how long it
+took. With --dry-run the statements are only listed`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeCqlExec(args, creds.Login)
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// dialCQL connects with the bundle
+var dialCQL = func(b bundle.Bundle, username, password string, timeout time.Duration) (*cql.Session, error) {
+	return cql.DialBundle(b, username, password, timeout)
+}
+
+// statementResult is the report of one statement
+type statementResult struct {
+	Line       int         `json:"line"`
+	Statement  string      `json:"statement"`
+	Outcome    string      `json:"outcome"`
+	Result     *cql.Result `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	DurationMs int64       `json:"durationMs"`
+}
+
+// statement outcomes
+const (
+	outcomeOK      = "ok"
+	outcomeFailed  = "failed"
+	outcomeSkipped = "skipped"
+)
+
+func executeCqlExec(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	if cqlExecFmt != pkg.TextFormat && cqlExecFmt != pkg.JSONFormat {
+		return "", fmt.Errorf("-o %q is not valid option", cqlExecFmt)
+	}
+	script, source, err := readScript()
+	if err != nil {
+		return "", err
+	}
+	statements := cql.Split(script)
+	if len(statements) == 0 {
+		return "", fmt.Errorf("no statements found in %v", source)
+	}
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, args[0])
+	if err != nil {
+		return "", err
+	}
+	keyspace := cqlExecKeyspace
+	if keyspace == "" {
+		keyspace = deref(db.Info.Keyspace)
+	}
+	var results []statementResult
+	if env.DryRun {
+		results = skipStatements(statements, "dry run")
+	} else {
+		session, err := connectCQL(client, db.Id, keyspace, cqlExecTimeout)
+		if err != nil {
+			return "", err
+		}
+		defer session.Close()
+		results = runStatements(session, statements)
+	}
+	out, err := writeStatementResults(results)
+	if err != nil {
+		return "", err
+	}
+	var failed int
+	for _, r := range results {
+		if r.Outcome == outcomeFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return out, fmt.Errorf("%v of %v statement(s) failed", failed, len(results))
+	}
+	return out, nil
+}
+
+// readScript returns the statements of -e or -f and where they came from
+func readScript() (string, string, error) {
+	switch {
+	case cqlExecFile != "" && cqlExecStatement != "":
+		return "", "", fmt.Errorf("pass either -e or -f, not both")
+	case cqlExecStatement != "":
+		return cqlExecStatement, "-e", nil
+	case cqlExecFile == "-":
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to read stdin with error %v", err)
+		}
+		return string(b), "stdin", nil
+	case cqlExecFile != "":
+		b, err := os.ReadFile(cqlExecFile)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to read '%v' with error %v", cqlExecFile, err)
+		}
+		return string(b), cqlExecFile, nil
+	default:
+		return "", "", fmt.Errorf("pass the statements to run with -f or -e")
+	}
+}
+
+// connectCQL opens a session with the cached bundle of the database and switches to the keyspace
+func connectCQL(client pkg.Client, id, keyspace string, timeout time.Duration) (*cql.Session, error) {
+	bundlePath, err := cachedBundle(client, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	b, err := bundle.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	username, password, err := loginCredentials()
+	if err != nil {
+		return nil, err
+	}
+	session, err := dialCQL(b, username, password, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to '%s' with error %v", id, err)
+	}
+	if keyspace != "" {
+		if _, err := session.Query("USE " + cql.QuoteIdentifier(keyspace)); err != nil {
+			session.Close()
+			return nil, fmt.Errorf("unable to use keyspace '%v' with error %v", keyspace, err)
+		}
+	}
+	return session, nil
+}
+
+// runStatements runs the statements in order, after a failure the rest are skipped with --stop-on-error
+func runStatements(session *cql.Session, statements []cql.Statement) []statementResult {
+	var results []statementResult
+	for i, s := range statements {
+		start := time.Now()
+		result, err := session.Query(s.Text)
+		r := statementResult{
+			Line:       s.Line,
+			Statement:  s.Text,
+			Outcome:    outcomeOK,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			r.Outcome = outcomeFailed
+			r.Error = err.Error()
+		} else {
+			r.Result = &result
+		}
+		results = append(results, r)
+		if err != nil && cqlExecStopOnError {
+			return append(results, skipStatements(statements[i+1:], "stop on error")...)
+		}
+	}
+	return results
+}
+
+func skipStatements(statements []cql.Statement, reason string) []statementResult {
+	var results []statementResult
+	for _, s := range statements {
+		results = append(results, statementResult{Line: s.Line, Statement: s.Text, Outcome: outcomeSkipped, Error: reason})
+	}
+	return results
+}
+
+// writeStatementResults prints one row per statement, followed by the rows returned by the queries in text output
+func writeStatementResults(results []statementResult) (string, error) {
+	if cqlExecFmt == pkg.JSONFormat {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		return string(b), nil
+	}
+	rows := [][]string{{"line", "statement", "result", "duration"}}
+	for _, r := range results {
+		rows = append(rows, []string{fmt.Sprint(r.Line), shorten(r.Statement), describeStatement(r), fmt.Sprintf("%vms", r.DurationMs)})
+	}
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, rows); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	for _, r := range results {
+		if r.Result == nil || r.Result.Kind != "rows" {
+			continue
+		}
+		fmt.Fprintf(&out, "\n\nline %v\n", r.Line)
+		if err := pkg.WriteRows(&out, append([][]string{r.Result.Columns}, r.Result.Rows...)); err != nil {
+			return "", fmt.Errorf("unexpected error writing text output %v", err)
+		}
+	}
+	return out.String(), nil
+}
+
+// describeStatement is the result column of the text output
+func describeStatement(r statementResult) string {
+	switch {
+	case r.Outcome == outcomeFailed:
+		return "failed: " + r.Error
+	case r.Outcome == outcomeSkipped:
+		return "skipped: " + r.Error
+	case r.Result.Kind == "rows" && r.Result.MorePages:
+		return fmt.Sprintf("%v rows, more not shown", len(r.Result.Rows))
+	case r.Result.Kind == "rows":
+		return fmt.Sprintf("%v rows", len(r.Result.Rows))
+	default:
+		return outcomeOK
+	}
+}
+
+// shorten puts the statement on one line and cuts it at statementWidth characters
+func shorten(statement string) string {
+	s := []rune(strings.Join(strings.Fields(statement), " "))
+	if len(s) > statementWidth {
+		return string(s[:statementWidth-3]) + "..."
+	}
+	return string(s)
+}
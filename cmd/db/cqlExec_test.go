@@ -0,0 +1,223 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// cqlStandIn answers users queries with rows, missing tables with an error and everything else with a schema change
+func cqlStandIn(query string) tests.CQLResult {
+	switch {
+	case strings.HasPrefix(query, "USE"):
+		return tests.CQLResult{}
+	case strings.Contains(query, "missing"):
+		return tests.CQLResult{ErrorCode: 0x2200, ErrorMessage: "table missing does not exist"}
+	case strings.HasPrefix(query, "SELECT"):
+		return tests.CQLResult{Columns: []string{"id", "name"}, Rows: [][]string{{"1", "ann"}}}
+	default:
+		return tests.CQLResult{Change: "CREATED", Keyspace: "ks1", Table: "users"}
+	}
+}
+
+// withCqlExec points the connection at a stand-in server and returns it with a client serving the bundle
+func withCqlExec(t *testing.T, script string) (*tests.CQLServer, *tests.MockClient) {
+	// setting package variables by hand, there be dragons
+	server, err := tests.NewCQLServer("token", "AstraCS:secret", nil, cqlStandIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, mockClient := serveBundle(t)
+	mockClient.Databases = []astraops.Database{cqlshDb()}
+	dir := t.TempDir()
+	cqlExecFile = path.Join(dir, "schema.cql")
+	if err := os.WriteFile(cqlExecFile, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	originalCache := bundleCacheDir
+	bundleCacheDir = func() (string, error) {
+		return path.Join(dir, "bundles"), nil
+	}
+	originalCreds := storedCreds
+	storedCreds = func() (string, pkg.ClientInfo, error) {
+		return "AstraCS:secret", pkg.ClientInfo{}, nil
+	}
+	originalDial := dialCQL
+	dialCQL = func(b bundle.Bundle, username, password string, timeout time.Duration) (*cql.Session, error) {
+		return cql.Dial(server.Addr(), nil, username, password, timeout)
+	}
+	t.Cleanup(func() {
+		if err := server.Close(); err != nil {
+			t.Logf("unable to close server %v", err)
+		}
+		cqlExecFile = ""
+		cqlExecStatement = ""
+		cqlExecKeyspace = ""
+		cqlExecStopOnError = false
+		cqlExecFmt = pkg.TextFormat
+		bundleCacheDir = originalCache
+		storedCreds = originalCreds
+		dialCQL = originalDial
+	})
+	return server, mockClient
+}
+
+const migration = `-- users
+CREATE TABLE users (id int PRIMARY KEY, name text);
+SELECT * FROM missing;
+SELECT id, name FROM users;
+`
+
+func TestCqlExec(t *testing.T) {
+	server, mockClient := withCqlExec(t, migration)
+	out, err := executeCqlExec([]string{"mydb"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || err.Error() != "1 of 3 statement(s) failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	expectedQueries := []string{`USE "ks1"`, "CREATE TABLE users (id int PRIMARY KEY, name text)", "SELECT * FROM missing", "SELECT id, name FROM users"}
+	if !reflect.DeepEqual(server.Queries(), expectedQueries) {
+		t.Errorf("expected %v but was %v", expectedQueries, server.Queries())
+	}
+	lines := strings.Split(out, "\n")
+	expected := []string{
+		"line statement                                          result",
+		"2    CREATE TABLE users (id int PRIMARY KEY, name text) ok",
+		"3    SELECT * FROM missing                              failed: table missing does not exist (error code 0x2200)",
+		"4    SELECT id, name FROM users                         1 rows",
+		"",
+		"line 4",
+		"id name",
+		"1  ann",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %v lines but was %v\n%v", len(expected), len(lines), out)
+	}
+	for i := range expected {
+		if !strings.HasPrefix(lines[i], expected[i]) {
+			t.Errorf("line %v expected to start with %q but was %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestCqlExecStopOnErrorJSON(t *testing.T) {
+	server, mockClient := withCqlExec(t, migration)
+	cqlExecStopOnError = true
+	cqlExecFmt = pkg.JSONFormat
+	cqlExecKeyspace = "other"
+	out, err := executeCqlExec([]string{"mydb"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Error("expected error")
+	}
+	var results []statementResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("unable to parse output %v", err)
+	}
+	var outcomes []string
+	for _, r := range results {
+		outcomes = append(outcomes, r.Outcome)
+	}
+	if !reflect.DeepEqual(outcomes, []string{outcomeOK, outcomeFailed, outcomeSkipped}) {
+		t.Errorf("unexpected outcomes %v", outcomes)
+	}
+	if results[0].Result == nil || results[0].Result.Kind != "void" {
+		t.Errorf("unexpected result %v", results[0].Result)
+	}
+	if len(server.Queries()) != 3 || server.Queries()[0] != `USE "other"` {
+		t.Errorf("expected the third statement to be skipped but was %v", server.Queries())
+	}
+}
+
+func TestCqlExecDryRun(t *testing.T) {
+	server, mockClient := withCqlExec(t, migration)
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	out, err := executeCqlExec([]string{"mydb"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(server.Queries()) != 0 {
+		t.Errorf("expected no queries in dry run but was %v", server.Queries())
+	}
+	if strings.Count(out, "skipped: dry run") != 3 {
+		t.Errorf("expected every statement to be skipped\n%v", out)
+	}
+}
+
+func TestCqlExecStatement(t *testing.T) {
+	server, mockClient := withCqlExec(t, "")
+	cqlExecFile = ""
+	cqlExecStatement = "SELECT id, name FROM users"
+	if _, err := executeCqlExec([]string{cqlshDbID}, func() (pkg.Client, error) {
+		return mockClient, nil
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(server.Queries()) != 2 {
+		t.Errorf("unexpected queries %v", server.Queries())
+	}
+}
+
+func TestCqlExecNoStatements(t *testing.T) {
+	_, mockClient := withCqlExec(t, "-- nothing to do\n")
+	_, err := executeCqlExec([]string{"mydb"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "no statements found in") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCqlExecBothFileAndStatement(t *testing.T) {
+	withCqlExec(t, migration)
+	cqlExecStatement = "SELECT 1"
+	if _, err := executeCqlExec([]string{"mydb"}, nil); err == nil || err.Error() != "pass either -e or -f, not both" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestShortenMultiByte(t *testing.T) {
+	statement := "INSERT INTO users (id, name) VALUES (1, '" + strings.Repeat("é", 40) + "')"
+	s := shorten(statement)
+	if !utf8.ValidString(s) {
+		t.Errorf("expected valid UTF-8 but was %q", s)
+	}
+	if n := utf8.RuneCountInString(s); n != statementWidth {
+		t.Errorf("expected %v characters but was %v", statementWidth, n)
+	}
+	if short := shorten("SELECT  *\n FROM users"); short != "SELECT * FROM users" {
+		t.Errorf("unexpected statement %q", short)
+	}
+}
@@ -0,0 +1,222 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	"github.com/datastax-labs/astra-cli/pkg/httputils"
+	"github.com/spf13/cobra"
+)
+
+// cqlshPathEnv sets the cqlsh binary when --cqlsh-path is not passed
+const cqlshPathEnv = "ASTRA_CQLSH_PATH"
+
+var cqlshKeyspace string
+var cqlshExecute string
+var cqlshFile string
+var cqlshPath string
+
+func init() {
+	CqlshCmd.Flags().StringVarP(&cqlshKeyspace, "keyspace", "k", "", "keyspace to use, the keyspace of the database by default")
+	CqlshCmd.Flags().StringVarP(&cqlshExecute, "execute", "e", "", "execute the statement and quit")
+	CqlshCmd.Flags().StringVarP(&cqlshFile, "file", "f", "", "execute the statements of the file and quit")
+	CqlshCmd.Flags().StringVar(&cqlshPath, "cqlsh-path", "", "cqlsh binary to run, $"+cqlshPathEnv+" or cqlsh on the PATH by default")
+}
+
+// CqlshCmd runs cqlsh connected to the database
+var CqlshCmd = &cobra.Command{
+	Use:   "cqlsh <id|name> [-k keyspace] [-e statement | -f file] [-- cqlsh args]",
+	Short: "runs cqlsh connected to the database",
+	Long: `runs cqlsh connected to the database with the secure bundle and the credentials of astra-cli login. The bundle is
+downloaded once and kept in ~/.config/astra/bundles until its certificates expire. The credentials are passed in a temporary
+cqlshrc readable only by the user so they never show up in the process list. The exit code is the one of cqlsh`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		code, err := executeCqlsh(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(code)
+	},
+}
+
+// bundleCacheDir is where the bundles used by cqlsh are kept
+var bundleCacheDir = func() (string, error) {
+	confDir, _, err := pkg.GetHome(os.UserHomeDir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(confDir, "bundles"), nil
+}
+
+// runCqlsh runs the binary attached to the terminal and returns its exit code. Interrupts are left to cqlsh
+// so the temporary cqlshrc is still removed when the user presses ctrl-c
+var runCqlsh = func(binary string, args []string) (int, error) {
+	cmd := exec.Command(binary, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, fmt.Errorf("unable to run %v with error %v", binary, err)
+	}
+	return 0, nil
+}
+
+func executeCqlsh(args []string, makeClient func() (pkg.Client, error)) (int, error) {
+	if cqlshExecute != "" && cqlshFile != "" {
+		return 1, fmt.Errorf("pass either -e or -f, not both")
+	}
+	binary, err := findCqlsh()
+	if err != nil {
+		return 1, err
+	}
+	client, err := makeClient()
+	if err != nil {
+		return 1, fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, args[0])
+	if err != nil {
+		return 1, err
+	}
+	bundlePath, err := cachedBundle(client, db.Id, time.Now())
+	if err != nil {
+		return 1, err
+	}
+	rcFile, err := writeCqlshrc()
+	if err != nil {
+		return 1, err
+	}
+	defer removeCqlshrc(rcFile)
+	cqlshArgs := []string{"--cqlshrc", rcFile, "-b", bundlePath}
+	keyspace := cqlshKeyspace
+	if keyspace == "" {
+		keyspace = deref(db.Info.Keyspace)
+	}
+	if keyspace != "" {
+		cqlshArgs = append(cqlshArgs, "-k", keyspace)
+	}
+	if cqlshExecute != "" {
+		cqlshArgs = append(cqlshArgs, "-e", cqlshExecute)
+	}
+	if cqlshFile != "" {
+		cqlshArgs = append(cqlshArgs, "-f", cqlshFile)
+	}
+	cqlshArgs = append(cqlshArgs, args[1:]...)
+	return runCqlsh(binary, cqlshArgs)
+}
+
+// findCqlsh resolves --cqlsh-path, then $ASTRA_CQLSH_PATH, then cqlsh on the PATH
+func findCqlsh() (string, error) {
+	binary := cqlshPath
+	if binary == "" {
+		binary = os.Getenv(cqlshPathEnv)
+	}
+	if binary == "" {
+		binary = "cqlsh"
+	}
+	found, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("unable to find cqlsh '%v' with error %v, install it or pass --cqlsh-path", binary, err)
+	}
+	return found, nil
+}
+
+// cachedBundle returns the external bundle of the database saved in the cache directory. The bundle is downloaded
+// again when it is missing, damaged or its certificates are no longer valid
+func cachedBundle(client pkg.Client, id string, now time.Time) (string, error) {
+	dir, err := bundleCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find bundle cache with error %v", err)
+	}
+	bundlePath := path.Join(dir, pkg.PathWithEnv(id+".zip"))
+	if b, err := bundle.Open(bundlePath); err == nil && len(b.Check(now)) == 0 {
+		return bundlePath, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("unable to create directory '%v' with error %v", dir, err)
+	}
+	secBundle, err := client.GetSecureBundle(id)
+	if err != nil {
+		return "", fmt.Errorf("unable to get '%s' with error %v", id, err)
+	}
+	urlToDownload, err := bundleURL(id, availableBundles(secBundle), "external")
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := httputils.DownloadZip(urlToDownload, bundlePath); err != nil {
+		return "", fmt.Errorf("error downloading bundle '%v'", err)
+	}
+	return bundlePath, nil
+}
+
+// loginCredentials are the CQL username and password of astra-cli login, token and the token or the client id and secret
+func loginCredentials() (string, string, error) {
+	token, clientInfo, err := storedCreds()
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read credentials with error %v", err)
+	}
+	if token == "" {
+		return clientInfo.ClientID, clientInfo.ClientSecret, nil
+	}
+	return "token", token, nil
+}
+
+// writeCqlshrc saves the credentials of astra-cli login in a temporary cqlshrc only the user can read
+func writeCqlshrc() (string, error) {
+	username, password, err := loginCredentials()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "astra-cqlshrc-*")
+	if err != nil {
+		return "", fmt.Errorf("unable to create cqlshrc with error %v", err)
+	}
+	_, err = fmt.Fprintf(f, "[authentication]\nusername = %s\npassword = %s\n", iniValue(username), iniValue(password))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		removeCqlshrc(f.Name())
+		return "", fmt.Errorf("unable to write cqlshrc with error %v", err)
+	}
+	return f.Name(), nil
+}
+
+// removeCqlshrc deletes the temporary cqlshrc, failing to do so leaves the credentials on disk so the user is told
+func removeCqlshrc(rcFile string) {
+	if err := os.Remove(rcFile); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "warning: unable to remove %v with error %v, delete it by hand\n", rcFile, err)
+	}
+}
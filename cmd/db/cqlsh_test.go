@@ -0,0 +1,197 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+const cqlshDbID = "2c3bc0d6-5e3e-4d77-81c8-d95a35bdc58b"
+
+// cqlshRun is what runCqlsh was called with, the cqlshrc is read while cqlsh would be running
+type cqlshRun struct {
+	binary  string
+	args    []string
+	cqlshrc string
+}
+
+func withCqlsh(t *testing.T, token string, exitCode int) (*cqlshRun, string) {
+	// setting package variables by hand, there be dragons
+	dir := t.TempDir()
+	cqlshPath = path.Join(dir, "cqlsh")
+	if err := os.WriteFile(cqlshPath, []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := path.Join(dir, "bundles")
+	originalCache := bundleCacheDir
+	bundleCacheDir = func() (string, error) {
+		return cacheDir, nil
+	}
+	originalCreds := storedCreds
+	storedCreds = func() (string, pkg.ClientInfo, error) {
+		if token == "" {
+			return "", pkg.ClientInfo{ClientID: "id", ClientSecret: "secret"}, nil
+		}
+		return token, pkg.ClientInfo{}, nil
+	}
+	run := &cqlshRun{}
+	originalRun := runCqlsh
+	runCqlsh = func(binary string, args []string) (int, error) {
+		run.binary = binary
+		run.args = args
+		rc, err := os.ReadFile(args[1])
+		if err != nil {
+			t.Fatalf("cqlshrc is missing while cqlsh runs %v", err)
+		}
+		run.cqlshrc = string(rc)
+		return exitCode, nil
+	}
+	t.Cleanup(func() {
+		cqlshKeyspace = ""
+		cqlshExecute = ""
+		cqlshFile = ""
+		cqlshPath = ""
+		bundleCacheDir = originalCache
+		storedCreds = originalCreds
+		runCqlsh = originalRun
+	})
+	return run, cacheDir
+}
+
+func cqlshDb() astraops.Database {
+	name := "mydb"
+	keyspace := "ks1"
+	return astraops.Database{Id: cqlshDbID, Info: astraops.DatabaseInfo{Name: &name, Keyspace: &keyspace}}
+}
+
+func TestCqlsh(t *testing.T) {
+	_, mockClient := serveBundle(t)
+	mockClient.Databases = []astraops.Database{cqlshDb()}
+	run, cacheDir := withCqlsh(t, "AstraCS:secret", 3)
+	code, err := executeCqlsh([]string{cqlshDbID}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if code != 3 {
+		t.Errorf("expected exit code of cqlsh 3 but was %v", code)
+	}
+	if run.binary != cqlshPath {
+		t.Errorf("expected %v to run but was %v", cqlshPath, run.binary)
+	}
+	bundlePath := path.Join(cacheDir, pkg.PathWithEnv(cqlshDbID+".zip"))
+	expected := []string{"--cqlshrc", run.args[1], "-b", bundlePath, "-k", "ks1"}
+	if !reflect.DeepEqual(run.args, expected) {
+		t.Errorf("expected args %v but was %v", expected, run.args)
+	}
+	for _, arg := range run.args {
+		if strings.Contains(arg, "AstraCS") {
+			t.Errorf("token passed on the command line %v", run.args)
+		}
+	}
+	expectedRc := "[authentication]\nusername = token\npassword = AstraCS:secret\n"
+	if run.cqlshrc != expectedRc {
+		t.Errorf("expected cqlshrc %q but was %q", expectedRc, run.cqlshrc)
+	}
+	if _, err := os.Stat(run.args[1]); !os.IsNotExist(err) {
+		t.Errorf("expected cqlshrc to be removed but stat returned %v", err)
+	}
+	if _, err := os.Stat(bundlePath); err != nil {
+		t.Errorf("expected bundle to be cached %v", err)
+	}
+}
+
+func TestCqlshUsesCachedBundle(t *testing.T) {
+	_, mockClient := serveBundle(t)
+	mockClient.Databases = []astraops.Database{cqlshDb(), cqlshDb()}
+	withCqlsh(t, "AstraCS:secret", 0)
+	for i := 0; i < 2; i++ {
+		if _, err := executeCqlsh([]string{cqlshDbID}, func() (pkg.Client, error) {
+			return mockClient, nil
+		}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	expected := []interface{}{cqlshDbID, cqlshDbID, cqlshDbID}
+	if !reflect.DeepEqual(mockClient.Calls(), expected) {
+		t.Errorf("expected one bundle lookup %v but was %v", expected, mockClient.Calls())
+	}
+}
+
+func TestCqlshServiceAccountAndFlags(t *testing.T) {
+	_, mockClient := serveBundle(t)
+	mockClient.Databases = []astraops.Database{cqlshDb()}
+	run, _ := withCqlsh(t, "", 0)
+	cqlshKeyspace = "other"
+	cqlshExecute = "select * from t"
+	if _, err := executeCqlsh([]string{"mydb", "--debug"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{"-k", "other", "-e", "select * from t", "--debug"}
+	if !reflect.DeepEqual(run.args[4:], expected) {
+		t.Errorf("expected args %v but was %v", expected, run.args[4:])
+	}
+	expectedRc := "[authentication]\nusername = id\npassword = secret\n"
+	if run.cqlshrc != expectedRc {
+		t.Errorf("expected cqlshrc %q but was %q", expectedRc, run.cqlshrc)
+	}
+}
+
+func TestCqlshPasswordWithPercent(t *testing.T) {
+	_, mockClient := serveBundle(t)
+	mockClient.Databases = []astraops.Database{cqlshDb()}
+	run, _ := withCqlsh(t, "AstraCS:50%off", 0)
+	if _, err := executeCqlsh([]string{cqlshDbID}, func() (pkg.Client, error) {
+		return mockClient, nil
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expectedRc := "[authentication]\nusername = token\npassword = AstraCS:50%%off\n"
+	if run.cqlshrc != expectedRc {
+		t.Errorf("expected cqlshrc %q but was %q", expectedRc, run.cqlshrc)
+	}
+}
+
+func TestCqlshNotFound(t *testing.T) {
+	withCqlsh(t, "AstraCS:secret", 0)
+	cqlshPath = path.Join(t.TempDir(), "missing")
+	_, err := executeCqlsh([]string{cqlshDbID}, func() (pkg.Client, error) {
+		t.Fatal("should not login without cqlsh")
+		return nil, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "--cqlsh-path") {
+		t.Errorf("expected error pointing at --cqlsh-path but was %v", err)
+	}
+}
+
+func TestCqlshExecuteAndFile(t *testing.T) {
+	withCqlsh(t, "AstraCS:secret", 0)
+	cqlshExecute = "select * from t"
+	cqlshFile = "schema.cql"
+	if _, err := executeCqlsh([]string{cqlshDbID}, nil); err == nil {
+		t.Error("expected error passing -e and -f")
+	}
+}
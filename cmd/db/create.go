@@ -16,10 +16,14 @@
 package db
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
 	astraops "github.com/datastax/astra-client-go/v2/astra"
 	"github.com/spf13/cobra"
 )
@@ -29,6 +33,8 @@ var createDbKeyspace string
 var createDbRegion string
 var createDbTier string
 var createDbCloudProvider string
+var createInteractive bool
+var createFile string
 
 func init() {
 	CreateCmd.Flags().StringVarP(&createDbName, "name", "n", "", "name to give to the Astra Database")
@@ -36,6 +42,8 @@ func init() {
 	CreateCmd.Flags().StringVarP(&createDbRegion, "region", "r", "us-east1", "region to give to the Astra Database")
 	CreateCmd.Flags().StringVarP(&createDbTier, "tier", "t", "serverless", "tier to give to the Astra Database")
 	CreateCmd.Flags().StringVarP(&createDbCloudProvider, "cloudProvider", "l", "GCP", "cloud provider flag to give to the Astra Database")
+	CreateCmd.Flags().StringVarP(&createFile, "file", "f", "", "spec file in yaml or json with the databases to create, the format db export writes")
+	CreateCmd.Flags().BoolVarP(&createInteractive, "interactive", "i", false, "guided creation that walks through the available clouds, regions and tiers")
 }
 
 // CreateCmd creates a database in Astra
@@ -58,6 +66,12 @@ func executeCreate(makeClient func() (pkg.Client, error)) error {
 	if err != nil {
 		return fmt.Errorf("unable to login with error %v", err)
 	}
+	if createFile != "" {
+		if createInteractive {
+			return fmt.Errorf("--file and --interactive cannot be used together")
+		}
+		return createFromSpec(client, createFile)
+	}
 	createDb := astraops.DatabaseInfoCreate{
 		Name:          createDbName,
 		Keyspace:      createDbKeyspace,
@@ -66,10 +80,52 @@ func executeCreate(makeClient func() (pkg.Client, error)) error {
 		Tier:          astraops.Tier(createDbTier),
 		CloudProvider: astraops.CloudProvider(createDbCloudProvider),
 	}
+	if createInteractive {
+		var confirmed bool
+		createDb, confirmed, err = runCreateWizard(client, bufio.NewReader(stdin), os.Stdout)
+		if err != nil {
+			return fmt.Errorf("unable to complete interactive create with error %v", err)
+		}
+		if !confirmed {
+			fmt.Println("database creation cancelled")
+			return nil
+		}
+	}
 	db, err := client.CreateDb(createDb)
 	if err != nil {
 		return fmt.Errorf("unable to create '%v' with error %v", createDb, err)
 	}
+	if env.DryRun {
+		fmt.Printf("dry run, would create database %v\n", createDb.Name)
+		return nil
+	}
 	fmt.Printf("database %v created\n", db.Id)
 	return nil
 }
+
+// createFromSpec creates every database of the spec file along with its additional keyspaces and regions
+func createFromSpec(client pkg.Client, file string) error {
+	s, err := spec.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	plan, err := spec.NewPlan(s, nil, false)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, r := range spec.Apply(client, plan, os.Stdout, nil) {
+		switch {
+		case r.Err != nil:
+			failed = append(failed, fmt.Sprintf("'%v' with error %v", r.Action.Description(), r.Err))
+		case r.Action.Type == spec.ActionCreate && r.DryRun:
+			fmt.Printf("dry run, would create database %v\n", r.Action.Database)
+		case r.Action.Type == spec.ActionCreate:
+			fmt.Printf("database %v created\n", r.Action.Database)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to create from '%v': %v", file, strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// runCreateWizard walks through the choices needed to create a database. The cloud, region and tier options come
+// from the live tier information so only combinations the account can actually use are offered. The values of the
+// create flags are used as the defaults. Returns false when the database creation was not confirmed
+func runCreateWizard(client pkg.Client, reader *bufio.Reader, out io.Writer) (astraops.DatabaseInfoCreate, bool, error) {
+	name, err := prompt(reader, out, "database name", createDbName)
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+	keyspace, err := prompt(reader, out, "keyspace", createDbKeyspace)
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+	tiers, err := client.GetTierInfo()
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, fmt.Errorf("unable to get tiers with error %v", err)
+	}
+	tiers = availableTiers(tiers)
+	if len(tiers) == 0 {
+		return astraops.DatabaseInfoCreate{}, false, fmt.Errorf("there is no tier with remaining quota available to this account")
+	}
+
+	clouds := uniqueSorted(tiers, func(t astraops.AvailableRegionCombination) string { return string(t.CloudProvider) })
+	if err := writeOptions(out, []string{"cloud provider"}, clouds, func(c string) []string { return []string{c} }); err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+	cloud, err := choose(reader, out, "cloud provider", clouds, createDbCloudProvider)
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+
+	cloudTiers := filterTiers(tiers, func(t astraops.AvailableRegionCombination) bool { return string(t.CloudProvider) == cloud })
+	regions := uniqueSorted(cloudTiers, func(t astraops.AvailableRegionCombination) string { return t.Region })
+	err = writeOptions(out, []string{"region", "tiers", "cost per month from", "databases left"}, regions, func(r string) []string {
+		regionTiers := filterTiers(cloudTiers, func(t astraops.AvailableRegionCombination) bool { return t.Region == r })
+		var names []string
+		cheapest := regionTiers[0]
+		databasesLeft := 0
+		for _, t := range regionTiers {
+			names = append(names, string(t.Tier))
+			if costPerMonth(t) < costPerMonth(cheapest) {
+				cheapest = t
+			}
+			if left := t.DatabaseCountLimit - t.DatabaseCountUsed; left > databasesLeft {
+				databasesLeft = left
+			}
+		}
+		return []string{r, strings.Join(names, ","), formatCost(cheapest.Cost.CostPerMonthCents), fmt.Sprintf("%v", databasesLeft)}
+	})
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+	region, err := choose(reader, out, "region", regions, createDbRegion)
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+
+	regionTiers := filterTiers(cloudTiers, func(t astraops.AvailableRegionCombination) bool { return t.Region == region })
+	tierNames := uniqueSorted(regionTiers, func(t astraops.AvailableRegionCombination) string { return string(t.Tier) })
+	err = writeOptions(out, []string{"tier", "cost per month", "cost per minute", "databases left", "capacity units left"}, tierNames, func(name string) []string {
+		t := filterTiers(regionTiers, func(t astraops.AvailableRegionCombination) bool { return string(t.Tier) == name })[0]
+		return []string{
+			name,
+			formatCost(t.Cost.CostPerMonthCents),
+			formatCost(t.Cost.CostPerMinCents),
+			fmt.Sprintf("%v", t.DatabaseCountLimit-t.DatabaseCountUsed),
+			fmt.Sprintf("%v", t.CapacityUnitsLimit-t.CapacityUnitsUsed),
+		}
+	})
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+	tier, err := choose(reader, out, "tier", tierNames, createDbTier)
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+
+	createDb := astraops.DatabaseInfoCreate{
+		Name:          name,
+		Keyspace:      keyspace,
+		CapacityUnits: 1,
+		Region:        region,
+		Tier:          astraops.Tier(tier),
+		CloudProvider: astraops.CloudProvider(cloud),
+	}
+	fmt.Fprintln(out, "\nsummary")
+	err = pkg.WriteRows(out, [][]string{
+		{"name", createDb.Name},
+		{"keyspace", createDb.Keyspace},
+		{"cloud provider", string(createDb.CloudProvider)},
+		{"region", createDb.Region},
+		{"tier", string(createDb.Tier)},
+	})
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	fmt.Fprintf(out, "\n\nequivalent command:\n%v\n\n", createCommandLine(createDb))
+	confirmed, err := confirm(reader, out, "create database")
+	if err != nil {
+		return astraops.DatabaseInfoCreate{}, false, err
+	}
+	return createDb, confirmed, nil
+}
+
+// createCommandLine returns the non interactive command that creates the same database
+func createCommandLine(createDb astraops.DatabaseInfoCreate) string {
+	return strings.Join([]string{
+		"astra-cli db create",
+		"--name", shellQuote(createDb.Name),
+		"--keyspace", shellQuote(createDb.Keyspace),
+		"--cloudProvider", shellQuote(string(createDb.CloudProvider)),
+		"--region", shellQuote(createDb.Region),
+		"--tier", shellQuote(string(createDb.Tier)),
+	}, " ")
+}
+
+// shellQuote single quotes the value when it contains anything a shell would interpret
+func shellQuote(value string) string {
+	if value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.") == "" {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// availableTiers drops the combinations where the database quota is already used up
+func availableTiers(tiers []astraops.AvailableRegionCombination) []astraops.AvailableRegionCombination {
+	return filterTiers(tiers, func(t astraops.AvailableRegionCombination) bool {
+		return t.DatabaseCountLimit > t.DatabaseCountUsed
+	})
+}
+
+func filterTiers(tiers []astraops.AvailableRegionCombination, keep func(astraops.AvailableRegionCombination) bool) []astraops.AvailableRegionCombination {
+	var filtered []astraops.AvailableRegionCombination
+	for _, t := range tiers {
+		if keep(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+func uniqueSorted(tiers []astraops.AvailableRegionCombination, value func(astraops.AvailableRegionCombination) string) []string {
+	var values []string
+	for _, t := range tiers {
+		v := value(t)
+		if !contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func costPerMonth(t astraops.AvailableRegionCombination) float64 {
+	if t.Cost.CostPerMonthCents == nil {
+		return 0.0
+	}
+	return *t.Cost.CostPerMonthCents
+}
+
+// writeOptions prints a numbered table of the options so they can be picked by number
+func writeOptions(out io.Writer, header []string, options []string, columns func(string) []string) error {
+	rows := [][]string{append([]string{"#"}, header...)}
+	for i, o := range options {
+		rows = append(rows, append([]string{fmt.Sprintf("%v", i+1)}, columns(o)...))
+	}
+	fmt.Fprintln(out)
+	if err := pkg.WriteRows(out, rows); err != nil {
+		return fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
@@ -0,0 +1,129 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func wizardTiers() []astraops.AvailableRegionCombination {
+	cost := 1000.0
+	return []astraops.AvailableRegionCombination{
+		{Tier: "serverless", CloudProvider: "GCP", Region: "us-east1", DatabaseCountLimit: 5, DatabaseCountUsed: 1, Cost: astraops.Costs{CostPerMonthCents: &cost}},
+		{Tier: "serverless", CloudProvider: "AWS", Region: "us-east-1", DatabaseCountLimit: 5, DatabaseCountUsed: 5},
+		{Tier: "serverless", CloudProvider: "AWS", Region: "us-west-2", DatabaseCountLimit: 5, DatabaseCountUsed: 0},
+		{Tier: "C10", CloudProvider: "AWS", Region: "us-west-2", DatabaseCountLimit: 5, DatabaseCountUsed: 0, CapacityUnitsLimit: 12},
+	}
+}
+
+func TestCreateWizard(t *testing.T) {
+	// setting package variables by hand, there be dragons
+	createDbName = ""
+	createDbKeyspace = ""
+	createDbRegion = "us-east1"
+	createDbTier = "serverless"
+	createDbCloudProvider = "GCP"
+	mockClient := &tests.MockClient{Tiers: wizardTiers()}
+	var out bytes.Buffer
+	// AWS is option 1, us-east-1 is not offered since its quota is used, C10 is option 1
+	input := "mydb\nmyks\n1\nus-west-2\n1\ny\n"
+	createDb, confirmed, err := runCreateWizard(mockClient, bufio.NewReader(strings.NewReader(input)), &out)
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if !confirmed {
+		t.Fatal("expected the creation to be confirmed")
+	}
+	expected := astraops.DatabaseInfoCreate{
+		Name:          "mydb",
+		Keyspace:      "myks",
+		CapacityUnits: 1,
+		CloudProvider: "AWS",
+		Region:        "us-west-2",
+		Tier:          "C10",
+	}
+	if createDb != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, createDb)
+	}
+	if strings.Contains(out.String(), "us-east-1 ") {
+		t.Errorf("region with no quota left should not be offered but output was\n%v", out.String())
+	}
+	expectedCmd := "astra-cli db create --name mydb --keyspace myks --cloudProvider AWS --region us-west-2 --tier C10"
+	if !strings.Contains(out.String(), expectedCmd) {
+		t.Errorf("expected output to contain '%v' but was\n%v", expectedCmd, out.String())
+	}
+}
+
+func TestCreateWizardDefaults(t *testing.T) {
+	createDbName = "flagdb"
+	createDbKeyspace = "flagks"
+	createDbRegion = "us-east1"
+	createDbTier = "serverless"
+	createDbCloudProvider = "GCP"
+	mockClient := &tests.MockClient{Tiers: wizardTiers()}
+	var out bytes.Buffer
+	createDb, confirmed, err := runCreateWizard(mockClient, bufio.NewReader(strings.NewReader("\n\n\n\n\nyes\n")), &out)
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if !confirmed {
+		t.Fatal("expected the creation to be confirmed")
+	}
+	if createDb.Name != "flagdb" || createDb.CloudProvider != "GCP" || createDb.Region != "us-east1" || createDb.Tier != "serverless" {
+		t.Errorf("expected the flag values as defaults but was '%v'", createDb)
+	}
+	if !strings.Contains(out.String(), "$10.00") {
+		t.Errorf("expected the cost to be shown but output was\n%v", out.String())
+	}
+}
+
+func TestCreateInteractiveCancelled(t *testing.T) {
+	createInteractive = true
+	originalStdin := stdin
+	defer func() {
+		createInteractive = false
+		stdin = originalStdin
+	}()
+	createDbName = "flagdb"
+	createDbKeyspace = "flagks"
+	stdin = strings.NewReader("\n\n\n\n\nn\n")
+	mockClient := &tests.MockClient{Tiers: wizardTiers()}
+	err := executeCreate(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 0 {
+		t.Errorf("expected no create call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if actual := shellQuote("my db's"); actual != `'my db'\''s'` {
+		t.Errorf("expected quoted value but was %v", actual)
+	}
+	if actual := shellQuote("us-east1"); actual != "us-east1" {
+		t.Errorf("expected unquoted value but was %v", actual)
+	}
+}
@@ -17,9 +17,13 @@ package db
 
 import (
 	"fmt"
+	"os"
+	"path"
 	"testing"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
 	tests "github.com/datastax-labs/astra-cli/pkg/tests"
 	astraops "github.com/datastax/astra-client-go/v2/astra"
 )
@@ -155,3 +159,74 @@ func TestCreateSetsProvider(t *testing.T) {
 		t.Errorf("expected '%v' but was '%v'", arg0.CloudProvider, createDbCloudProvider)
 	}
 }
+
+func TestCreateFromFile(t *testing.T) {
+	// setting package variables by hand, there be dragons
+	createFile = path.Join(t.TempDir(), "spec.yaml")
+	originalInterval := spec.WaitInterval
+	spec.WaitInterval = 0
+	defer func() {
+		createFile = ""
+		spec.WaitInterval = originalInterval
+	}()
+	content := `
+databases:
+  - name: orders
+    keyspaces: [orders, audit]
+    cloudProvider: AWS
+    regions: [us-east-1]
+`
+	if err := os.WriteFile(createFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{
+			{Id: "abc"},
+			{Id: "abc", Status: astraops.StatusEnumACTIVE},
+		},
+	}
+	err := executeCreate(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	arg0 := mockClient.Call(0).(astraops.DatabaseInfoCreate)
+	if arg0.Name != "orders" || arg0.Keyspace != "orders" || arg0.CloudProvider != "AWS" || arg0.Region != "us-east-1" {
+		t.Errorf("unexpected create %v", arg0)
+	}
+	addKeyspace := mockClient.Call(1).([]interface{})
+	if addKeyspace[0] != "abc" || addKeyspace[1] != "audit" {
+		t.Errorf("expected audit to be added to abc but was %v", addKeyspace)
+	}
+}
+
+func TestCreateFromFileDryRun(t *testing.T) {
+	// setting package variables by hand, there be dragons
+	createFile = path.Join(t.TempDir(), "spec.yaml")
+	env.DryRun = true
+	defer func() {
+		createFile = ""
+		env.DryRun = false
+	}()
+	content := `
+databases:
+  - name: orders
+    keyspaces: [orders, audit]
+    regions: [us-east-1, us-west-2]
+`
+	if err := os.WriteFile(createFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// the dry run client returns an empty database for the create
+	mockClient := &tests.MockClient{}
+	err := executeCreate(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected only the create to be sent but was %v", mockClient.Calls())
+	}
+}
@@ -16,27 +16,42 @@
 package db
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
 	"github.com/spf13/cobra"
 )
 
+var deleteYes bool
+
+func init() {
+	DeleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "skip the confirmation, required when not running in a terminal")
+	AddOverrideProtectionFlag(DeleteCmd)
+	addBulkFlags(DeleteCmd)
+}
+
 // DeleteCmd provides the delete database command
 var DeleteCmd = &cobra.Command{
-	Use:   "delete <id>",
+	Use:   "delete <id>...",
 	Short: "delete database by databaseID",
-	Long:  `deletes a database from your Astra account by ID`,
-	Args:  cobra.ExactArgs(1),
+	Long: `deletes databases from your Astra account by ID or by --selector. The name of the database has to be typed to confirm unless --yes is passed,
+when deleting several databases their number has to be typed instead`,
+	Args: bulkArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
 		creds := &pkg.Creds{}
 		msg, err := executeDelete(args, creds.Login)
+		if msg != "" {
+			fmt.Fprintln(os.Stdout, msg)
+		}
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-		fmt.Fprintln(os.Stdout, msg)
 	},
 }
 
@@ -45,10 +60,65 @@ func executeDelete(args []string, makeClient func() (pkg.Client, error)) (string
 	if err != nil {
 		return "", fmt.Errorf("unable to login with error '%v'", err)
 	}
-	id := args[0]
-	fmt.Printf("starting to delete database %v\n", id)
+	if isBulk(args) {
+		return executeBulk(client, args, bulkOperation{
+			name:    "delete",
+			done:    "deleted",
+			confirm: true,
+			run: func(client pkg.Client, db astraops.Database) error {
+				return client.Terminate(db.Id, false)
+			},
+		})
+	}
+	// resolved so a name can be passed like with --dry-run, everything after works on the id
+	db, err := pkg.ResolveDb(client, args[0])
+	if err != nil {
+		return "", err
+	}
+	id := db.Id
+	if err := GuardDb(db, "delete"); err != nil {
+		return "", err
+	}
+	if err := confirmDelete(db); err != nil {
+		return "", err
+	}
+	if !env.DryRun {
+		fmt.Printf("starting to delete database %v\n", id)
+	}
 	if err := client.Terminate(id, false); err != nil {
 		return "", fmt.Errorf("unable to delete '%s' with error %v", id, err)
 	}
+	if env.DryRun {
+		return fmt.Sprintf("dry run, would delete database %v", id), nil
+	}
 	return fmt.Sprintf("database %v deleted", id), nil
 }
+
+// confirmDelete shows the database about to be deleted and asks for its name to be typed. Without a terminal
+// nobody can answer so --yes is required
+func confirmDelete(db astraops.Database) error {
+	name := db.Id
+	if db.Info.Name != nil && *db.Info.Name != "" {
+		name = *db.Info.Name
+	}
+	var region string
+	if db.Info.Region != nil {
+		region = *db.Info.Region
+	}
+	fmt.Printf("database %v (%v) in region %v with status %v will be deleted\n", name, db.Id, region, db.Status)
+	if deleteYes || env.DryRun {
+		return nil
+	}
+	if !isTerminal() {
+		return fmt.Errorf("refusing to delete '%v' without confirmation when not running in a terminal, pass --yes to delete anyway", db.Id)
+	}
+	fmt.Printf("type the database name '%v' to confirm: ", name)
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && strings.TrimSpace(answer) == "" {
+		return fmt.Errorf("unable to read confirmation with error %v", err)
+	}
+	if strings.TrimSpace(answer) != name {
+		return fmt.Errorf("'%v' does not match the database name '%v', database not deleted", strings.TrimSpace(answer), name)
+	}
+	return nil
+}
@@ -16,15 +16,27 @@
 package db
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
 	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
 )
 
 func TestDelete(t *testing.T) {
-	mockClient := &tests.MockClient{}
+	withPolicy(t, "")
+	// setting package variables by hand, there be dragons
+	deleteYes = true
+	defer func() {
+		deleteYes = false
+	}()
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{Id: "123"}},
+	}
 	id := "123"
 	msg, err := executeDelete([]string{id}, func() (pkg.Client, error) {
 		return mockClient, nil
@@ -32,11 +44,11 @@ func TestDelete(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error '%v'", err)
 	}
-	if len(mockClient.Calls()) != 1 {
-		t.Fatalf("expected 1 call but was %v", len(mockClient.Calls()))
+	if len(mockClient.Calls()) != 2 {
+		t.Fatalf("expected 2 calls but was %v", len(mockClient.Calls()))
 	}
-	if id != mockClient.Call(0) {
-		t.Errorf("expected '%v' but was '%v'", id, mockClient.Call(0))
+	if id != mockClient.Call(1) {
+		t.Errorf("expected '%v' but was '%v'", id, mockClient.Call(1))
 	}
 	expected := "database 123 deleted"
 	if expected != msg {
@@ -67,8 +79,14 @@ func TestDeleteLoginError(t *testing.T) {
 }
 
 func TestDeleteError(t *testing.T) {
+	withPolicy(t, "")
+	deleteYes = true
+	defer func() {
+		deleteYes = false
+	}()
 	mockClient := &tests.MockClient{
-		ErrorQueue: []error{fmt.Errorf("timeout error")},
+		Databases:  []astraops.Database{{Id: "123"}},
+		ErrorQueue: []error{nil, fmt.Errorf("timeout error")},
 	}
 	id := "123"
 	msg, err := executeDelete([]string{id}, func() (pkg.Client, error) {
@@ -77,14 +95,121 @@ func TestDeleteError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error but none came")
 	}
-	if len(mockClient.Calls()) != 1 {
-		t.Fatalf("expected 1 call but was %v", len(mockClient.Calls()))
+	if len(mockClient.Calls()) != 2 {
+		t.Fatalf("expected 2 calls but was %v", len(mockClient.Calls()))
 	}
-	if id != mockClient.Call(0) {
-		t.Errorf("expected '%v' but was '%v'", id, mockClient.Call(0))
+	if id != mockClient.Call(1) {
+		t.Errorf("expected '%v' but was '%v'", id, mockClient.Call(1))
 	}
 	expected := ""
 	if expected != msg {
 		t.Errorf("expected '%v' but was '%v'", expected, msg)
 	}
 }
+
+func withTerminal(t *testing.T, terminal bool, input string) {
+	originalStdin := stdin
+	originalIsTerminal := isTerminal
+	stdin = strings.NewReader(input)
+	isTerminal = func() bool { return terminal }
+	t.Cleanup(func() {
+		stdin = originalStdin
+		isTerminal = originalIsTerminal
+	})
+}
+
+func TestDeleteTypedName(t *testing.T) {
+	withPolicy(t, "")
+	withTerminal(t, true, "mydb\n")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{Id: "123", Info: astraops.DatabaseInfo{Name: astraops.StringPtr("mydb")}}},
+	}
+	_, err := executeDelete([]string{"123"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 2 {
+		t.Errorf("expected 2 calls but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestDeleteTypedNameMismatch(t *testing.T) {
+	withPolicy(t, "")
+	withTerminal(t, true, "otherdb\n")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{Id: "123", Info: astraops.DatabaseInfo{Name: astraops.StringPtr("mydb")}}},
+	}
+	_, err := executeDelete([]string{"123"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	expected := "'otherdb' does not match the database name 'mydb', database not deleted"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected only the lookup call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestDeleteNonInteractiveRefuses(t *testing.T) {
+	withPolicy(t, "")
+	withTerminal(t, false, "mydb\n")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{Id: "123", Info: astraops.DatabaseInfo{Name: astraops.StringPtr("mydb")}}},
+	}
+	_, err := executeDelete([]string{"123"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected only the lookup call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestDeleteDryRun(t *testing.T) {
+	withPolicy(t, "")
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{Id: "123"}},
+	}
+	msg, err := executeDelete([]string{"123"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	expected := "dry run, would delete database 123"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
+
+func TestDeleteByNameDryRun(t *testing.T) {
+	withPolicy(t, "")
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	mockClient := &tests.MockClient{
+		// the first database is consumed by the failed lookup of the name as an id
+		Databases:  []astraops.Database{{}, {Id: "123", Info: astraops.DatabaseInfo{Name: astraops.StringPtr("mydb")}}},
+		ErrorQueue: []error{errors.New("not found")},
+	}
+	msg, err := executeDelete([]string{"mydb"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	expected := "dry run, would delete database 123"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+	calls := mockClient.Calls()
+	if calls[len(calls)-1] != "123" {
+		t.Errorf("expected the id to be terminated but was %v", calls[len(calls)-1])
+	}
+}
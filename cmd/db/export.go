@@ -0,0 +1,108 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var exportFmt string
+var exportAll bool
+
+func init() {
+	ExportCmd.Flags().StringVarP(&exportFmt, "output", "o", pkg.YAMLFormat, "Output format for the spec, yaml or json")
+	ExportCmd.Flags().BoolVarP(&exportAll, "all", "a", false, "export every database that is not terminated")
+}
+
+// ExportCmd writes existing databases as a spec that db create -f and apply accept
+var ExportCmd = &cobra.Command{
+	Use:   "export [id...]",
+	Short: "exports databases as a spec",
+	Long:  `exports the databases with the ids given, or all of them with --all, in the spec format used by db create -f, plan and apply`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executeExport(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(msg)
+	},
+}
+
+func executeExport(args []string, login func() (pkg.Client, error)) (string, error) {
+	if len(args) == 0 && !exportAll {
+		return "", &pkg.ParseError{
+			Args: args,
+			Err:  fmt.Errorf("pass database ids or --all"),
+		}
+	}
+	if len(args) > 0 && exportAll {
+		return "", &pkg.ParseError{
+			Args: args,
+			Err:  fmt.Errorf("database ids cannot be combined with --all"),
+		}
+	}
+	client, err := login()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	var dbs []astraops.Database
+	if exportAll {
+		if dbs, err = client.ListDb("", "", "", pkg.MaxListLimit); err != nil {
+			return "", fmt.Errorf("unable to get list of dbs with error '%v'", err)
+		}
+	} else {
+		for _, id := range args {
+			db, err := client.FindDb(id)
+			if err != nil {
+				return "", fmt.Errorf("unable to get '%s' with error %v", id, err)
+			}
+			dbs = append(dbs, db)
+		}
+	}
+	s := spec.Spec{Databases: []spec.Database{}}
+	for _, db := range dbs {
+		s.Databases = append(s.Databases, spec.FromDatabase(db))
+	}
+	if err := s.Validate(); err != nil {
+		return "", fmt.Errorf("unable to export, db create -f and apply would reject the spec with error %v", err)
+	}
+	switch exportFmt {
+	case pkg.YAMLFormat:
+		b, err := yaml.Marshal(s)
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to yaml: '%v', Try -output json instead", err)
+		}
+		return string(b), nil
+	case pkg.JSONFormat:
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output yaml instead", err)
+		}
+		return string(b) + "\n", nil
+	default:
+		return "", fmt.Errorf("-o %q is not valid option", exportFmt)
+	}
+}
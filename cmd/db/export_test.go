@@ -0,0 +1,148 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func exportTestDb() astraops.Database {
+	tier := astraops.Tier("serverless")
+	cloud := astraops.CloudProvider("AWS")
+	cu := 1
+	additional := []string{"audit"}
+	return astraops.Database{
+		Id:              "abc",
+		Status:          astraops.StatusEnumACTIVE,
+		DataEndpointUrl: astraops.StringPtr("https://abc-us-east-1.apps.astra.datastax.com/api/rest"),
+		Info: astraops.DatabaseInfo{
+			Name:                astraops.StringPtr("orders"),
+			Keyspace:            astraops.StringPtr("orders"),
+			AdditionalKeyspaces: &additional,
+			CloudProvider:       &cloud,
+			Tier:                &tier,
+			CapacityUnits:       &cu,
+			Region:              astraops.StringPtr("us-east-1"),
+			Datacenters: &[]astraops.Datacenter{
+				{Id: astraops.StringPtr("abc-2"), Region: "us-west-2", Status: "ACTIVE"},
+				{Id: astraops.StringPtr("abc-1"), Region: "us-east-1", Status: "ACTIVE"},
+			},
+		},
+	}
+}
+
+func TestExportYaml(t *testing.T) {
+	exportFmt = pkg.YAMLFormat
+	exportAll = false
+	msg, err := executeExport([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{Databases: []astraops.Database{exportTestDb()}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `databases:
+    - name: orders
+      keyspaces:
+        - orders
+        - audit
+      cloudProvider: AWS
+      regions:
+        - us-east-1
+        - us-west-2
+      tier: serverless
+      capacityUnits: 1
+`
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+	s, err := spec.Parse([]byte(msg))
+	if err != nil {
+		t.Fatalf("exported spec cannot be read back %v", err)
+	}
+	if s.Databases[0].Name != "orders" {
+		t.Errorf("expected orders but was %v", s.Databases[0].Name)
+	}
+}
+
+func TestExportAllJSON(t *testing.T) {
+	exportFmt = pkg.JSONFormat
+	exportAll = true
+	defer func() {
+		exportAll = false
+	}()
+	other := exportTestDb()
+	other.Info.Name = astraops.StringPtr("billing")
+	mockClient := &tests.MockClient{Databases: []astraops.Database{exportTestDb(), other}}
+	msg, err := executeExport([]string{}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	s, err := spec.Parse([]byte(msg))
+	if err != nil {
+		t.Fatalf("exported spec cannot be read back %v", err)
+	}
+	if len(s.Databases) != 2 {
+		t.Errorf("expected 2 databases but was %v", len(s.Databases))
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected 1 list call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestExportInvalidSpec(t *testing.T) {
+	exportFmt = pkg.YAMLFormat
+	exportAll = true
+	defer func() {
+		exportAll = false
+	}()
+	unnamed := exportTestDb()
+	unnamed.Info.Name = nil
+	for _, c := range []struct {
+		dbs      []astraops.Database
+		expected string
+	}{
+		{[]astraops.Database{exportTestDb(), exportTestDb()}, "database orders is in the spec more than once"},
+		{[]astraops.Database{unnamed}, "database 1 in spec has no name"},
+	} {
+		msg, err := executeExport([]string{}, func() (pkg.Client, error) {
+			return &tests.MockClient{Databases: c.dbs}, nil
+		})
+		expected := "unable to export, db create -f and apply would reject the spec with error " + c.expected
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected '%v' but was '%v'", expected, err)
+		}
+		if msg != "" {
+			t.Errorf("expected nothing written but was %v", msg)
+		}
+	}
+}
+
+func TestExportNoArgs(t *testing.T) {
+	exportAll = false
+	_, err := executeExport([]string{}, func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
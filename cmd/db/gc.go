@@ -0,0 +1,213 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bulk"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcNamePrefix string
+	gcOlderThan  string
+	gcYes        bool
+)
+
+func init() {
+	GcCmd.Flags().StringVar(&gcNamePrefix, "name-prefix", "", "only databases with a name starting with this prefix are collected, required")
+	GcCmd.Flags().StringVar(&gcOlderThan, "older-than", "24h", "only databases created longer ago than this are collected, for example 24h or 7d")
+	GcCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "terminate the databases found, without it they are only listed")
+	GcCmd.Flags().IntVar(&bulkParallel, "parallel", defaultParallel, "number of databases to terminate at the same time")
+	AddOverrideProtectionFlag(GcCmd)
+}
+
+// GcCmd terminates databases left behind by CI jobs
+var GcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "terminates old databases whose name starts with a prefix",
+	Long: `finds databases whose name starts with --name-prefix and that were created longer ago than --older-than, lists them with their cost
+and terminates them when --yes is passed. Made for ephemeral databases created by CI pipelines that failed to clean up`,
+	Args: cobra.NoArgs,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executeGc(creds.Login)
+		if msg != "" {
+			fmt.Println(msg)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// executeGc lists the expired databases and terminates them if --yes is set
+func executeGc(makeClient func() (pkg.Client, error)) (string, error) {
+	if gcNamePrefix == "" {
+		return "", fmt.Errorf("--name-prefix is required so gc never considers every database of the account")
+	}
+	olderThan, err := bulk.ParseAge(gcOlderThan)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse --older-than '%v' with error %v", gcOlderThan, err)
+	}
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	dbs, err := client.ListDb("", "", "", pkg.MaxListLimit)
+	if err != nil {
+		return "", fmt.Errorf("unable to list databases with error %v", err)
+	}
+	now := time.Now()
+	expired := expiredDatabases(dbs, gcNamePrefix, olderThan, now)
+	if len(expired) == 0 {
+		return fmt.Sprintf("no databases starting with '%v' older than %v found", gcNamePrefix, gcOlderThan), nil
+	}
+	tiers, err := client.GetTierInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: unable to get tier pricing, cost estimates will be missing, with error %v\n", err)
+	}
+	var preview bytes.Buffer
+	rows := [][]string{{"id", "name", "tier", "region", "status", "age", "cost per month"}}
+	for _, db := range expired {
+		created, _ := bulk.CreationTime(db)
+		cost := "unknown"
+		if cents, ok := monthlyCost(db, tiers); ok {
+			cost = formatCost(&cents)
+		}
+		rows = append(rows, []string{db.Id, bulk.Name(db), dbTier(db), dbRegion(db), string(db.Status), now.Sub(created).Round(time.Minute).String(), cost})
+	}
+	if err := pkg.WriteRows(&preview, rows); err != nil {
+		return "", fmt.Errorf("unable to write databases with error %v", err)
+	}
+	fmt.Printf("found %v database(s) starting with '%v' older than %v\n%v\n", len(expired), gcNamePrefix, gcOlderThan, preview.String())
+	if !gcYes {
+		return "pass --yes to terminate them", nil
+	}
+	results, err := runBulk(client, expired, nil, bulkOperation{
+		name: "delete",
+		done: "terminated",
+		run: func(client pkg.Client, db astraops.Database) error {
+			return client.Terminate(db.Id, false)
+		},
+	})
+	if len(results) == 0 {
+		return "", err
+	}
+	var out bytes.Buffer
+	if writeErr := bulk.WriteResults(&out, results, doneResult("terminated")); writeErr != nil {
+		return "", fmt.Errorf("unable to write results with error %v", writeErr)
+	}
+	saved := estimateSavings(expired, results, tiers)
+	if env.DryRun {
+		fmt.Fprintf(&out, "\ndry run, estimated savings once terminated %v per month", formatCost(&saved))
+		return out.String(), err
+	}
+	fmt.Fprintf(&out, "\nestimated savings %v per month", formatCost(&saved))
+	return out.String(), err
+}
+
+// expiredDatabases are the databases with the name prefix created before olderThan, terminated ones are left out
+func expiredDatabases(dbs []astraops.Database, prefix string, olderThan time.Duration, now time.Time) []astraops.Database {
+	var expired []astraops.Database
+	for _, db := range dbs {
+		if db.Status == astraops.StatusEnumTERMINATED || db.Status == astraops.StatusEnumTERMINATING {
+			continue
+		}
+		if !strings.HasPrefix(bulk.Name(db), prefix) {
+			continue
+		}
+		created, ok := bulk.CreationTime(db)
+		if !ok || now.Sub(created) <= olderThan {
+			continue
+		}
+		expired = append(expired, db)
+	}
+	return expired
+}
+
+// monthlyCost finds the price of the database tier in its cloud and region, parked databases use the parked price.
+// Classic tiers are priced per capacity unit
+func monthlyCost(db astraops.Database, tiers []astraops.AvailableRegionCombination) (float64, bool) {
+	if db.Info.Tier == nil {
+		return 0.0, false
+	}
+	var cloud astraops.CloudProvider
+	if db.Info.CloudProvider != nil {
+		cloud = *db.Info.CloudProvider
+	}
+	for _, t := range tiers {
+		if !strings.EqualFold(string(t.Tier), string(*db.Info.Tier)) || !strings.EqualFold(string(t.CloudProvider), string(cloud)) || t.Region != dbRegion(db) {
+			continue
+		}
+		cents := t.Cost.CostPerMonthCents
+		if db.Status == astraops.StatusEnumPARKED {
+			cents = t.Cost.CostPerMonthParkedCents
+		}
+		if cents == nil {
+			return 0.0, false
+		}
+		units := 1
+		if db.Info.CapacityUnits != nil && *db.Info.CapacityUnits > 1 {
+			units = *db.Info.CapacityUnits
+		}
+		return *cents * float64(units), true
+	}
+	return 0.0, false
+}
+
+// estimateSavings adds up the monthly cost of the databases successfully terminated
+func estimateSavings(dbs []astraops.Database, results []bulk.Result, tiers []astraops.AvailableRegionCombination) float64 {
+	terminated := make(map[string]bool)
+	for _, r := range results {
+		if r.Err == nil {
+			terminated[r.ID] = true
+		}
+	}
+	var saved float64
+	for _, db := range dbs {
+		if !terminated[db.Id] {
+			continue
+		}
+		if cents, ok := monthlyCost(db, tiers); ok {
+			saved += cents
+		}
+	}
+	return saved
+}
+
+func dbTier(db astraops.Database) string {
+	if db.Info.Tier == nil {
+		return ""
+	}
+	return string(*db.Info.Tier)
+}
+
+func dbRegion(db astraops.Database) string {
+	if db.Info.Region == nil {
+		return ""
+	}
+	return *db.Info.Region
+}
@@ -0,0 +1,178 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func ciDb(id, name string, age time.Duration, status astraops.StatusEnum) astraops.Database {
+	tier := astraops.Tier("C10")
+	cloud := astraops.CloudProviderGCP
+	units := 2
+	return astraops.Database{
+		Id:           id,
+		Status:       status,
+		CreationTime: astraops.StringPtr(time.Now().Add(-age).Format(time.RFC3339)),
+		Info: astraops.DatabaseInfo{
+			Name:          astraops.StringPtr(name),
+			Tier:          &tier,
+			CloudProvider: &cloud,
+			Region:        astraops.StringPtr("us-east1"),
+			CapacityUnits: &units,
+		},
+	}
+}
+
+func withGc(t *testing.T, prefix, olderThan string, yes bool) {
+	gcNamePrefix = prefix
+	gcOlderThan = olderThan
+	gcYes = yes
+	bulkParallel = 1
+	t.Cleanup(func() {
+		gcNamePrefix = ""
+		gcOlderThan = "24h"
+		gcYes = false
+		bulkParallel = defaultParallel
+	})
+}
+
+func gcClient() *tests.MockClient {
+	perMonth := 10000.0
+	return &tests.MockClient{
+		Databases: []astraops.Database{
+			ciDb("a", "ci-abc", 48*time.Hour, astraops.StatusEnumACTIVE),
+			ciDb("b", "ci-def", time.Hour, astraops.StatusEnumACTIVE),
+			ciDb("c", "prod", 48*time.Hour, astraops.StatusEnumACTIVE),
+			ciDb("d", "ci-ghi", 48*time.Hour, astraops.StatusEnumTERMINATING),
+			ciDb("e", "ci-jkl", 72*time.Hour, astraops.StatusEnumACTIVE),
+		},
+		Tiers: []astraops.AvailableRegionCombination{
+			{Tier: "C10", CloudProvider: astraops.CloudProviderGCP, Region: "us-east1", Cost: astraops.Costs{CostPerMonthCents: &perMonth}},
+		},
+	}
+}
+
+func TestGcListsWithoutYes(t *testing.T) {
+	withPolicy(t, "")
+	withGc(t, "ci-", "24h", false)
+	mockClient := gcClient()
+	msg, err := executeGc(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if msg != "pass --yes to terminate them" {
+		t.Errorf("unexpected message '%v'", msg)
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected only the list call but was %v", mockClient.Calls())
+	}
+}
+
+func TestGcTerminates(t *testing.T) {
+	withPolicy(t, "")
+	withGc(t, "ci-", "1d", true)
+	mockClient := gcClient()
+	msg, err := executeGc(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	calls := mockClient.Calls()
+	if len(calls) != 3 || calls[1] != "a" || calls[2] != "e" {
+		t.Errorf("expected a and e to be terminated but was %v", calls)
+	}
+	if !strings.Contains(msg, "estimated savings $400.00 per month") {
+		t.Errorf("expected the savings of 2 databases with 2 capacity units but was '%v'", msg)
+	}
+}
+
+func TestGcRequiresPrefix(t *testing.T) {
+	withGc(t, "", "24h", true)
+	_, err := executeGc(func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err == nil {
+		t.Error("expected an error without a prefix")
+	}
+}
+
+func TestGcNothingFound(t *testing.T) {
+	withGc(t, "nightly-", "24h", true)
+	msg, err := executeGc(func() (pkg.Client, error) {
+		return gcClient(), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if !strings.HasPrefix(msg, "no databases starting with 'nightly-'") {
+		t.Errorf("unexpected message '%v'", msg)
+	}
+}
+
+const ciPolicy = `
+environments:
+  prod:
+    names: ["ci-abc"]
+`
+
+func TestGcSkipsProtected(t *testing.T) {
+	withPolicy(t, ciPolicy)
+	withGc(t, "ci-", "1d", true)
+	mockClient := gcClient()
+	msg, err := executeGc(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || err.Error() != "1 of 2 database(s) failed to delete" {
+		t.Errorf("unexpected error '%v'", err)
+	}
+	if !strings.Contains(msg, "pass --override-protection to delete anyway") {
+		t.Errorf("expected the protected database in the results but was '%v'", msg)
+	}
+	calls := mockClient.Calls()
+	if len(calls) != 2 || calls[1] != "e" {
+		t.Errorf("expected only e to be terminated but was %v", calls)
+	}
+	if GcCmd.Flags().Lookup("override-protection") == nil {
+		t.Error("expected gc to accept --override-protection")
+	}
+}
+
+func TestGcProtectedOverride(t *testing.T) {
+	withPolicy(t, ciPolicy)
+	withGc(t, "ci-", "1d", true)
+	overrideProtection = true
+	mockClient := gcClient()
+	_, err := executeGc(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	calls := mockClient.Calls()
+	if len(calls) != 3 || calls[1] != "a" || calls[2] != "e" {
+		t.Errorf("expected a and e to be terminated but was %v", calls)
+	}
+}
@@ -0,0 +1,162 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	"github.com/datastax-labs/astra-cli/pkg/migrate"
+	"github.com/spf13/cobra"
+)
+
+var migrateDir string
+var migrateKeyspace string
+var migrateTimeout time.Duration
+
+func init() {
+	MigrateCmd.PersistentFlags().StringVarP(&migrateDir, "dir", "d", "migrations", "directory of the numbered migrations, 0001_create_users.cql and optionally 0001_create_users.down.cql")
+	MigrateCmd.PersistentFlags().StringVarP(&migrateKeyspace, "keyspace", "k", "", "keyspace the migrations are applied to, the keyspace of the database by default")
+	MigrateCmd.PersistentFlags().DurationVar(&migrateTimeout, "timeout", cql.DefaultTimeout, "timeout of connecting and of each statement")
+	MigrateCmd.AddCommand(MigrateUpCmd)
+	MigrateCmd.AddCommand(MigrateDownCmd)
+	MigrateCmd.AddCommand(MigrateStatusCmd)
+}
+
+// MigrateCmd is the parent command for versioned schema migrations
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Shows all the migrate commands",
+	Long: `Shows all the migrate commands. Apply, revert and list the numbered .cql migrations of a directory. The migrations applied
+to a keyspace are recorded with their checksum in its astra_schema_migrations table and nothing runs when an applied file was modified`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeMigrate(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeMigrate(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
+
+// migrationSession runs the migrations and is closed once done
+type migrationSession interface {
+	migrate.Session
+	Close() error
+}
+
+// openMigrationSession connects to the keyspace of the database
+var openMigrationSession = func(client pkg.Client, id, keyspace string) (migrationSession, error) {
+	conn, err := connectCQL(client, id, keyspace, migrateTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// migrationTarget is everything the migrate commands need: the migrations found, the keyspace and its tracker
+type migrationTarget struct {
+	migrations []migrate.Migration
+	keyspace   string
+	session    migrationSession
+	tracker    *migrate.Tracker
+	applied    []migrate.Applied
+}
+
+// openMigrationTarget loads the migrations, connects to the keyspace and reads the applied migrations
+func openMigrationTarget(idOrName string, makeClient func() (pkg.Client, error)) (*migrationTarget, error) {
+	migrations, err := migrate.Load(migrateDir)
+	if err != nil {
+		return nil, err
+	}
+	client, err := makeClient()
+	if err != nil {
+		return nil, fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	keyspace := migrateKeyspace
+	if keyspace == "" {
+		keyspace = deref(db.Info.Keyspace)
+	}
+	if keyspace == "" {
+		return nil, fmt.Errorf("database '%s' has no keyspace, pass one with -k", idOrName)
+	}
+	session, err := openMigrationSession(client, db.Id, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	tracker := migrate.NewTracker(session, keyspace)
+	applied, err := tracker.Applied()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	return &migrationTarget{migrations: migrations, keyspace: keyspace, session: session, tracker: tracker, applied: applied}, nil
+}
+
+// runMigrations applies or reverts the migrations in order and stops at the first failure. The table has one row
+// per migration, the ones after a failure are not run
+func runMigrations(migrations []migrate.Migration, done string, run func(m migrate.Migration) error) (string, error) {
+	rows := [][]string{{"version", "name", "result", "duration"}}
+	var failure error
+	for _, m := range migrations {
+		if failure != nil {
+			rows = append(rows, []string{fmt.Sprint(m.Version), m.Name, "not run", ""})
+			continue
+		}
+		start := time.Now()
+		err := run(m)
+		result := done
+		if err != nil {
+			failure = fmt.Errorf("migration %v_%v failed: %v", m.Version, m.Name, err)
+			result = "failed"
+		}
+		rows = append(rows, []string{fmt.Sprint(m.Version), m.Name, result, fmt.Sprintf("%vms", time.Since(start).Milliseconds())})
+	}
+	out, err := writeMigrationRows(rows)
+	if err != nil {
+		return "", err
+	}
+	return out, failure
+}
+
+// listMigrations is the dry run output of up and down
+func listMigrations(migrations []migrate.Migration, file func(m migrate.Migration) string) (string, error) {
+	rows := [][]string{{"version", "name", "file"}}
+	for _, m := range migrations {
+		rows = append(rows, []string{fmt.Sprint(m.Version), m.Name, file(m)})
+	}
+	return writeMigrationRows(rows)
+}
+
+func writeMigrationRows(rows [][]string) (string, error) {
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, rows); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	return out.String(), nil
+}
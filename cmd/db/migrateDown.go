@@ -0,0 +1,75 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/migrate"
+	"github.com/spf13/cobra"
+)
+
+var migrateDownTarget int64
+
+func init() {
+	MigrateDownCmd.Flags().Int64Var(&migrateDownTarget, "target", -1, "version to migrate down to, every newer migration is reverted and 0 reverts all of them. Only the latest by default")
+}
+
+// MigrateDownCmd reverts applied migrations with their down files
+var MigrateDownCmd = &cobra.Command{
+	Use:   "down <id|name>",
+	Short: "reverts applied migrations",
+	Long: `reverts the latest applied migration, or every one newer than --target, newest first by running its .down.cql file and
+removing it from the keyspace. Stops at the first failure. With --dry-run the migrations that would be reverted are listed`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeMigrateDown(args, creds.Login)
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func executeMigrateDown(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	target, err := openMigrationTarget(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	defer target.session.Close()
+	revert, err := migrate.Down(target.migrations, target.applied, migrateDownTarget)
+	if err != nil {
+		return "", err
+	}
+	if len(revert) == 0 {
+		return fmt.Sprintf("nothing to revert in keyspace %v", target.keyspace), nil
+	}
+	if env.DryRun {
+		out, err := listMigrations(revert, func(m migrate.Migration) string { return m.DownFile })
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("dry run, %v migration(s) would be reverted in %v\n%v", len(revert), target.keyspace, out), nil
+	}
+	return runMigrations(revert, "reverted", target.tracker.Revert)
+}
@@ -0,0 +1,84 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg/env"
+)
+
+func TestMigrateDownLatest(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1, 2)
+	out, err := executeMigrateDown([]string{"mydb"}, migrationClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := session.applied[2]; ok || len(session.applied) != 1 {
+		t.Errorf("expected version 2 reverted but was %v", session.applied)
+	}
+	if !strings.Contains(out, "add_name reverted") {
+		t.Errorf("unexpected output\n%v", out)
+	}
+}
+
+func TestMigrateDownAll(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1, 2)
+	migrateDownTarget = 0
+	if _, err := executeMigrateDown([]string{"mydb"}, migrationClient()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(session.applied) != 0 {
+		t.Errorf("expected everything reverted but was %v", session.applied)
+	}
+	if !strings.HasPrefix(session.statements[2], "ALTER TABLE users DROP name") {
+		t.Errorf("expected newest first but was %v", session.statements)
+	}
+}
+
+func TestMigrateDownNoDownFile(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1, 2, 3)
+	_, err := executeMigrateDown([]string{"mydb"}, migrationClient())
+	if err == nil || !strings.Contains(err.Error(), "3_add_index has no .down.cql file") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMigrateDownNothingApplied(t *testing.T) {
+	withMigrations(t, migrationFiles)
+	out, err := executeMigrateDown([]string{"mydb"}, migrationClient())
+	if err != nil || out != "nothing to revert in keyspace ks1" {
+		t.Errorf("unexpected result %q %v", out, err)
+	}
+}
+
+func TestMigrateDownDryRun(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1, 2)
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	out, err := executeMigrateDown([]string{"mydb"}, migrationClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(out, "0002_add_name.down.cql") || len(session.applied) != 2 {
+		t.Errorf("expected only a listing\n%v", out)
+	}
+}
@@ -0,0 +1,74 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/migrate"
+	"github.com/spf13/cobra"
+)
+
+var migrateStatusFmt string
+
+func init() {
+	MigrateStatusCmd.Flags().StringVarP(&migrateStatusFmt, "output", "o", "text", "Output format for report default is text, can also be json")
+}
+
+// MigrateStatusCmd lists the migrations with their state in the keyspace
+var MigrateStatusCmd = &cobra.Command{
+	Use:   "status <id|name>",
+	Short: "lists the migrations and whether they are applied",
+	Long: `lists every migration of --dir and every migration recorded in the keyspace with its state: applied, pending, modified when
+the file changed since it was applied or missing when the file of an applied migration is gone. Changes nothing`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeMigrateStatus(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeMigrateStatus(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	if migrateStatusFmt != pkg.TextFormat && migrateStatusFmt != pkg.JSONFormat {
+		return "", fmt.Errorf("-o %q is not valid option", migrateStatusFmt)
+	}
+	target, err := openMigrationTarget(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	defer target.session.Close()
+	statuses := migrate.Statuses(target.migrations, target.applied)
+	if migrateStatusFmt == pkg.JSONFormat {
+		b, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		return string(b), nil
+	}
+	rows := [][]string{{"version", "name", "state", "applied at"}}
+	for _, s := range statuses {
+		rows = append(rows, []string{fmt.Sprint(s.Version), s.Name, s.State, s.AppliedAt})
+	}
+	return writeMigrationRows(rows)
+}
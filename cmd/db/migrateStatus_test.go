@@ -0,0 +1,68 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/migrate"
+)
+
+func TestMigrateStatus(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1)
+	session.applied[7] = []string{"removed", "abc"}
+	out, err := executeMigrateStatus([]string{"mydb"}, migrationClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{
+		"version name         state   applied at",
+		"1       create_users applied 2022-01-09 09:16:06.016Z",
+		"2       add_name     pending ",
+		"3       add_index    pending ",
+		"7       removed      missing 2022-01-09 09:16:06.016Z",
+	}
+	if out != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%v\nbut was\n%v", strings.Join(expected, "\n"), out)
+	}
+}
+
+func TestMigrateStatusJSON(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1)
+	session.applied[1][1] = "changed"
+	migrateStatusFmt = pkg.JSONFormat
+	out, err := executeMigrateStatus([]string{"mydb"}, migrationClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var statuses []migrate.Status
+	if err := json.Unmarshal([]byte(out), &statuses); err != nil {
+		t.Fatal(err)
+	}
+	var states []string
+	for _, s := range statuses {
+		states = append(states, s.State)
+	}
+	if !reflect.DeepEqual(states, []string{migrate.StateModified, migrate.StatePending, migrate.StatePending}) {
+		t.Errorf("unexpected states %v", states)
+	}
+}
@@ -0,0 +1,79 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/migrate"
+	"github.com/spf13/cobra"
+)
+
+var migrateUpTarget int64
+
+func init() {
+	MigrateUpCmd.Flags().Int64Var(&migrateUpTarget, "target", 0, "version to migrate up to, the latest by default")
+}
+
+// MigrateUpCmd applies the pending migrations
+var MigrateUpCmd = &cobra.Command{
+	Use:   "up <id|name>",
+	Short: "applies the pending migrations",
+	Long: `applies the migrations of --dir that are not recorded in the keyspace yet in version order, up to --target. Stops at the
+first failure, CQL has no transactions so the statements of the failed migration before the failure stay applied. With --dry-run
+the migrations that would be applied are listed`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeMigrateUp(args, creds.Login)
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func executeMigrateUp(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	target, err := openMigrationTarget(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	defer target.session.Close()
+	pending, err := migrate.Up(target.migrations, target.applied, migrateUpTarget)
+	if err != nil {
+		return "", err
+	}
+	if len(pending) == 0 {
+		return fmt.Sprintf("keyspace %v is up to date", target.keyspace), nil
+	}
+	if env.DryRun {
+		out, err := listMigrations(pending, func(m migrate.Migration) string { return m.File })
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("dry run, %v migration(s) would be applied to %v\n%v", len(pending), target.keyspace, out), nil
+	}
+	if err := target.tracker.EnsureTable(); err != nil {
+		return "", err
+	}
+	return runMigrations(pending, "applied", target.tracker.Apply)
+}
@@ -0,0 +1,104 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg/env"
+)
+
+func TestMigrateUp(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1)
+	out, err := executeMigrateUp([]string{"mydb"}, migrationClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(session.applied) != 3 || !session.closed || session.keyspace != "ks1" {
+		t.Errorf("expected every migration applied and the session closed but was %v %v", session.applied, session.closed)
+	}
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "2       add_name  applied") || !strings.HasPrefix(lines[2], "3       add_index applied") {
+		t.Errorf("unexpected output\n%v", out)
+	}
+	out, err = executeMigrateUp([]string{"mydb"}, migrationClient())
+	if err != nil || out != "keyspace ks1 is up to date" {
+		t.Errorf("unexpected second run %q %v", out, err)
+	}
+}
+
+func TestMigrateUpTarget(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	migrateUpTarget = 2
+	if _, err := executeMigrateUp([]string{"mydb"}, migrationClient()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := session.applied[3]; ok || len(session.applied) != 2 {
+		t.Errorf("expected versions 1 and 2 applied but was %v", session.applied)
+	}
+}
+
+func TestMigrateUpStopsOnFailure(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	session.failOn = "ADD name"
+	out, err := executeMigrateUp([]string{"mydb"}, migrationClient())
+	if err == nil || !strings.HasPrefix(err.Error(), "migration 2_add_name failed") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if lines := strings.Split(out, "\n"); !strings.HasSuffix(lines[3], "not run") {
+		t.Errorf("expected the last migration not to run\n%v", out)
+	}
+	if len(session.applied) != 1 {
+		t.Errorf("expected only version 1 recorded but was %v", session.applied)
+	}
+}
+
+func TestMigrateUpRefusesModified(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	applyMigrations(t, session, 1)
+	if err := os.WriteFile(path.Join(migrateDir, "0001_create_users.cql"), []byte("CREATE TABLE users (id text PRIMARY KEY);"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := executeMigrateUp([]string{"mydb"}, migrationClient())
+	if err == nil || !strings.Contains(err.Error(), "1_create_users were modified") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(session.applied) != 1 {
+		t.Errorf("expected nothing applied but was %v", session.applied)
+	}
+}
+
+func TestMigrateUpDryRun(t *testing.T) {
+	session := withMigrations(t, migrationFiles)
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	out, err := executeMigrateUp([]string{"mydb"}, migrationClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(out, "dry run, 3 migration(s) would be applied to ks1") {
+		t.Errorf("unexpected output\n%v", out)
+	}
+	expected := []string{"SELECT table_name FROM system_schema.tables WHERE keyspace_name = 'ks1' AND table_name = 'astra_schema_migrations'"}
+	if !reflect.DeepEqual(session.statements, expected) || session.table {
+		t.Errorf("expected only reads in dry run but was %v", session.statements)
+	}
+}
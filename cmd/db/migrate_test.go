@@ -0,0 +1,165 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	"github.com/datastax-labs/astra-cli/pkg/migrate"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+var (
+	insertMigration = regexp.MustCompile(`^INSERT INTO .*VALUES \((\d+), '([^']*)', '([^']*)'`)
+	deleteMigration = regexp.MustCompile(`^DELETE FROM .* WHERE version = (\d+)$`)
+)
+
+// memorySession keeps the tracking table in memory and fails statements containing failOn
+type memorySession struct {
+	statements []string
+	keyspace   string
+	table      bool
+	applied    map[int64][]string
+	failOn     string
+	closed     bool
+}
+
+func (m *memorySession) Query(statement string) (cql.Result, error) {
+	m.statements = append(m.statements, statement)
+	switch {
+	case m.failOn != "" && strings.Contains(statement, m.failOn):
+		return cql.Result{}, errors.New("invalid query")
+	case strings.Contains(statement, "system_schema.tables"):
+		if m.table {
+			return cql.Result{Kind: "rows", Rows: [][]string{{migrate.Table}}}, nil
+		}
+		return cql.Result{Kind: "rows"}, nil
+	case strings.HasPrefix(statement, "CREATE TABLE IF NOT EXISTS"):
+		m.table = true
+	case strings.HasPrefix(statement, "SELECT version"):
+		var versions []int64
+		for v := range m.applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+		var rows [][]string
+		for _, v := range versions {
+			rows = append(rows, []string{fmt.Sprint(v), m.applied[v][0], m.applied[v][1], "2022-01-09 09:16:06.016Z"})
+		}
+		return cql.Result{Kind: "rows", Rows: rows}, nil
+	}
+	if match := insertMigration.FindStringSubmatch(statement); match != nil {
+		v, _ := strconv.ParseInt(match[1], 10, 64)
+		m.applied[v] = []string{match[2], match[3]}
+	}
+	if match := deleteMigration.FindStringSubmatch(statement); match != nil {
+		v, _ := strconv.ParseInt(match[1], 10, 64)
+		delete(m.applied, v)
+	}
+	return cql.Result{Kind: "void"}, nil
+}
+
+func (m *memorySession) Close() error {
+	m.closed = true
+	return nil
+}
+
+var migrationFiles = map[string]string{
+	"0001_create_users.cql":      "CREATE TABLE users (id int PRIMARY KEY);",
+	"0001_create_users.down.cql": "DROP TABLE users;",
+	"0002_add_name.cql":          "ALTER TABLE users ADD name text;",
+	"0002_add_name.down.cql":     "ALTER TABLE users DROP name;",
+	"0003_add_index.cql":         "CREATE INDEX users_name ON users (name);",
+}
+
+// withMigrations writes the migration files and connects the commands to the returned session
+func withMigrations(t *testing.T, files map[string]string) *memorySession {
+	// setting package variables by hand, there be dragons
+	migrateDir = t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(path.Join(migrateDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	session := &memorySession{applied: make(map[int64][]string)}
+	original := openMigrationSession
+	openMigrationSession = func(client pkg.Client, id, keyspace string) (migrationSession, error) {
+		session.keyspace = keyspace
+		session.closed = false
+		return session, nil
+	}
+	t.Cleanup(func() {
+		migrateDir = "migrations"
+		migrateKeyspace = ""
+		migrateUpTarget = 0
+		migrateDownTarget = -1
+		migrateStatusFmt = pkg.TextFormat
+		openMigrationSession = original
+	})
+	return session
+}
+
+// applyMigrations records the versions as applied with the checksum of their file
+func applyMigrations(t *testing.T, session *memorySession, versions ...int64) {
+	migrations, err := migrate.Load(migrateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.table = true
+	for _, m := range migrations {
+		for _, v := range versions {
+			if m.Version == v {
+				session.applied[v] = []string{m.Name, m.Checksum}
+			}
+		}
+	}
+}
+
+func migrationClient() func() (pkg.Client, error) {
+	return func() (pkg.Client, error) {
+		return &tests.MockClient{Databases: []astraops.Database{cqlshDb()}}, nil
+	}
+}
+
+func TestMigrationTargetNoKeyspace(t *testing.T) {
+	withMigrations(t, migrationFiles)
+	name := "mydb"
+	_, err := openMigrationTarget("mydb", func() (pkg.Client, error) {
+		return &tests.MockClient{Databases: []astraops.Database{{Id: cqlshDbID, Info: astraops.DatabaseInfo{Name: &name}}}}, nil
+	})
+	if err == nil || err.Error() != "database 'mydb' has no keyspace, pass one with -k" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMigrationTargetMissingDir(t *testing.T) {
+	withMigrations(t, nil)
+	migrateDir = path.Join(migrateDir, "missing")
+	if _, err := openMigrationTarget("mydb", migrationClient()); err == nil || !strings.HasPrefix(err.Error(), "unable to read migrations") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
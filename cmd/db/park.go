@@ -20,37 +20,63 @@ import (
 	"os"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
 	"github.com/spf13/cobra"
 )
 
+func init() {
+	AddOverrideProtectionFlag(ParkCmd)
+	addBulkFlags(ParkCmd)
+}
+
 // ParkCmd provides parking support for classic database tiers in Astra
 var ParkCmd = &cobra.Command{
-	Use:   "park <id>",
-	Short: "parks the database specified, does not work with serverless",
-	Long:  `parks the database specified, only works on classic tier databases and can take a very long time to park (20-30 minutes)`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "park <id>...",
+	Short: "parks the databases specified, does not work with serverless",
+	Long:  `parks the databases specified by ID or by --selector, only works on classic tier databases and can take a very long time to park (20-30 minutes)`,
+	Args:  bulkArgs(0),
 	Run: func(cobraCmd *cobra.Command, args []string) {
 		creds := &pkg.Creds{}
 		msg, err := executePark(args, creds.Login)
+		if msg != "" {
+			fmt.Println(msg)
+		}
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-		fmt.Println(msg)
 	},
 }
 
-// executePark parks the database with the specified ID. If no ID is provided
+// executePark parks the database with the specified ID, several ids or a selector go through executeBulk. If no ID is provided
 // the command will error out
 func executePark(args []string, makeClient func() (pkg.Client, error)) (string, error) {
 	client, err := makeClient()
 	if err != nil {
 		return "", fmt.Errorf("unable to login with error %v", err)
 	}
+	if isBulk(args) {
+		return executeBulk(client, args, bulkOperation{
+			name: "park",
+			done: "parked",
+			run: func(client pkg.Client, db astraops.Database) error {
+				return client.Park(db.Id)
+			},
+		})
+	}
 	id := args[0]
-	fmt.Printf("starting to park database %v\n", id)
+	if err := guard(client, id, "park"); err != nil {
+		return "", err
+	}
+	if !env.DryRun {
+		fmt.Printf("starting to park database %v\n", id)
+	}
 	if err := client.Park(id); err != nil {
 		return "", fmt.Errorf("unable to park '%s' with error %v", id, err)
 	}
+	if env.DryRun {
+		return fmt.Sprintf("dry run, would park database %v", id), nil
+	}
 	return fmt.Sprintf("database %v parked", id), nil
 }
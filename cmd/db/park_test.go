@@ -20,10 +20,12 @@ import (
 	"testing"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
 	tests "github.com/datastax-labs/astra-cli/pkg/tests"
 )
 
 func TestPark(t *testing.T) {
+	withPolicy(t, "")
 	// setting package variables by hand, there be dragons
 	mockClient := &tests.MockClient{}
 	id := "abcd"
@@ -67,6 +69,7 @@ func TestParkFailedLogin(t *testing.T) {
 }
 
 func TestParkFailed(t *testing.T) {
+	withPolicy(t, "")
 	// setting package variables by hand, there be dragons
 	mockClient := &tests.MockClient{}
 	mockClient.ErrorQueue = []error{errors.New("unable to park")}
@@ -86,3 +89,19 @@ func TestParkFailed(t *testing.T) {
 		t.Errorf("expected '%v' but was '%v'", expected, msg)
 	}
 }
+
+func TestParkDryRun(t *testing.T) {
+	withPolicy(t, "")
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	msg, err := executePark([]string{"parkID123"}, func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	expected := "dry run, would park database parkID123"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
@@ -0,0 +1,272 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+// defaultPingTimeout bounds each check, a healthy database answers every one well under it
+const defaultPingTimeout = 10 * time.Second
+
+var pingFmt string
+var pingTimeout time.Duration
+
+func init() {
+	PingCmd.Flags().StringVarP(&pingFmt, "output", "o", "text", "Output format for report default is text, can also be json")
+	PingCmd.Flags().DurationVar(&pingTimeout, "timeout", defaultPingTimeout, "timeout of each check")
+}
+
+// lookupHost resolves the host of the CQL endpoint
+var lookupHost = func(host string, timeout time.Duration) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+// PingCmd checks the database can be reached the way applications reach it
+var PingCmd = &cobra.Command{
+	Use:   "ping <id|name>",
+	Short: "checks the CQL, REST and GraphQL endpoints of the database can be reached",
+	Long: `an ACTIVE database can still be out of reach of applications. ping resolves the CQL endpoint of the secure bundle, asks
+its metadata service for the nodes and completes a TLS handshake with the proxy using the certificates of the bundle, then calls the
+REST and GraphQL APIs. Every check is reported with how long it took, the checks that need a failed one are skipped, and the exit
+code is 1 when any check failed. Without a token an API answering 401 counts as reachable`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executePing(args, creds.Login)
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// pingCheck is the report of one check
+type pingCheck struct {
+	Check     string `json:"check"`
+	Target    string `json:"target"`
+	Outcome   string `json:"outcome"`
+	Detail    string `json:"detail,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+// check outcomes
+const (
+	pingPass = "pass"
+	pingFail = "fail"
+	pingSkip = "skip"
+)
+
+// pingStep is a check to run, target is only read once the steps before it passed
+type pingStep struct {
+	check  string
+	target func() string
+	run    func() (string, error)
+}
+
+func executePing(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	if pingFmt != pkg.TextFormat && pingFmt != pkg.JSONFormat {
+		return "", fmt.Errorf("-o %q is not valid option", pingFmt)
+	}
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, args[0])
+	if err != nil {
+		return "", err
+	}
+	token, _, err := storedCreds()
+	if err != nil {
+		return "", fmt.Errorf("unable to read credentials with error %v", err)
+	}
+	checks := runChecks(cqlSteps(client, db.Id))
+	for _, step := range apiSteps(db, token) {
+		checks = append(checks, runChecks([]pingStep{step})...)
+	}
+	out, err := writePingChecks(checks)
+	if err != nil {
+		return "", err
+	}
+	var failed int
+	for _, c := range checks {
+		if c.Outcome == pingFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return out, fmt.Errorf("%v of %v checks failed", failed, len(checks))
+	}
+	return out, nil
+}
+
+// cqlSteps follow DialBundle: the bundle, the host of its metadata service, the contact points and the proxy
+func cqlSteps(client pkg.Client, id string) []pingStep {
+	var b bundle.Bundle
+	var info cql.ContactInfo
+	return []pingStep{
+		{
+			check:  "bundle",
+			target: func() string { return id },
+			run: func() (string, error) {
+				bundlePath, err := cachedBundle(client, id, time.Now())
+				if err != nil {
+					return "", err
+				}
+				b, err = bundle.Open(bundlePath)
+				return bundlePath, err
+			},
+		},
+		{
+			check:  "dns",
+			target: func() string { return b.Config.Host },
+			run: func() (string, error) {
+				addrs, err := lookupHost(b.Config.Host, pingTimeout)
+				if err != nil {
+					return "", fmt.Errorf("unable to resolve '%v' with error %v", b.Config.Host, err)
+				}
+				return strings.Join(addrs, ", "), nil
+			},
+		},
+		{
+			check:  "metadata",
+			target: func() string { return cql.MetadataURL(b) },
+			run: func() (string, error) {
+				tlsConfig, err := b.TLSConfig()
+				if err != nil {
+					return "", err
+				}
+				info, err = cql.FetchContactInfo(cql.MetadataClient(tlsConfig, pingTimeout), cql.MetadataURL(b))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%v contact point(s) in %v", len(info.ContactPoints), info.LocalDC), nil
+			},
+		},
+		{
+			check:  "tls",
+			target: func() string { return info.SNIProxyAddress },
+			run: func() (string, error) {
+				tlsConfig, err := b.TLSConfig()
+				if err != nil {
+					return "", err
+				}
+				return "", cql.Handshake(info, tlsConfig, pingTimeout)
+			},
+		},
+	}
+}
+
+// apiSteps call the REST and GraphQL APIs, they do not depend on each other
+func apiSteps(db astraops.Database, token string) []pingStep {
+	baseURL, baseErr := stargate.BaseURL(db, pkg.Env)
+	api := stargate.NewClient(baseURL, token)
+	api.SetTimeout(pingTimeout)
+	call := func(check func() error) func() (string, error) {
+		return func() (string, error) {
+			if baseErr != nil {
+				return "", baseErr
+			}
+			err := check()
+			var apiErr *stargate.Error
+			if token == "" && errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+				return "reachable, 401 without a token", nil
+			}
+			return "", err
+		}
+	}
+	return []pingStep{
+		{
+			check:  "rest",
+			target: func() string { return api.URL(stargate.RESTHealthPath) },
+			run:    call(api.CheckREST),
+		},
+		{
+			check:  "graphql",
+			target: func() string { return api.URL(stargate.GraphQLSchemaPath) },
+			run:    call(api.CheckGraphQL),
+		},
+	}
+}
+
+// runChecks runs the steps in order and times them, the steps after a failed one are skipped as they need its result
+func runChecks(steps []pingStep) []pingCheck {
+	var checks []pingCheck
+	var failed string
+	for _, s := range steps {
+		if failed != "" {
+			checks = append(checks, pingCheck{Check: s.check, Outcome: pingSkip, Detail: failed + " failed"})
+			continue
+		}
+		c := pingCheck{Check: s.check, Target: s.target(), Outcome: pingPass}
+		start := time.Now()
+		detail, err := s.run()
+		c.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			c.Outcome = pingFail
+			detail = err.Error()
+			failed = s.check
+		}
+		c.Detail = detail
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// writePingChecks prints one row per check
+func writePingChecks(checks []pingCheck) (string, error) {
+	if pingFmt == pkg.JSONFormat {
+		b, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		return string(b), nil
+	}
+	rows := [][]string{{"check", "target", "result", "latency", "detail"}}
+	for _, c := range checks {
+		latency := fmt.Sprintf("%vms", c.LatencyMs)
+		if c.Outcome == pingSkip {
+			latency = ""
+		}
+		rows = append(rows, []string{c.Check, c.Target, c.Outcome, latency, c.Detail})
+	}
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, rows); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,237 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// withPing serves a bundle for 127.0.0.1 with its metadata service and proxy, and the APIs answering apiStatus
+func withPing(t *testing.T, token string, apiStatus int) *tests.MockClient {
+	// setting package variables by hand, there be dragons
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipContent, serverTLS, err := tests.ServerBundle(tests.BundleOptions{Host: "127.0.0.1", Port: l.Addr().(*net.TCPAddr).Port, ServerNames: []string{cqlshDbID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy, err := tests.NewCQLServer("token", token, serverTLS, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := proxy.Close(); err != nil {
+			t.Logf("unable to close server %v", err)
+		}
+	})
+	metadata := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version":1,"contact_info":{"type":"sni_proxy","local_dc":"dc-1","contact_points":["%v"],"sni_proxy_address":"%v"}}`, cqlshDbID, proxy.Addr())
+	}))
+	metadata.Listener.Close()
+	metadata.Listener = l
+	metadata.TLS = serverTLS
+	metadata.StartTLS()
+	t.Cleanup(metadata.Close)
+	download := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		if _, err := w.Write(zipContent); err != nil {
+			t.Logf("unable to write zip %v", err)
+		}
+	}))
+	t.Cleanup(download.Close)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := `{"data":{"keyspaces":[{"name":"ks1"}]}}`
+		if apiStatus == http.StatusUnauthorized {
+			response = `{"description":"Role unauthorized for operation","code":401}`
+		}
+		w.WriteHeader(apiStatus)
+		if _, err := io.WriteString(w, response); err != nil {
+			t.Logf("unable to write response %v", err)
+		}
+	}))
+	t.Cleanup(api.Close)
+	db := cqlshDb()
+	db.DataEndpointUrl = astraops.StringPtr(api.URL + "/api/rest")
+	dir := t.TempDir()
+	originalCache := bundleCacheDir
+	bundleCacheDir = func() (string, error) {
+		return path.Join(dir, "bundles"), nil
+	}
+	originalCreds := storedCreds
+	storedCreds = func() (string, pkg.ClientInfo, error) {
+		return token, pkg.ClientInfo{}, nil
+	}
+	pingFmt = pkg.JSONFormat
+	pingTimeout = 5 * time.Second
+	t.Cleanup(func() {
+		bundleCacheDir = originalCache
+		storedCreds = originalCreds
+		pingFmt = pkg.TextFormat
+		pingTimeout = defaultPingTimeout
+	})
+	return &tests.MockClient{Bundle: astraops.CredsURL{DownloadURL: download.URL}, Databases: []astraops.Database{db}}
+}
+
+func runPing(t *testing.T, mockClient *tests.MockClient) ([]pingCheck, error) {
+	out, err := executePing([]string{cqlshDbID}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	var checks []pingCheck
+	if jsonErr := json.Unmarshal([]byte(out), &checks); jsonErr != nil {
+		t.Fatalf("unable to parse '%v' with error %v", out, jsonErr)
+	}
+	return checks, err
+}
+
+func outcomes(checks []pingCheck) string {
+	var s []string
+	for _, c := range checks {
+		s = append(s, c.Check+"="+c.Outcome)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestPing(t *testing.T) {
+	mockClient := withPing(t, "AstraCS:secret", http.StatusOK)
+	checks, err := runPing(t, mockClient)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "bundle=pass dns=pass metadata=pass tls=pass rest=pass graphql=pass"
+	if outcomes(checks) != expected {
+		t.Fatalf("expected %v but was %v", expected, checks)
+	}
+	if checks[1].Target != "127.0.0.1" || checks[1].Detail != "127.0.0.1" {
+		t.Errorf("unexpected dns check %+v", checks[1])
+	}
+	if checks[2].Detail != "1 contact point(s) in dc-1" {
+		t.Errorf("unexpected metadata check %+v", checks[2])
+	}
+	if !strings.HasSuffix(checks[4].Target, "/api/rest/v2/schemas/keyspaces") || !strings.HasSuffix(checks[5].Target, "/api/graphql-schema") {
+		t.Errorf("unexpected api targets %v and %v", checks[4].Target, checks[5].Target)
+	}
+}
+
+func TestPingText(t *testing.T) {
+	mockClient := withPing(t, "AstraCS:secret", http.StatusOK)
+	pingFmt = pkg.TextFormat
+	out, err := executePing([]string{cqlshDbID}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lines := strings.Split(out, "\n")
+	if len(lines) != 7 || !strings.HasPrefix(lines[0], "check") || !strings.HasPrefix(lines[4], "tls") {
+		t.Fatalf("unexpected output\n%v", out)
+	}
+	for _, line := range lines[1:] {
+		if !strings.Contains(line, " pass ") || !strings.Contains(line, "ms") {
+			t.Errorf("expected a passed check with its latency but was '%v'", line)
+		}
+	}
+}
+
+func TestPingDNSFailure(t *testing.T) {
+	mockClient := withPing(t, "AstraCS:secret", http.StatusOK)
+	originalLookup := lookupHost
+	lookupHost = func(host string, timeout time.Duration) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	t.Cleanup(func() {
+		lookupHost = originalLookup
+	})
+	checks, err := runPing(t, mockClient)
+	if err == nil || err.Error() != "1 of 6 checks failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	expected := "bundle=pass dns=fail metadata=skip tls=skip rest=pass graphql=pass"
+	if outcomes(checks) != expected {
+		t.Fatalf("expected %v but was %v", expected, checks)
+	}
+	if checks[1].Detail != "unable to resolve '127.0.0.1' with error no such host" || checks[2].Detail != "dns failed" {
+		t.Errorf("unexpected details %+v", checks)
+	}
+}
+
+func TestPingNoToken(t *testing.T) {
+	mockClient := withPing(t, "", http.StatusUnauthorized)
+	checks, err := runPing(t, mockClient)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if outcomes(checks) != "bundle=pass dns=pass metadata=pass tls=pass rest=pass graphql=pass" {
+		t.Fatalf("unexpected checks %v", checks)
+	}
+	if checks[4].Detail != "reachable, 401 without a token" {
+		t.Errorf("unexpected detail %v", checks[4].Detail)
+	}
+}
+
+func TestPingTokenRejected(t *testing.T) {
+	mockClient := withPing(t, "AstraCS:secret", http.StatusUnauthorized)
+	checks, err := runPing(t, mockClient)
+	if err == nil || err.Error() != "2 of 6 checks failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if outcomes(checks) != "bundle=pass dns=pass metadata=pass tls=pass rest=fail graphql=fail" {
+		t.Fatalf("unexpected checks %v", checks)
+	}
+	if !strings.Contains(checks[4].Detail, "Role unauthorized for operation (status 401)") {
+		t.Errorf("unexpected detail %v", checks[4].Detail)
+	}
+}
+
+func TestPingBundleFailure(t *testing.T) {
+	mockClient := withPing(t, "AstraCS:secret", http.StatusOK)
+	mockClient.ErrorQueue = []error{nil, errors.New("no bundle")}
+	checks, err := runPing(t, mockClient)
+	if err == nil || err.Error() != "1 of 6 checks failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if outcomes(checks) != "bundle=fail dns=skip metadata=skip tls=skip rest=pass graphql=pass" {
+		t.Fatalf("unexpected checks %v", checks)
+	}
+}
+
+func TestPingInvalidFormat(t *testing.T) {
+	pingFmt = "yaml"
+	t.Cleanup(func() {
+		pingFmt = pkg.TextFormat
+	})
+	_, err := executePing([]string{cqlshDbID}, func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err == nil || err.Error() != `-o "yaml" is not valid option` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
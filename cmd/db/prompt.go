@@ -0,0 +1,106 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stdin is where interactive answers are read from, tests replace it
+var stdin io.Reader = os.Stdin
+
+// isTerminal reports if stdin is attached to a terminal so a person can answer prompts, tests replace it
+var isTerminal = func() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+const maxPromptTries = 3
+
+// prompt asks for a value until a non empty one is given, the default is used when the answer is empty
+func prompt(reader *bufio.Reader, out io.Writer, label, def string) (string, error) {
+	for tries := 0; tries < maxPromptTries; tries++ {
+		if def != "" {
+			fmt.Fprintf(out, "%v [%v]: ", label, def)
+		} else {
+			fmt.Fprintf(out, "%v: ", label)
+		}
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if err != nil && (err != io.EOF || answer == "") {
+			return "", fmt.Errorf("error reading input %v", err)
+		}
+		if answer == "" {
+			answer = def
+		}
+		if answer != "" {
+			return answer, nil
+		}
+		fmt.Fprintf(out, "%v cannot be empty try again\n", label)
+	}
+	return "", fmt.Errorf("no value entered for %v", label)
+}
+
+// choose asks to pick one of the options either by its number or by its value
+func choose(reader *bufio.Reader, out io.Writer, label string, options []string, def string) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("there are no options available for %v", label)
+	}
+	if !contains(options, def) {
+		def = options[0]
+	}
+	for tries := 0; tries < maxPromptTries; tries++ {
+		answer, err := prompt(reader, out, label, def)
+		if err != nil {
+			return "", err
+		}
+		if i, err := strconv.Atoi(answer); err == nil && i > 0 && i <= len(options) {
+			return options[i-1], nil
+		}
+		for _, o := range options {
+			if strings.EqualFold(o, answer) {
+				return o, nil
+			}
+		}
+		fmt.Fprintf(out, "'%v' is not one of the options try again\n", answer)
+	}
+	return "", fmt.Errorf("no valid option entered for %v", label)
+}
+
+// confirm asks a yes or no question, anything but y or yes is a no
+func confirm(reader *bufio.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%v [y/N]: ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("error reading input %v", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/audit"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/protect"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+// overrideProtection lets delete, park and resize run against a database protected by the policy
+var overrideProtection bool
+
+// loadPolicy returns the protection policy and the file overrides are recorded in
+var loadPolicy = func() (protect.Policy, string, error) {
+	confDir, _, err := pkg.GetHome(os.UserHomeDir)
+	if err != nil {
+		return protect.Policy{}, "", err
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		workDir = ""
+	}
+	policy, err := protect.Load(confDir, workDir)
+	if err != nil {
+		return protect.Policy{}, "", err
+	}
+	return policy, path.Join(confDir, protect.OverrideLogName), nil
+}
+
+// AddOverrideProtectionFlag adds --override-protection to a command that checks the protection policy
+func AddOverrideProtectionFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&overrideProtection, "override-protection", false, "run even if the database is protected by the protection policy, the override is recorded")
+}
+
+// guard checks the protection policy before the operation, the database is only looked up by id or name when
+// the current environment protects something
+func guard(client pkg.Client, idOrName, operation string) error {
+	policy, logFile, err := loadPolicy()
+	if err != nil {
+		return fmt.Errorf("unable to load protection policy with error %v", err)
+	}
+	if policy.Empty(pkg.Env) {
+		return nil
+	}
+	db, err := pkg.ResolveDb(client, idOrName)
+	if err != nil {
+		return fmt.Errorf("unable to check protection of '%s' with error %v", idOrName, err)
+	}
+	return checkPolicy(policy, logFile, db, operation)
+}
+
+// GuardDb checks the protection policy for a database already looked up, apply uses it before --prune removes anything
+func GuardDb(db astraops.Database, operation string) error {
+	policy, logFile, err := loadPolicy()
+	if err != nil {
+		return fmt.Errorf("unable to load protection policy with error %v", err)
+	}
+	return checkPolicy(policy, logFile, db, operation)
+}
+
+func checkPolicy(policy protect.Policy, logFile string, db astraops.Database, operation string) error {
+	rule, protected := policy.Match(pkg.Env, db)
+	if !protected {
+		return nil
+	}
+	var name string
+	if db.Info.Name != nil {
+		name = *db.Info.Name
+	}
+	if !overrideProtection {
+		return fmt.Errorf("database '%v' is protected by %v in env %v, refusing to %v it, pass --override-protection to %v anyway", db.Id, rule, pkg.Env, operation, operation)
+	}
+	override := protect.Override{
+		Time:      time.Now(),
+		User:      audit.CurrentUser(),
+		Env:       pkg.Env,
+		Operation: operation,
+		ID:        db.Id,
+		Name:      name,
+		Rule:      rule,
+	}
+	if env.DryRun {
+		fmt.Fprintf(os.Stderr, "dry run: would record protection override of '%v' by %v in %v\n", db.Id, override.User, logFile)
+		return nil
+	}
+	if err := protect.Record(logFile, override); err != nil {
+		return fmt.Errorf("unable to record protection override, not continuing, with error %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "warning: database '%v' is protected by %v, override by %v recorded in %v\n", db.Id, rule, override.User, logFile)
+	return nil
+}
@@ -0,0 +1,166 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/protect"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// withPolicy replaces the protection policy for the test and returns the override log file
+func withPolicy(t *testing.T, yaml string) string {
+	policy, err := protect.Parse([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logFile := path.Join(t.TempDir(), protect.OverrideLogName)
+	original := loadPolicy
+	loadPolicy = func() (protect.Policy, string, error) {
+		return policy, logFile, nil
+	}
+	t.Cleanup(func() {
+		loadPolicy = original
+		overrideProtection = false
+	})
+	return logFile
+}
+
+const prodPolicy = `
+environments:
+  prod:
+    names: ["prod-*"]
+`
+
+func protectedDb() astraops.Database {
+	return astraops.Database{Id: "abcd", Info: astraops.DatabaseInfo{Name: astraops.StringPtr("prod-users")}}
+}
+
+func TestParkProtected(t *testing.T) {
+	withPolicy(t, prodPolicy)
+	mockClient := &tests.MockClient{Databases: []astraops.Database{protectedDb()}}
+	_, err := executePark([]string{"abcd"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "is protected by name prod-*") {
+		t.Fatalf("expected protection error but was '%v'", err)
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected only the lookup call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestParkProtectedByName(t *testing.T) {
+	withPolicy(t, prodPolicy)
+	env.DryRun = true
+	t.Cleanup(func() { env.DryRun = false })
+	mockClient := &tests.MockClient{
+		// the name is looked up as an id first, that lookup consumes the first database
+		Databases:  []astraops.Database{{}, protectedDb()},
+		ErrorQueue: []error{errors.New("not found")},
+	}
+	_, err := executePark([]string{"prod-users"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "database 'abcd' is protected by name prod-*") {
+		t.Fatalf("expected protection error but was '%v'", err)
+	}
+}
+
+func TestParkByNameNotProtected(t *testing.T) {
+	withPolicy(t, prodPolicy)
+	env.DryRun = true
+	t.Cleanup(func() { env.DryRun = false })
+	mockClient := &tests.MockClient{
+		Databases:  []astraops.Database{{}, namedDb("efgh", "dev-users")},
+		ErrorQueue: []error{errors.New("not found")},
+	}
+	msg, err := executePark([]string{"dev-users"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if msg != "dry run, would park database dev-users" {
+		t.Errorf("unexpected message '%v'", msg)
+	}
+}
+
+func TestResizeProtectedOverride(t *testing.T) {
+	logFile := withPolicy(t, prodPolicy)
+	overrideProtection = true
+	mockClient := &tests.MockClient{Databases: []astraops.Database{protectedDb()}}
+	err := executeResize([]string{"abcd", "2"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 2 {
+		t.Errorf("expected lookup and resize calls but was %v", len(mockClient.Calls()))
+	}
+	b, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("expected the override to be recorded but was '%v'", err)
+	}
+	if !strings.Contains(string(b), "operation=resize id=abcd name=prod-users") {
+		t.Errorf("unexpected override log '%v'", string(b))
+	}
+}
+
+func TestDeleteProtected(t *testing.T) {
+	withPolicy(t, prodPolicy)
+	deleteYes = true
+	defer func() {
+		deleteYes = false
+	}()
+	mockClient := &tests.MockClient{Databases: []astraops.Database{protectedDb()}}
+	_, err := executeDelete([]string{"abcd"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected protection error")
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected only the lookup call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestParkNotProtectedInOtherEnv(t *testing.T) {
+	withPolicy(t, `
+environments:
+  dev:
+    names: ["prod-*"]
+`)
+	mockClient := &tests.MockClient{}
+	_, err := executePark([]string{"abcd"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected no lookup when nothing is protected but was %v calls", len(mockClient.Calls()))
+	}
+}
@@ -0,0 +1,48 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RegionCmd.AddCommand(RegionAddCmd)
+	RegionCmd.AddCommand(RegionListCmd)
+	RegionCmd.AddCommand(RegionRemoveCmd)
+}
+
+// RegionCmd is the parent command for managing the datacenters of a multi-region database
+var RegionCmd = &cobra.Command{
+	Use:   "region",
+	Short: "Shows all the region commands",
+	Long:  `Shows all the region commands. Add, list and remove the datacenters (regions) of a database`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeRegion(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeRegion(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
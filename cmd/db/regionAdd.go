@@ -0,0 +1,95 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+var regionAddRegion string
+var regionAddTier string
+var regionAddCloudProvider string
+var regionAddCapacityUnits int
+
+func init() {
+	RegionAddCmd.Flags().StringVarP(&regionAddRegion, "region", "r", "", "region to add to the Astra Database")
+	RegionAddCmd.Flags().StringVarP(&regionAddTier, "tier", "t", "", "tier of the new datacenter, defaults to the tier of the database")
+	RegionAddCmd.Flags().StringVarP(&regionAddCloudProvider, "cloudProvider", "l", "", "cloud provider of the new datacenter, defaults to the cloud provider of the database")
+	RegionAddCmd.Flags().IntVarP(&regionAddCapacityUnits, "capacityUnits", "c", 1, "capacity units of the new datacenter")
+}
+
+// RegionAddCmd adds a datacenter in a new region to a database
+var RegionAddCmd = &cobra.Command{
+	Use:   "add <id>",
+	Short: "adds a region to the database",
+	Long:  `adds a datacenter in the specified region to the database and waits until the new datacenter is active`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executeRegionAdd(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(msg)
+	},
+}
+
+func executeRegionAdd(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	if regionAddRegion == "" {
+		return "", &pkg.ParseError{
+			Args: args,
+			Err:  fmt.Errorf("--region is required"),
+		}
+	}
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	id := args[0]
+	db, err := client.FindDb(id)
+	if err != nil {
+		return "", fmt.Errorf("unable to get '%s' with error %v", id, err)
+	}
+	dc := astraops.Datacenter{
+		Region:        regionAddRegion,
+		CapacityUnits: &regionAddCapacityUnits,
+		Tier:          astraops.Tier(regionAddTier),
+		CloudProvider: astraops.CloudProvider(regionAddCloudProvider),
+	}
+	if dc.Tier == "" && db.Info.Tier != nil {
+		dc.Tier = *db.Info.Tier
+	}
+	if dc.CloudProvider == "" && db.Info.CloudProvider != nil {
+		dc.CloudProvider = *db.Info.CloudProvider
+	}
+	if !env.DryRun {
+		fmt.Printf("starting to add region %v to database %v\n", regionAddRegion, id)
+	}
+	if err := client.AddDatacenters(id, []astraops.Datacenter{dc}); err != nil {
+		return "", fmt.Errorf("unable to add region '%s' to '%s' with error %v", regionAddRegion, id, err)
+	}
+	if env.DryRun {
+		return fmt.Sprintf("dry run, would add region %v to database %v", regionAddRegion, id), nil
+	}
+	return fmt.Sprintf("region %v added to database %v", regionAddRegion, id), nil
+}
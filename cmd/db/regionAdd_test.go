@@ -0,0 +1,120 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestRegionAdd(t *testing.T) {
+	// setting package variables by hand, there be dragons
+	regionAddRegion = "us-west1"
+	regionAddTier = ""
+	regionAddCloudProvider = ""
+	regionAddCapacityUnits = 1
+	tier := astraops.Tier("serverless")
+	cloud := astraops.CloudProvider("GCP")
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{
+			{Id: "abc", Info: astraops.DatabaseInfo{Tier: &tier, CloudProvider: &cloud}},
+		},
+	}
+	msg, err := executeRegionAdd([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if len(mockClient.Calls()) != 2 {
+		t.Fatalf("expected 2 calls but was %v", len(mockClient.Calls()))
+	}
+	addCall := mockClient.Call(1).([]interface{})
+	if addCall[0] != "abc" {
+		t.Errorf("expected '%v' but was '%v'", "abc", addCall[0])
+	}
+	dcs := addCall[1].([]astraops.Datacenter)
+	if len(dcs) != 1 {
+		t.Fatalf("expected 1 datacenter but was %v", len(dcs))
+	}
+	if dcs[0].Region != "us-west1" {
+		t.Errorf("expected '%v' but was '%v'", "us-west1", dcs[0].Region)
+	}
+	if dcs[0].Tier != tier {
+		t.Errorf("expected '%v' but was '%v'", tier, dcs[0].Tier)
+	}
+	if dcs[0].CloudProvider != cloud {
+		t.Errorf("expected '%v' but was '%v'", cloud, dcs[0].CloudProvider)
+	}
+	expected := "region us-west1 added to database abc"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
+
+func TestRegionAddMissingRegion(t *testing.T) {
+	regionAddRegion = ""
+	mockClient := &tests.MockClient{}
+	_, err := executeRegionAdd([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(mockClient.Calls()) != 0 {
+		t.Errorf("expected no calls but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestRegionAddFails(t *testing.T) {
+	regionAddRegion = "us-west1"
+	mockClient := &tests.MockClient{
+		Databases:  []astraops.Database{{Id: "abc"}},
+		ErrorQueue: []error{nil, errors.New("quota exceeded")},
+	}
+	_, err := executeRegionAdd([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expected := "unable to add region 'us-west1' to 'abc' with error quota exceeded"
+	if err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestRegionAddDryRun(t *testing.T) {
+	// setting package variables by hand, there be dragons
+	regionAddRegion = "us-west1"
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	msg, err := executeRegionAdd([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{Databases: []astraops.Database{{Id: "abc"}}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	expected := "dry run, would add region us-west1 to database abc"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
@@ -0,0 +1,94 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"github.com/spf13/cobra"
+)
+
+var regionListFmt string
+var regionListAll bool
+
+func init() {
+	RegionListCmd.Flags().StringVarP(&regionListFmt, "output", "o", "text", "Output format for report default is text")
+	RegionListCmd.Flags().BoolVarP(&regionListAll, "all", "a", false, "include terminated datacenters")
+}
+
+// RegionListCmd lists the datacenters of a database with the status of each one
+var RegionListCmd = &cobra.Command{
+	Use:   "list <id>",
+	Short: "lists the regions of the database",
+	Long:  `lists every datacenter of the database with its region and status`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executeRegionList(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(msg)
+	},
+}
+
+func executeRegionList(args []string, login func() (pkg.Client, error)) (string, error) {
+	client, err := login()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	id := args[0]
+	var dcs []astraops.Datacenter
+	if dcs, err = client.ListDatacenters(id, regionListAll); err != nil {
+		return "", fmt.Errorf("unable to list regions of '%s' with error %v", id, err)
+	}
+	switch regionListFmt {
+	case pkg.TextFormat:
+		var rows [][]string
+		rows = append(rows, []string{"id", "region", "cloud", "tier", "capacity units", "status"})
+		for _, dc := range dcs {
+			var dcID string
+			if dc.Id != nil {
+				dcID = *dc.Id
+			}
+			var capacityUnits string
+			if dc.CapacityUnits != nil {
+				capacityUnits = fmt.Sprintf("%v", *dc.CapacityUnits)
+			}
+			rows = append(rows, []string{dcID, dc.Region, string(dc.CloudProvider), string(dc.Tier), capacityUnits, dc.Status})
+		}
+		var buf bytes.Buffer
+		err = pkg.WriteRows(&buf, rows)
+		if err != nil {
+			return "", fmt.Errorf("unexpected error writing out text %v", err)
+		}
+		return buf.String(), nil
+	case pkg.JSONFormat:
+		b, err := json.MarshalIndent(dcs, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("-o %q is not valid option", regionListFmt)
+	}
+}
@@ -0,0 +1,67 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestRegionList(t *testing.T) {
+	regionListFmt = pkg.TextFormat
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Id: astraops.StringPtr("abc-1"), Region: "us-east1", CloudProvider: "GCP", Tier: "serverless", Status: "ACTIVE"},
+			{Id: astraops.StringPtr("abc-2"), Region: "us-west1", CloudProvider: "GCP", Tier: "serverless", Status: "INITIALIZING"},
+		},
+	}
+	msg, err := executeRegionList([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	lines := strings.Split(msg, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but was %v", len(lines))
+	}
+	expected := "abc-2 us-west1 GCP   serverless                INITIALIZING"
+	if lines[2] != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, lines[2])
+	}
+}
+
+func TestRegionListFails(t *testing.T) {
+	regionListFmt = pkg.TextFormat
+	mockClient := &tests.MockClient{
+		ErrorQueue: []error{errors.New("no db")},
+	}
+	_, err := executeRegionList([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expected := "unable to list regions of 'abc' with error no db"
+	if err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
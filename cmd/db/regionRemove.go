@@ -0,0 +1,74 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/spf13/cobra"
+)
+
+const regionRemoveArgs = 2
+
+// RegionRemoveCmd terminates one datacenter of a database
+var RegionRemoveCmd = &cobra.Command{
+	Use:   "remove <id> <region|datacenter id>",
+	Short: "removes a region from the database",
+	Long:  `terminates the datacenter of the database in the specified region (or with the specified datacenter id) and waits until it is terminated`,
+	Args:  cobra.ExactArgs(regionRemoveArgs),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executeRegionRemove(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(msg)
+	},
+}
+
+func executeRegionRemove(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	id := args[0]
+	key := args[1]
+	dcs, err := client.ListDatacenters(id, false)
+	if err != nil {
+		return "", fmt.Errorf("unable to list regions of '%s' with error %v", id, err)
+	}
+	dc, found := pkg.FindDatacenter(dcs, key)
+	if !found || dc.Id == nil {
+		return "", fmt.Errorf("no datacenter with region or id '%s' found for database '%s'", key, id)
+	}
+	if len(dcs) == 1 {
+		return "", fmt.Errorf("region '%s' is the only region of database '%s', use delete to remove the database", dc.Region, id)
+	}
+	if !env.DryRun {
+		fmt.Printf("starting to remove region %v (datacenter %v) from database %v\n", dc.Region, *dc.Id, id)
+	}
+	if err := client.TerminateDatacenter(id, *dc.Id); err != nil {
+		return "", fmt.Errorf("unable to remove region '%s' from '%s' with error %v", key, id, err)
+	}
+	if env.DryRun {
+		return fmt.Sprintf("dry run, would remove region %v (datacenter %v) from database %v", dc.Region, *dc.Id, id), nil
+	}
+	return fmt.Sprintf("region %v removed from database %v", dc.Region, id), nil
+}
@@ -0,0 +1,104 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db is where the Astra DB commands are
+package db
+
+import (
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestRegionRemoveByRegion(t *testing.T) {
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Id: astraops.StringPtr("abc-1"), Region: "us-east1", Status: "ACTIVE"},
+			{Id: astraops.StringPtr("abc-2"), Region: "us-west1", Status: "ACTIVE"},
+		},
+	}
+	msg, err := executeRegionRemove([]string{"abc", "us-west1"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	terminateCall := mockClient.Call(1).([]interface{})
+	if terminateCall[1] != "abc-2" {
+		t.Errorf("expected '%v' but was '%v'", "abc-2", terminateCall[1])
+	}
+	expected := "region us-west1 removed from database abc"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
+
+func TestRegionRemoveLastRegion(t *testing.T) {
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Id: astraops.StringPtr("abc-1"), Region: "us-east1", Status: "ACTIVE"},
+		},
+	}
+	_, err := executeRegionRemove([]string{"abc", "abc-1"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(mockClient.Calls()) != 1 {
+		t.Errorf("expected 1 call but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestRegionRemoveNotFound(t *testing.T) {
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Id: astraops.StringPtr("abc-1"), Region: "us-east1", Status: "ACTIVE"},
+		},
+	}
+	_, err := executeRegionRemove([]string{"abc", "eu-west1"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expected := "no datacenter with region or id 'eu-west1' found for database 'abc'"
+	if err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestRegionRemoveDryRun(t *testing.T) {
+	env.DryRun = true
+	defer func() { env.DryRun = false }()
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Id: astraops.StringPtr("abc-1"), Region: "us-east1", Status: "ACTIVE"},
+			{Id: astraops.StringPtr("abc-2"), Region: "us-west1", Status: "ACTIVE"},
+		},
+	}
+	msg, err := executeRegionRemove([]string{"abc", "us-west1"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	expected := "dry run, would remove region us-west1 (datacenter abc-2) from database abc"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
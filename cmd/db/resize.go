@@ -21,17 +21,22 @@ import (
 	"strconv"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
 	"github.com/spf13/cobra"
 )
 
-const noRequiredArgs = 2
+func init() {
+	AddOverrideProtectionFlag(ResizeCmd)
+	addBulkFlags(ResizeCmd)
+}
 
 // ResizeCmd provides the resize database command
 var ResizeCmd = &cobra.Command{
-	Use:   "resize <id> <capacity unit>",
+	Use:   "resize <id>... <capacity unit>",
 	Short: "Resizes a database by id with the specified capacity unit",
-	Long:  "Resizes a database by id with the specified capacity unit. Note does not work on serverless.",
-	Args:  cobra.ExactArgs(noRequiredArgs),
+	Long:  "Resizes databases by id or by --selector with the specified capacity unit, which is always the last argument. Note does not work on serverless.",
+	Args:  bulkArgs(1),
 	Run: func(cobraCmd *cobra.Command, args []string) {
 		creds := &pkg.Creds{}
 		err := executeResize(args, creds.Login)
@@ -49,8 +54,8 @@ func executeResize(args []string, makeClient func() (pkg.Client, error)) error {
 	if err != nil {
 		return fmt.Errorf("unable to login with error %v", err)
 	}
-	id := args[0]
-	capacityUnitRaw := args[1]
+	ids := args[:len(args)-1]
+	capacityUnitRaw := args[len(args)-1]
 	defaultCapacity := 10
 	bits := 32
 	capacityUnit, err := strconv.ParseInt(capacityUnitRaw, defaultCapacity, bits)
@@ -60,9 +65,30 @@ func executeResize(args []string, makeClient func() (pkg.Client, error)) error {
 			Err:  fmt.Errorf("unable to parse capacity unit '%s' with error %v", capacityUnitRaw, err),
 		}
 	}
+	if isBulk(ids) {
+		msg, err := executeBulk(client, ids, bulkOperation{
+			name: "resize",
+			done: fmt.Sprintf("resize to %v submitted", capacityUnit),
+			run: func(client pkg.Client, db astraops.Database) error {
+				return client.Resize(db.Id, int(capacityUnit))
+			},
+		})
+		if msg != "" {
+			fmt.Println(msg)
+		}
+		return err
+	}
+	id := ids[0]
+	if err := guard(client, id, "resize"); err != nil {
+		return err
+	}
 	if err := client.Resize(id, int(capacityUnit)); err != nil {
 		return fmt.Errorf("unable to resize '%s' with error %v", id, err)
 	}
+	if env.DryRun {
+		fmt.Printf("dry run, would resize database %v to size %v\n", id, capacityUnit)
+		return nil
+	}
 	fmt.Printf("resize database %v submitted with size %v\n", id, capacityUnit)
 	return nil
 }
@@ -24,6 +24,7 @@ import (
 )
 
 func TestResize(t *testing.T) {
+	withPolicy(t, "")
 	// setting package variables by hand, there be dragons
 	mockClient := &tests.MockClient{}
 	id := "resizeId1"
@@ -49,6 +50,7 @@ func TestResize(t *testing.T) {
 }
 
 func TestResizeParseError(t *testing.T) {
+	withPolicy(t, "")
 	// setting package variables by hand, there be dragons
 	mockClient := &tests.MockClient{}
 	id := "resizeparseId"
@@ -69,6 +71,7 @@ func TestResizeParseError(t *testing.T) {
 }
 
 func TestResizeFailed(t *testing.T) {
+	withPolicy(t, "")
 	// setting package variables by hand, there be dragons
 	mockClient := &tests.MockClient{}
 	mockClient.ErrorQueue = []error{errors.New("no db")}
@@ -0,0 +1,46 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	SchemaCmd.AddCommand(SchemaDumpCmd)
+}
+
+// SchemaCmd is the parent command for the schema of the keyspaces of a database
+var SchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Shows all the schema commands",
+	Long:  `Shows all the schema commands. Export the keyspaces, types, tables and indexes of a database as CQL`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeSchema(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeSchema(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	"github.com/datastax-labs/astra-cli/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+var schemaDumpKeyspace string
+var schemaDumpDir string
+var schemaDumpTimeout time.Duration
+
+func init() {
+	SchemaDumpCmd.Flags().StringVarP(&schemaDumpKeyspace, "keyspace", "k", "", "keyspace to dump, every keyspace of the database by default")
+	SchemaDumpCmd.Flags().StringVarP(&schemaDumpDir, "dir", "d", "", "directory to write a file per table into, <keyspace>/keyspace.cql has the keyspace and its types, <keyspace>/tables the tables")
+	SchemaDumpCmd.Flags().DurationVar(&schemaDumpTimeout, "timeout", cql.DefaultTimeout, "timeout of connecting and of each query")
+}
+
+// schemaSession reads system_schema
+type schemaSession interface {
+	schema.Session
+	Close() error
+}
+
+// openSchemaSession connects to the database
+var openSchemaSession = func(client pkg.Client, id string) (schemaSession, error) {
+	conn, err := connectCQL(client, id, "", schemaDumpTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// SchemaDumpCmd writes the schema of the keyspaces as CQL
+var SchemaDumpCmd = &cobra.Command{
+	Use:   "dump <id|name>",
+	Short: "dumps the schema of the keyspaces as CQL",
+	Long: `prints the CREATE statements of the keyspaces, user defined types, tables and indexes of the database read from
+system_schema. Everything is sorted, and types come after the types they use, so dumps of two databases can be compared with diff.
+Table options other than the clustering order, comment and default_time_to_live are left out`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeSchemaDump(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeSchemaDump(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, err := makeClient()
+	if err != nil {
+		return "", fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, args[0])
+	if err != nil {
+		return "", err
+	}
+	keyspaces := dumpKeyspaces(db.Info.Keyspace, db.Info.AdditionalKeyspaces)
+	if len(keyspaces) == 0 {
+		return "", fmt.Errorf("database '%s' has no keyspace, pass one with -k", args[0])
+	}
+	session, err := openSchemaSession(client, db.Id)
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	var dumps []schema.Keyspace
+	for _, name := range keyspaces {
+		ks, err := schema.Load(session, name)
+		if err != nil {
+			return "", fmt.Errorf("unable to read the schema of %v with error %v", name, err)
+		}
+		dumps = append(dumps, ks)
+	}
+	if schemaDumpDir != "" {
+		return writeSchemaFiles(dumps)
+	}
+	var ddl []string
+	for _, ks := range dumps {
+		ddl = append(ddl, schema.Dump(ks))
+	}
+	return strings.TrimSuffix(strings.Join(ddl, "\n"), "\n"), nil
+}
+
+// dumpKeyspaces is -k or the keyspaces of the database sorted
+func dumpKeyspaces(keyspace *string, additional *[]string) []string {
+	if schemaDumpKeyspace != "" {
+		return []string{schemaDumpKeyspace}
+	}
+	seen := make(map[string]bool)
+	var keyspaces []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			keyspaces = append(keyspaces, name)
+		}
+	}
+	if keyspace != nil {
+		add(*keyspace)
+	}
+	if additional != nil {
+		for _, name := range *additional {
+			add(name)
+		}
+	}
+	sort.Strings(keyspaces)
+	return keyspaces
+}
+
+// writeSchemaFiles writes a file per table under --dir and lists the files written
+func writeSchemaFiles(dumps []schema.Keyspace) (string, error) {
+	var written []string
+	for _, ks := range dumps {
+		for _, f := range schema.DumpFiles(ks) {
+			target := filepath.Join(schemaDumpDir, filepath.FromSlash(f.Name))
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return "", fmt.Errorf("unable to create '%v' with error %v", filepath.Dir(target), err)
+			}
+			if err := os.WriteFile(target, []byte(f.Content), 0600); err != nil {
+				return "", fmt.Errorf("unable to write '%v' with error %v", target, err)
+			}
+			written = append(written, target)
+		}
+	}
+	return fmt.Sprintf("wrote %v files to %v\n%v", len(written), schemaDumpDir, strings.Join(written, "\n")), nil
+}
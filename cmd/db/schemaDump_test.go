@@ -0,0 +1,194 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// schemaStandIn answers system_schema queries with a users table in every keyspace but missing
+type schemaStandIn struct {
+	statements []string
+	closed     bool
+}
+
+func (s *schemaStandIn) Query(statement string) (cql.Result, error) {
+	s.statements = append(s.statements, statement)
+	if strings.Contains(statement, "'missing'") {
+		if strings.Contains(statement, "system_schema.keyspaces") {
+			return cql.Result{Columns: []string{"replication", "durable_writes"}}, nil
+		}
+		return cql.Result{}, nil
+	}
+	switch {
+	case strings.Contains(statement, "system_schema.keyspaces"):
+		return cql.Result{Columns: []string{"replication", "durable_writes"}, Rows: [][]string{{"{'class': 'NetworkTopologyStrategy', 'us-east1': '3'}", "true"}}}, nil
+	case strings.Contains(statement, "system_schema.tables"):
+		return cql.Result{Columns: []string{"table_name", "comment", "default_time_to_live"}, Rows: [][]string{{"users", "", "0"}}}, nil
+	case strings.Contains(statement, "system_schema.columns"):
+		return cql.Result{Columns: []string{"table_name", "column_name", "type", "kind", "position", "clustering_order"}, Rows: [][]string{
+			{"users", "name", "text", "regular", "-1", "none"},
+			{"users", "id", "int", "partition_key", "0", "none"},
+		}}, nil
+	}
+	return cql.Result{}, nil
+}
+
+func (s *schemaStandIn) Close() error {
+	s.closed = true
+	return nil
+}
+
+// withSchemaDump replaces the session with a stand-in and resets the flags after the test
+func withSchemaDump(t *testing.T) *schemaStandIn {
+	// setting package variables by hand, there be dragons
+	session := &schemaStandIn{}
+	original := openSchemaSession
+	openSchemaSession = func(client pkg.Client, id string) (schemaSession, error) {
+		if id != cqlshDbID {
+			t.Errorf("unexpected database %v", id)
+		}
+		return session, nil
+	}
+	t.Cleanup(func() {
+		openSchemaSession = original
+		schemaDumpKeyspace = ""
+		schemaDumpDir = ""
+	})
+	return session
+}
+
+// schemaClient has a database with the keyspaces ks1 and ks0
+func schemaClient() func() (pkg.Client, error) {
+	return func() (pkg.Client, error) {
+		db := cqlshDb()
+		db.Info.AdditionalKeyspaces = &[]string{"ks0", "ks1"}
+		return &tests.MockClient{Databases: []astraops.Database{db}}, nil
+	}
+}
+
+const usersDDL = `CREATE TABLE %v.users (
+    id int,
+    name text,
+    PRIMARY KEY (id)
+);`
+
+func TestSchemaDump(t *testing.T) {
+	session := withSchemaDump(t)
+	out, err := executeSchemaDump([]string{"mydb"}, schemaClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ks0 := strings.Index(out, "CREATE KEYSPACE ks0 WITH replication = {'class': 'NetworkTopologyStrategy', 'us-east1': '3'} AND durable_writes = true;")
+	ks1 := strings.Index(out, "CREATE KEYSPACE ks1 ")
+	if ks0 != 0 || ks1 < 0 {
+		t.Errorf("expected ks0 then ks1 but was\n%v", out)
+	}
+	if !strings.HasSuffix(out, strings.ReplaceAll(usersDDL, "%v", "ks1")) {
+		t.Errorf("expected the users table of ks1 last but was\n%v", out)
+	}
+	if !session.closed {
+		t.Error("expected the session to be closed")
+	}
+}
+
+func TestSchemaDumpKeyspace(t *testing.T) {
+	session := withSchemaDump(t)
+	schemaDumpKeyspace = "other"
+	out, err := executeSchemaDump([]string{"mydb"}, schemaClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if strings.Count(out, "CREATE KEYSPACE") != 1 || !strings.HasPrefix(out, "CREATE KEYSPACE other ") {
+		t.Errorf("expected only the keyspace other but was\n%v", out)
+	}
+	for _, s := range session.statements {
+		if !strings.HasSuffix(s, "WHERE keyspace_name = 'other'") {
+			t.Errorf("unexpected statement %v", s)
+		}
+	}
+}
+
+func TestSchemaDumpDir(t *testing.T) {
+	withSchemaDump(t)
+	schemaDumpDir = t.TempDir()
+	schemaDumpKeyspace = "ks1"
+	out, err := executeSchemaDump([]string{"mydb"}, schemaClient())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	keyspaceFile := filepath.Join(schemaDumpDir, "ks1", "keyspace.cql")
+	tableFile := filepath.Join(schemaDumpDir, "ks1", "tables", "users.cql")
+	expected := "wrote 2 files to " + schemaDumpDir + "\n" + keyspaceFile + "\n" + tableFile
+	if out != expected {
+		t.Errorf("expected %q but was %q", expected, out)
+	}
+	table, err := os.ReadFile(tableFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(table) != strings.ReplaceAll(usersDDL, "%v", "ks1")+"\n" {
+		t.Errorf("unexpected table file %q", table)
+	}
+}
+
+func TestSchemaDumpMissingKeyspace(t *testing.T) {
+	withSchemaDump(t)
+	schemaDumpKeyspace = "missing"
+	_, err := executeSchemaDump([]string{"mydb"}, schemaClient())
+	expected := "unable to read the schema of missing with error keyspace 'missing' not found"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestSchemaDumpNoKeyspace(t *testing.T) {
+	withSchemaDump(t)
+	name := "mydb"
+	_, err := executeSchemaDump([]string{"mydb"}, func() (pkg.Client, error) {
+		return &tests.MockClient{Databases: []astraops.Database{{Id: cqlshDbID, Info: astraops.DatabaseInfo{Name: &name}}}}, nil
+	})
+	if err == nil || err.Error() != "database 'mydb' has no keyspace, pass one with -k" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSchemaDumpConnectFails(t *testing.T) {
+	withSchemaDump(t)
+	openSchemaSession = func(client pkg.Client, id string) (schemaSession, error) {
+		return nil, errors.New("unable to connect")
+	}
+	if _, err := executeSchemaDump([]string{"mydb"}, schemaClient()); err == nil || err.Error() != "unable to connect" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSchemaDumpLoginFails(t *testing.T) {
+	withSchemaDump(t)
+	_, err := executeSchemaDump([]string{"mydb"}, func() (pkg.Client, error) { return nil, errors.New("bad creds") })
+	if err == nil || err.Error() != "unable to login with error bad creds" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/datastax-labs/astra-cli/pkg"
 	"github.com/datastax-labs/astra-cli/pkg/httputils"
@@ -30,11 +31,16 @@ import (
 var secBundleFmt string
 var secBundleLoc string
 var secBundleDownloadType string
+var secBundleAllRegions bool
+var secBundleDir string
 
 func init() {
-	SecBundleCmd.Flags().StringVarP(&secBundleFmt, "output", "o", "zip", "Output format for report default is zip")
-	SecBundleCmd.Flags().StringVarP(&secBundleDownloadType, "download-type", "d", "external", "Bundle type to download external, internal, proxy-external and proxy-internal available. Only works with -o zip")
+	SecBundleCmd.Flags().StringVarP(&secBundleFmt, "output", "o", "zip", "Output format for report default is zip, can also be json, list, dir to extract the bundle into --dir or k8s-secret to print a Kubernetes Secret")
+	SecBundleCmd.Flags().StringVarP(&secBundleDownloadType, "download-type", "d", "external", "Bundle type to download external, internal, proxy-external and proxy-internal available. Only works with -o zip, dir and k8s-secret")
 	SecBundleCmd.Flags().StringVarP(&secBundleLoc, "location", "l", "secureBundle.zip", "location of bundle to download to if using zip format. ignore if using json")
+	SecBundleCmd.Flags().BoolVar(&secBundleAllRegions, "all-regions", false, "download the bundle of every region into --dir with a manifest.json. Only works with -o zip")
+	SecBundleCmd.Flags().StringVar(&secBundleDir, "dir", "secureBundles", "directory the bundles are saved to when using --all-regions or extracted to when using -o dir")
+	SecBundleCmd.AddCommand(SecBundleInspectCmd)
 }
 
 // SecBundleCmd  provides the secBundle database command
@@ -60,30 +66,44 @@ func executeSecBundle(args []string, login func() (pkg.Client, error)) (string,
 		return "", fmt.Errorf("unable to login with error %v", err)
 	}
 	id := args[0]
+	if secBundleAllRegions {
+		if secBundleFmt != "zip" {
+			return "", fmt.Errorf("--all-regions only works with -o zip")
+		}
+		return downloadAllRegions(client, id)
+	}
 	var secBundle astraops.CredsURL
 	if secBundle, err = client.GetSecureBundle(id); err != nil {
 		return "", fmt.Errorf("unable to get '%s' with error %v", id, err)
 	}
+	available := availableBundles(secBundle)
 	switch secBundleFmt {
 	case "zip":
-		var urlToDownload string
-		switch secBundleDownloadType {
-		case "external":
-			urlToDownload = secBundle.DownloadURL
-		case "internal":
-			urlToDownload = *secBundle.DownloadURLInternal
-		case "proxy-external":
-			urlToDownload = *secBundle.DownloadURLMigrationProxy
-		case "proxy-internal":
-			urlToDownload = *secBundle.DownloadURLMigrationProxyInternal
-		default:
-			return "", fmt.Errorf("invalid download type %s passed. valid options are 'external', 'internal', 'proxy-external', 'proxy-internal'", secBundleDownloadType)
+		urlToDownload, err := bundleURL(id, available, secBundleDownloadType)
+		if err != nil {
+			return "", err
 		}
-		bytesWritten, err := httputils.DownloadZip(urlToDownload, secBundleLoc)
+		bytesWritten, sum, err := httputils.DownloadZip(urlToDownload, secBundleLoc)
 		if err != nil {
 			return "", fmt.Errorf("error outputing zip format '%v'", err)
 		}
-		return fmt.Sprintf("file %v saved %v bytes written", secBundleLoc, bytesWritten), nil
+		return fmt.Sprintf("file %v saved %v bytes written sha256 %v", secBundleLoc, bytesWritten, sum), nil
+	case dirFormat:
+		zipBytes, err := downloadBundle(id, available)
+		if err != nil {
+			return "", err
+		}
+		names, err := extractBundle(zipBytes, secBundleDir)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("bundle extracted to %v: %v", secBundleDir, strings.Join(names, ", ")), nil
+	case k8sSecretFormat:
+		zipBytes, err := downloadBundle(id, available)
+		if err != nil {
+			return "", err
+		}
+		return makeK8sSecret(zipBytes)
 	case pkg.JSONFormat:
 		b, err := json.MarshalIndent(secBundle, "", "  ")
 		if err != nil {
@@ -91,13 +111,70 @@ func executeSecBundle(args []string, login func() (pkg.Client, error)) (string,
 		}
 		return string(b), nil
 	case "list":
-		return fmt.Sprintf(`
-		external bundle: %s
-		internal bundle: %s
-		external proxy: %s
-		internal proxy: %s
-		`, secBundle.DownloadURL, *secBundle.DownloadURLInternal, *secBundle.DownloadURLMigrationProxy, *secBundle.DownloadURLMigrationProxyInternal), nil
+		if len(available) == 0 {
+			return "", fmt.Errorf("no secure bundle is available for '%s'", id)
+		}
+		// same layout and labels as before missing types were left out, scripts parse it
+		var out strings.Builder
+		out.WriteString("\n")
+		for _, b := range available {
+			fmt.Fprintf(&out, "\t\t%s: %s\n", bundleLabels[b.name], b.url)
+		}
+		out.WriteString("\t\t")
+		return out.String(), nil
 	default:
 		return "", fmt.Errorf("-o %q is not valid option", secBundleFmt)
 	}
 }
+
+// bundleTypes are the valid --download-type values
+var bundleTypes = []string{"external", "internal", "proxy-external", "proxy-internal"}
+
+// bundleLabels name the bundle types in the list output
+var bundleLabels = map[string]string{
+	"external":       "external bundle",
+	"internal":       "internal bundle",
+	"proxy-external": "external proxy",
+	"proxy-internal": "internal proxy",
+}
+
+// availableBundle is a bundle type the database has a download URL for
+type availableBundle struct {
+	name string
+	url  string
+}
+
+// availableBundles returns the bundle types with a download URL in the order of bundleTypes. Only external is always
+// there, the others are missing for databases that do not support private endpoints or the migration proxy
+func availableBundles(secBundle astraops.CredsURL) []availableBundle {
+	return bundlesWithURL(&secBundle.DownloadURL, secBundle.DownloadURLInternal, secBundle.DownloadURLMigrationProxy, secBundle.DownloadURLMigrationProxyInternal)
+}
+
+// bundlesWithURL pairs the urls with bundleTypes and drops the missing ones
+func bundlesWithURL(urls ...*string) []availableBundle {
+	var available []availableBundle
+	for i, u := range urls {
+		if u != nil && *u != "" {
+			available = append(available, availableBundle{name: bundleTypes[i], url: *u})
+		}
+	}
+	return available
+}
+
+// bundleURL finds the download URL of the type, the error lists the types available when it is missing
+func bundleURL(id string, available []availableBundle, downloadType string) (string, error) {
+	if !contains(bundleTypes, downloadType) {
+		return "", fmt.Errorf("invalid download type %s passed. valid options are 'external', 'internal', 'proxy-external', 'proxy-internal'", downloadType)
+	}
+	var names []string
+	for _, b := range available {
+		if b.name == downloadType {
+			return b.url, nil
+		}
+		names = append(names, b.name)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("download type %s is not available for '%s', the database has no secure bundle", downloadType, id)
+	}
+	return "", fmt.Errorf("download type %s is not available for '%s', available types are: %s", downloadType, id, strings.Join(names, ", "))
+}
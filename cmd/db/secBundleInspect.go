@@ -0,0 +1,99 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	"github.com/spf13/cobra"
+)
+
+// SecBundleInspectCmd checks a secure connect bundle already downloaded
+var SecBundleInspectCmd = &cobra.Command{
+	Use:   "inspect <file>",
+	Short: "shows and checks the content of a secure bundle zip",
+	Long: `opens a secure connect bundle, shows the host, ports and keyspace of its config.json and the CA and client certificates
+with their expiry dates. Exits with 1 if a setting, file or certificate is missing or a certificate is expired`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		out, err := executeSecBundleInspect(args, time.Now())
+		if out != "" {
+			fmt.Println(out)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func executeSecBundleInspect(args []string, now time.Time) (string, error) {
+	b, err := bundle.Open(args[0])
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	err = pkg.WriteRows(&out, [][]string{
+		{"host", b.Config.Host},
+		{"port", fmt.Sprintf("%v", b.Config.Port)},
+		{"cql port", fmt.Sprintf("%v", b.Config.CqlPort)},
+		{"keyspace", b.Config.Keyspace},
+		{"local dc", b.Config.LocalDC},
+		{"files", strings.Join(b.FileNames(), ", ")},
+	})
+	if err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	certs, certErr := b.Certificates()
+	if certErr == nil && len(certs) > 0 {
+		rows := [][]string{{"file", "subject", "issuer", "not before", "not after", "status"}}
+		for _, c := range certs {
+			rows = append(rows, []string{c.File, c.Subject, c.Issuer, c.NotBefore.Format("2006-01-02"), c.NotAfter.Format("2006-01-02"), certificateStatus(c, now)})
+		}
+		out.WriteString("\n\n")
+		if err := pkg.WriteRows(&out, rows); err != nil {
+			return "", fmt.Errorf("unexpected error writing text output %v", err)
+		}
+	}
+	problems := b.Check(now)
+	if len(problems) == 0 {
+		out.WriteString("\n\nbundle is valid")
+		return out.String(), nil
+	}
+	out.WriteString("\n\nproblems:")
+	for _, p := range problems {
+		out.WriteString("\n  " + p)
+	}
+	return out.String(), fmt.Errorf("bundle '%v' has %v problem(s)", args[0], len(problems))
+}
+
+func certificateStatus(c bundle.Certificate, now time.Time) string {
+	switch {
+	case now.After(c.NotAfter):
+		return "expired"
+	case now.Before(c.NotBefore):
+		return "not valid yet"
+	default:
+		days := int(c.NotAfter.Sub(now).Hours() / 24)
+		return fmt.Sprintf("expires in %v days", days)
+	}
+}
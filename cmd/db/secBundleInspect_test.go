@@ -0,0 +1,69 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+)
+
+func writeBundle(t *testing.T, opts tests.BundleOptions) string {
+	zip, err := tests.SecureBundle(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := path.Join(t.TempDir(), "secureBundle.zip")
+	if err := os.WriteFile(f, zip, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestSecBundleInspect(t *testing.T) {
+	f := writeBundle(t, tests.BundleOptions{Host: "db.example.com", Keyspace: "app"})
+	out, err := executeSecBundleInspect([]string{f}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, expected := range []string{"db.example.com", "29042", "app", "CN=test-client", "expires in", "bundle is valid"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected '%v' in output '%v'", expected, out)
+		}
+	}
+}
+
+func TestSecBundleInspectExpired(t *testing.T) {
+	f := writeBundle(t, tests.BundleOptions{NotAfter: time.Now().AddDate(0, 0, 10)})
+	out, err := executeSecBundleInspect([]string{f}, time.Now().AddDate(0, 0, 20))
+	if err == nil {
+		t.Fatal("expected an error for an expired bundle")
+	}
+	if !strings.Contains(out, "expired") || !strings.Contains(out, "problems:") {
+		t.Errorf("expected the expired certificate in output '%v'", out)
+	}
+}
+
+func TestSecBundleInspectMissingFile(t *testing.T) {
+	_, err := executeSecBundleInspect([]string{path.Join(t.TempDir(), "missing.zip")}, time.Now())
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
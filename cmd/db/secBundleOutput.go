@@ -0,0 +1,168 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/httputils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// dirFormat extracts the bundle into --dir
+	dirFormat = "dir"
+	// k8sSecretFormat prints a Kubernetes Secret holding the bundle
+	k8sSecretFormat = "k8s-secret"
+	// secretBundleKey is the key of the zip in the Kubernetes Secret
+	secretBundleKey = "secure-connect-bundle.zip"
+)
+
+var secBundleSecretName string
+var secBundleNamespace string
+var secBundleWithCredentials bool
+
+// storedCreds returns the token or service account added to the secret by --with-credentials
+var storedCreds = func() (string, pkg.ClientInfo, error) {
+	creds := &pkg.Creds{}
+	return creds.Stored()
+}
+
+func init() {
+	SecBundleCmd.Flags().StringVar(&secBundleSecretName, "secret-name", "astra-secure-bundle", "name of the Secret when using -o k8s-secret")
+	SecBundleCmd.Flags().StringVar(&secBundleNamespace, "namespace", "", "namespace of the Secret when using -o k8s-secret, left out when empty")
+	SecBundleCmd.Flags().BoolVar(&secBundleWithCredentials, "with-credentials", false, "add the token, or the client id and secret of the service account, used by astra-cli login to the Secret")
+}
+
+// downloadBundle keeps the bundle of --download-type in memory
+func downloadBundle(id string, available []availableBundle) ([]byte, error) {
+	urlToDownload, err := bundleURL(id, available, secBundleDownloadType)
+	if err != nil {
+		return nil, err
+	}
+	zipBytes, err := httputils.DownloadZipToMemory(urlToDownload)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading bundle '%v'", err)
+	}
+	return zipBytes, nil
+}
+
+// extractBundle writes the files of the zip into dir readable only by the user, the zip itself is never saved
+func extractBundle(zipBytes []byte, dir string) ([]string, error) {
+	r, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	if err != nil {
+		return nil, fmt.Errorf("bundle is not a valid zip with error %v", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create directory '%v' with error %v", dir, err)
+	}
+	var names []string
+	for _, f := range r.File {
+		name := filepath.Clean(filepath.FromSlash(f.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("bundle has file '%v' outside of the bundle, not extracting", f.Name)
+		}
+		target := filepath.Join(dir, name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return nil, fmt.Errorf("unable to create directory '%v' with error %v", target, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, fmt.Errorf("unable to create directory '%v' with error %v", filepath.Dir(target), err)
+		}
+		if err := extractFile(f, target); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func extractFile(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("unable to open '%v' in bundle with error %v", f.Name, err)
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to create '%v' with error %v", target, err)
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return fmt.Errorf("unable to extract '%v' with error %v", target, err)
+	}
+	return out.Close()
+}
+
+// k8sSecret is the part of a Kubernetes Secret manifest needed to hold the bundle
+type k8sSecret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type k8sMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// makeK8sSecret builds the Secret manifest with the zip base64 encoded, and the stored credentials when asked
+func makeK8sSecret(zipBytes []byte) (string, error) {
+	data := map[string]string{
+		secretBundleKey: base64.StdEncoding.EncodeToString(zipBytes),
+	}
+	if secBundleWithCredentials {
+		token, clientInfo, err := storedCreds()
+		if err != nil {
+			return "", fmt.Errorf("unable to read credentials for the secret with error %v", err)
+		}
+		if token != "" {
+			data["token"] = base64.StdEncoding.EncodeToString([]byte(token))
+		} else {
+			data["clientId"] = base64.StdEncoding.EncodeToString([]byte(clientInfo.ClientID))
+			data["clientName"] = base64.StdEncoding.EncodeToString([]byte(clientInfo.ClientName))
+			data["clientSecret"] = base64.StdEncoding.EncodeToString([]byte(clientInfo.ClientSecret))
+		}
+	}
+	secret := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sMetadata{
+			Name:      secBundleSecretName,
+			Namespace: secBundleNamespace,
+		},
+		Type: "Opaque",
+		Data: data,
+	}
+	b, err := yaml.Marshal(secret)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal secret with error %v", err)
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
@@ -0,0 +1,178 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+	"gopkg.in/yaml.v3"
+)
+
+// serveBundle serves a generated bundle and returns a client whose external bundle points at it
+func serveBundle(t *testing.T) ([]byte, *tests.MockClient) {
+	zipContent, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		if _, err := w.Write(zipContent); err != nil {
+			t.Logf("unable to write zip %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return zipContent, &tests.MockClient{Bundle: astraops.CredsURL{DownloadURL: ts.URL}}
+}
+
+func withSecBundleFmt(t *testing.T, format string) {
+	// setting package variables by hand, there be dragons
+	secBundleFmt = format
+	t.Cleanup(func() {
+		secBundleFmt = "zip"
+		secBundleDir = "secureBundles"
+		secBundleSecretName = "astra-secure-bundle"
+		secBundleNamespace = ""
+		secBundleWithCredentials = false
+	})
+}
+
+func TestSecBundleDir(t *testing.T) {
+	_, mockClient := serveBundle(t)
+	withSecBundleFmt(t, dirFormat)
+	secBundleDir = path.Join(t.TempDir(), "bundle")
+	msg, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(msg, "bundle extracted to "+secBundleDir) {
+		t.Errorf("unexpected message '%v'", msg)
+	}
+	entries, err := os.ReadDir(secBundleDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected %v to be 0600 but was %v", e.Name(), info.Mode().Perm())
+		}
+		if strings.HasSuffix(e.Name(), ".zip") {
+			t.Errorf("expected the zip not to be written but found %v", e.Name())
+		}
+	}
+	if strings.Join(names, ",") != "ca.crt,cert,config.json,cqlshrc,key" {
+		t.Errorf("unexpected files %v", names)
+	}
+}
+
+func TestExtractBundleRejectsEscapingPaths(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	if _, err := w.Create("../evil"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dir := path.Join(t.TempDir(), "bundle")
+	if _, err := extractBundle(buf.Bytes(), dir); err == nil {
+		t.Fatal("expected an error for a path outside of the directory")
+	}
+	if _, err := os.Stat(path.Join(dir, "..", "evil")); !os.IsNotExist(err) {
+		t.Error("expected nothing to be written outside of the directory")
+	}
+}
+
+func TestSecBundleK8sSecret(t *testing.T) {
+	zipContent, mockClient := serveBundle(t)
+	withSecBundleFmt(t, k8sSecretFormat)
+	secBundleSecretName = "app-bundle"
+	secBundleNamespace = "apps"
+	secBundleWithCredentials = true
+	original := storedCreds
+	storedCreds = func() (string, pkg.ClientInfo, error) {
+		return "AstraCS:abc", pkg.ClientInfo{}, nil
+	}
+	defer func() {
+		storedCreds = original
+	}()
+	msg, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var secret k8sSecret
+	if err := yaml.Unmarshal([]byte(msg), &secret); err != nil {
+		t.Fatalf("unable to parse secret '%v' with error %v", msg, err)
+	}
+	if secret.Kind != "Secret" || secret.Metadata.Name != "app-bundle" || secret.Metadata.Namespace != "apps" {
+		t.Errorf("unexpected secret %v", secret)
+	}
+	bundle, err := base64.StdEncoding.DecodeString(secret.Data[secretBundleKey])
+	if err != nil || !bytes.Equal(bundle, zipContent) {
+		t.Errorf("expected the bundle in the secret with error %v", err)
+	}
+	token, err := base64.StdEncoding.DecodeString(secret.Data["token"])
+	if err != nil || string(token) != "AstraCS:abc" {
+		t.Errorf("expected the token in the secret but was '%v' with error %v", string(token), err)
+	}
+}
+
+func TestSecBundleK8sSecretServiceAccount(t *testing.T) {
+	_, mockClient := serveBundle(t)
+	withSecBundleFmt(t, k8sSecretFormat)
+	secBundleWithCredentials = true
+	original := storedCreds
+	storedCreds = func() (string, pkg.ClientInfo, error) {
+		return "", pkg.ClientInfo{ClientID: "id", ClientName: "name", ClientSecret: "secret"}, nil
+	}
+	defer func() {
+		storedCreds = original
+	}()
+	msg, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if strings.Contains(msg, "namespace") {
+		t.Errorf("expected no namespace but was '%v'", msg)
+	}
+	for _, key := range []string{"clientId", "clientName", "clientSecret"} {
+		if !strings.Contains(msg, key+":") {
+			t.Errorf("expected %v in '%v'", key, msg)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/httputils"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// manifestFile describes the bundles saved by --all-regions
+const manifestFile = "manifest.json"
+
+// bundleManifest is the content of manifest.json
+type bundleManifest struct {
+	DatabaseID   string           `json:"databaseId"`
+	DownloadType string           `json:"downloadType"`
+	Bundles      []regionalBundle `json:"bundles"`
+}
+
+// regionalBundle is the bundle of one datacenter
+type regionalBundle struct {
+	Region        string `json:"region"`
+	DatacenterID  string `json:"datacenterId"`
+	CloudProvider string `json:"cloudProvider"`
+	File          string `json:"file"`
+	Bytes         int64  `json:"bytes"`
+	SHA256        string `json:"sha256"`
+}
+
+// datacenterBundles returns the bundle types the datacenter has a download URL for, in the order of bundleTypes
+func datacenterBundles(dc astraops.Datacenter) []availableBundle {
+	return bundlesWithURL(dc.SecureBundleUrl, dc.SecureBundleInternalUrl, dc.SecureBundleMigrationProxyUrl, dc.SecureBundleMigrationProxyInternalUrl)
+}
+
+// downloadAllRegions saves the bundle of every datacenter as secure-connect-<region>.zip in secBundleDir and
+// writes the manifest once all of them are downloaded
+func downloadAllRegions(client pkg.Client, id string) (string, error) {
+	dcs, err := client.ListDatacenters(id, false)
+	if err != nil {
+		return "", fmt.Errorf("unable to list regions of '%s' with error %v", id, err)
+	}
+	var active []astraops.Datacenter
+	for _, dc := range dcs {
+		if dc.Status == string(astraops.StatusEnumTERMINATED) || dc.Status == string(astraops.StatusEnumTERMINATING) {
+			continue
+		}
+		active = append(active, dc)
+	}
+	if len(active) == 0 {
+		return "", fmt.Errorf("database '%s' has no regions to download bundles for", id)
+	}
+	urls := make([]string, len(active))
+	for i, dc := range active {
+		u, err := bundleURL(fmt.Sprintf("%s region %s", id, dc.Region), datacenterBundles(dc), secBundleDownloadType)
+		if err != nil {
+			return "", err
+		}
+		urls[i] = u
+	}
+	if err := os.MkdirAll(secBundleDir, 0700); err != nil {
+		return "", fmt.Errorf("unable to create directory '%v' with error %v", secBundleDir, err)
+	}
+	manifest := bundleManifest{DatabaseID: id, DownloadType: secBundleDownloadType}
+	rows := [][]string{{"region", "file", "bytes", "sha256"}}
+	for i, dc := range active {
+		name := fmt.Sprintf("secure-connect-%s.zip", dc.Region)
+		bytesWritten, sum, err := httputils.DownloadZip(urls[i], filepath.Join(secBundleDir, name))
+		if err != nil {
+			return "", fmt.Errorf("unable to download bundle of region %s with error %v", dc.Region, err)
+		}
+		var dcID string
+		if dc.Id != nil {
+			dcID = *dc.Id
+		}
+		manifest.Bundles = append(manifest.Bundles, regionalBundle{
+			Region:        dc.Region,
+			DatacenterID:  dcID,
+			CloudProvider: string(dc.CloudProvider),
+			File:          name,
+			Bytes:         bytesWritten,
+			SHA256:        sum,
+		})
+		rows = append(rows, []string{dc.Region, name, fmt.Sprintf("%v", bytesWritten), sum})
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal manifest with error %v", err)
+	}
+	manifestPath := filepath.Join(secBundleDir, manifestFile)
+	if err := os.WriteFile(manifestPath, b, 0600); err != nil {
+		return "", fmt.Errorf("unable to write manifest '%v' with error %v", manifestPath, err)
+	}
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, rows); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	return fmt.Sprintf("%v\n\n%v bundle(s) and %v saved in %v", out.String(), len(manifest.Bundles), manifestFile, secBundleDir), nil
+}
@@ -0,0 +1,122 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package db provides the sub-commands for the db command
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func withAllRegions(t *testing.T, dir string) {
+	// setting package variables by hand, there be dragons
+	secBundleFmt = "zip"
+	secBundleAllRegions = true
+	secBundleDir = dir
+	t.Cleanup(func() {
+		secBundleAllRegions = false
+		secBundleDir = "secureBundles"
+		secBundleDownloadType = "external"
+	})
+}
+
+func TestSecBundleAllRegions(t *testing.T) {
+	bundles := make(map[string][]byte)
+	for _, region := range []string{"us-east1", "europe-west1"} {
+		b, err := tests.SecureBundle(tests.BundleOptions{Host: region + ".example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundles[region] = b
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		if _, err := w.Write(bundles[strings.TrimPrefix(r.URL.Path, "/")]); err != nil {
+			t.Logf("unable to write zip %v", err)
+		}
+	}))
+	defer ts.Close()
+	dir := path.Join(t.TempDir(), "bundles")
+	withAllRegions(t, dir)
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Id: astraops.StringPtr("abc-1"), Region: "us-east1", CloudProvider: astraops.CloudProviderGCP, Status: "ACTIVE", SecureBundleUrl: astraops.StringPtr(ts.URL + "/us-east1")},
+			{Id: astraops.StringPtr("abc-2"), Region: "europe-west1", CloudProvider: astraops.CloudProviderGCP, Status: "ACTIVE", SecureBundleUrl: astraops.StringPtr(ts.URL + "/europe-west1")},
+			{Id: astraops.StringPtr("abc-3"), Region: "us-west1", CloudProvider: astraops.CloudProviderGCP, Status: "TERMINATED"},
+		},
+	}
+	msg, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasSuffix(msg, "2 bundle(s) and manifest.json saved in "+dir) {
+		t.Errorf("unexpected message '%v'", msg)
+	}
+	b, err := os.ReadFile(path.Join(dir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.DatabaseID != "abc" || manifest.DownloadType != "external" || len(manifest.Bundles) != 2 {
+		t.Fatalf("unexpected manifest %v", manifest)
+	}
+	for _, bundle := range manifest.Bundles {
+		saved, err := os.ReadFile(path.Join(dir, bundle.File))
+		if err != nil {
+			t.Fatalf("expected %v to be saved with error %v", bundle.File, err)
+		}
+		if string(saved) != string(bundles[bundle.Region]) {
+			t.Errorf("expected %v to be the bundle of %v", bundle.File, bundle.Region)
+		}
+	}
+	if manifest.Bundles[1].File != "secure-connect-europe-west1.zip" || manifest.Bundles[1].DatacenterID != "abc-2" {
+		t.Errorf("unexpected bundle %v", manifest.Bundles[1])
+	}
+}
+
+func TestSecBundleAllRegionsMissingType(t *testing.T) {
+	dir := path.Join(t.TempDir(), "bundles")
+	withAllRegions(t, dir)
+	secBundleDownloadType = "internal"
+	mockClient := &tests.MockClient{
+		Datacenters: []astraops.Datacenter{
+			{Region: "us-east1", Status: "ACTIVE", SecureBundleUrl: astraops.StringPtr("https://external")},
+		},
+	}
+	_, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	expected := "download type internal is not available for 'abc region us-east1', available types are: external"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("expected nothing to be written")
+	}
+}
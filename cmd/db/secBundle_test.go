@@ -16,6 +16,8 @@
 package db
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,6 +25,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/datastax-labs/astra-cli/pkg"
@@ -60,9 +63,15 @@ func TestSecBundle(t *testing.T) {
 }
 
 func TestSecBundleZip(t *testing.T) {
-	zipContent := "zip file content"
+	zipContent, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, zipContent)
+		w.Header().Set("Content-Type", "application/zip")
+		if _, err := w.Write(zipContent); err != nil {
+			t.Logf("unable to write zip %v", err)
+		}
 	}))
 	defer ts.Close()
 	tmpDir := t.TempDir()
@@ -89,7 +98,8 @@ func TestSecBundleZip(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
-	expected := fmt.Sprintf("file %v saved 17 bytes written", zipFile)
+	sum := sha256.Sum256(zipContent)
+	expected := fmt.Sprintf("file %v saved %v bytes written sha256 %v", zipFile, len(zipContent), hex.EncodeToString(sum[:]))
 	if msg != expected {
 		t.Errorf("expected '%v' but was '%v'", expected, msg)
 	}
@@ -134,3 +144,63 @@ func TestSecBundleFailed(t *testing.T) {
 		t.Errorf("expected '%v' but was '%v'", expectedErr, err)
 	}
 }
+
+func TestSecBundleListOnlyAvailable(t *testing.T) {
+	// setting package variables by hand, there be dragons
+	secBundleFmt = "list"
+	defer func() {
+		secBundleFmt = "zip"
+	}()
+	msg, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{
+			Bundle: astraops.CredsURL{
+				DownloadURL:         "https://external",
+				DownloadURLInternal: astraops.StringPtr("https://internal"),
+			},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "\n\t\texternal bundle: https://external\n\t\tinternal bundle: https://internal\n\t\t"
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
+
+func TestSecBundleZipUnavailableType(t *testing.T) {
+	secBundleFmt = "zip"
+	secBundleDownloadType = "proxy-internal"
+	defer func() {
+		secBundleDownloadType = "external"
+	}()
+	_, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{
+			Bundle: astraops.CredsURL{
+				DownloadURL:         "https://external",
+				DownloadURLInternal: astraops.StringPtr("https://internal"),
+			},
+		}, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "download type proxy-internal is not available for 'abc', available types are: external, internal"
+	if err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err.Error())
+	}
+}
+
+func TestSecBundleZipInvalidType(t *testing.T) {
+	secBundleFmt = "zip"
+	secBundleDownloadType = "private"
+	defer func() {
+		secBundleDownloadType = "external"
+	}()
+	_, err := executeSecBundle([]string{"abc"}, func() (pkg.Client, error) {
+		return &tests.MockClient{Bundle: astraops.CredsURL{DownloadURL: "https://external"}}, nil
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid download type private") {
+		t.Errorf("unexpected error '%v'", err)
+	}
+}
@@ -63,32 +63,14 @@ func executeTiers(login func() (pkg.Client, error)) (string, error) {
 		var rows [][]string
 		rows = append(rows, []string{"name", "cloud", "region", "db (used)/(limit)", "cap (used)/(limit)", "cost per month", "cost per minute"})
 		for _, tier := range tiers {
-			var costMonthRaw float64
-			if tier.Cost.CostPerMonthCents != nil {
-				costMonthRaw = *tier.Cost.CostPerMonthCents
-			}
-			var costMinRaw float64
-			if tier.Cost.CostPerMinCents != nil {
-				costMinRaw = *tier.Cost.CostPerMinCents
-			}
-
-			divisor := 100.0
-			var costMonth float64
-			if costMonthRaw > 0.0 {
-				costMonth = costMonthRaw / divisor
-			}
-			var costMin float64
-			if costMinRaw > 0.0 {
-				costMin = costMinRaw / divisor
-			}
 			rows = append(rows, []string{
 				string(tier.Tier),
 				string(tier.CloudProvider),
 				tier.Region,
 				fmt.Sprintf("%v/%v", tier.DatabaseCountUsed, tier.DatabaseCountLimit),
 				fmt.Sprintf("%v/%v", tier.CapacityUnitsUsed, tier.CapacityUnitsLimit),
-				fmt.Sprintf("$%.2f", costMonth),
-				fmt.Sprintf("$%.2f", costMin)})
+				formatCost(tier.Cost.CostPerMonthCents),
+				formatCost(tier.Cost.CostPerMinCents)})
 		}
 		var buf bytes.Buffer
 		err = pkg.WriteRows(&buf, rows)
@@ -106,3 +88,13 @@ func executeTiers(login func() (pkg.Client, error)) (string, error) {
 		return "", fmt.Errorf("-o %q is not valid option", tiersFmt)
 	}
 }
+
+// formatCost turns the cents reported by the DevOps API into dollars
+func formatCost(cents *float64) string {
+	var cost float64
+	if cents != nil && *cents > 0.0 {
+		divisor := 100.0
+		cost = *cents / divisor
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -20,15 +20,21 @@ import (
 	"os"
 
 	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
 	"github.com/spf13/cobra"
 )
 
+func init() {
+	addBulkFlags(UnparkCmd)
+}
+
 // UnparkCmd provides unparking support for classic database tiers in Astra
 var UnparkCmd = &cobra.Command{
-	Use:   "unpark <id>",
+	Use:   "unpark <id>...",
 	Short: "parks the database specified, does not work with serverless",
-	Long:  `parks the database specified, only works on classic tier databases and can take a very long time to park (20-30 minutes)`,
-	Args:  cobra.ExactArgs(1),
+	Long:  `parks the database specified by ID or by --selector, only works on classic tier databases and can take a very long time to park (20-30 minutes)`,
+	Args:  bulkArgs(0),
 	Run: func(cobraCmd *cobra.Command, args []string) {
 		creds := &pkg.Creds{}
 		err := executeUnpark(args, creds.Login)
@@ -46,11 +52,31 @@ func executeUnpark(args []string, makeClient func() (pkg.Client, error)) error {
 	if err != nil {
 		return fmt.Errorf("unable to login with error %v", err)
 	}
+	if isBulk(args) {
+		msg, err := executeBulk(client, args, bulkOperation{
+			name:   "unpark",
+			done:   "unparked",
+			exempt: true,
+			run: func(client pkg.Client, db astraops.Database) error {
+				return client.Unpark(db.Id)
+			},
+		})
+		if msg != "" {
+			fmt.Println(msg)
+		}
+		return err
+	}
 	id := args[0]
-	fmt.Printf("starting to unpark database %v\n", id)
+	if !env.DryRun {
+		fmt.Printf("starting to unpark database %v\n", id)
+	}
 	if err := client.Unpark(id); err != nil {
 		return fmt.Errorf("unable to unpark '%s' with error %v", id, err)
 	}
+	if env.DryRun {
+		fmt.Printf("dry run, would unpark database %v\n", id)
+		return nil
+	}
 	fmt.Printf("database %v unparked\n", id)
 	return nil
 }
@@ -0,0 +1,51 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/cmd/doc"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	docCmd.AddCommand(doc.CollectionCmd)
+	docCmd.AddCommand(doc.GetCmd)
+	docCmd.AddCommand(doc.PutCmd)
+	docCmd.AddCommand(doc.PatchCmd)
+	docCmd.AddCommand(doc.DeleteCmd)
+	docCmd.AddCommand(doc.SearchCmd)
+}
+
+var docCmd = &cobra.Command{
+	Use:   "doc",
+	Short: "Shows all the doc commands",
+	Long:  `Shows all the doc commands. Store and search json documents of your databases through the Stargate Document API with your token`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeDoc(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeDoc(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
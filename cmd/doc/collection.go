@@ -0,0 +1,48 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CollectionCmd.AddCommand(CollectionListCmd)
+	CollectionCmd.AddCommand(CollectionCreateCmd)
+	CollectionCmd.AddCommand(CollectionDeleteCmd)
+}
+
+// CollectionCmd is the parent command for the collections of a namespace
+var CollectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Shows all the collection commands",
+	Long:  `Shows all the collection commands. List, create and delete the collections of json documents of a namespace`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if err := executeCollection(cobraCmd.Usage); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func executeCollection(usage func() error) error {
+	if err := usage(); err != nil {
+		return fmt.Errorf("warn unable to show usage %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addNamespaceFlag(CollectionCreateCmd)
+}
+
+// CollectionCreateCmd creates a collection
+var CollectionCreateCmd = &cobra.Command{
+	Use:   "create <id|name> <collection>",
+	Short: "creates a collection",
+	Long:  `creates an empty collection of json documents in the namespace`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeCollectionCreate(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeCollectionCreate(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	name := args[1]
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(http.MethodPost, client.URL(stargate.CollectionsPath(namespace)), []byte(fmt.Sprintf(`{"name":%q}`, name))), nil
+	}
+	if err := client.CreateCollection(namespace, name); err != nil {
+		return "", fmt.Errorf("unable to create collection %v.%v with error %v", namespace, name, err)
+	}
+	return fmt.Sprintf("collection %v.%v created", namespace, name), nil
+}
@@ -0,0 +1,63 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addNamespaceFlag(CollectionDeleteCmd)
+}
+
+// CollectionDeleteCmd deletes a collection
+var CollectionDeleteCmd = &cobra.Command{
+	Use:   "delete <id|name> <collection>",
+	Short: "deletes a collection",
+	Long:  `deletes the collection and every document in it`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeCollectionDelete(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeCollectionDelete(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	name := args[1]
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(http.MethodDelete, client.URL(stargate.CollectionsPath(namespace, name)), nil), nil
+	}
+	if err := client.DeleteCollection(namespace, name); err != nil {
+		return "", fmt.Errorf("unable to delete collection %v.%v with error %v", namespace, name, err)
+	}
+	return fmt.Sprintf("collection %v.%v deleted", namespace, name), nil
+}
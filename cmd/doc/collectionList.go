@@ -0,0 +1,81 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+var collectionListFmt string
+
+func init() {
+	addNamespaceFlag(CollectionListCmd)
+	CollectionListCmd.Flags().StringVarP(&collectionListFmt, "output", "o", pkg.TextFormat, "Output format for report default is text, can also be json")
+}
+
+// CollectionListCmd lists the collections of a namespace
+var CollectionListCmd = &cobra.Command{
+	Use:   "list <id|name>",
+	Short: "lists the collections of a namespace",
+	Long:  `lists the collections of the namespace, the keyspace of the database unless -n is passed`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeCollectionList(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeCollectionList(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	if collectionListFmt != pkg.TextFormat && collectionListFmt != pkg.JSONFormat {
+		return "", fmt.Errorf("-o %q is not valid option", collectionListFmt)
+	}
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	collections, err := client.ListCollections(namespace)
+	if err != nil {
+		return "", fmt.Errorf("unable to list collections of %v with error %v", namespace, err)
+	}
+	if collectionListFmt == pkg.JSONFormat {
+		b, err := json.MarshalIndent(collections, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		return string(b), nil
+	}
+	rows := [][]string{{"name", "upgrade available"}}
+	for _, c := range collections {
+		rows = append(rows, []string{c.Name, strconv.FormatBool(c.UpgradeAvailable)})
+	}
+	var out bytes.Buffer
+	if err := pkg.WriteRows(&out, rows); err != nil {
+		return "", fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	return out.String(), nil
+}
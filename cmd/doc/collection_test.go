@@ -0,0 +1,147 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+)
+
+// docCommand runs one of the doc commands with the client
+type docCommand func(args []string, makeClient func() (pkg.Client, error)) (string, error)
+
+const collections = `{"data":[{"name":"cars","upgradeAvailable":false},{"name":"people","upgradeAvailable":true}]}`
+
+func TestCollection(t *testing.T) {
+	cases := []struct {
+		name     string
+		run      docCommand
+		args     []string
+		status   int
+		response string
+		setup    func()
+		expected string
+		call     docCall
+	}{
+		{
+			name: "create", run: executeCollectionCreate, args: []string{"abc", "cars"},
+			status:   http.StatusCreated,
+			expected: "collection ns1.cars created",
+			call:     docCall{Method: http.MethodPost, Path: "/api/rest/v2/namespaces/ns1/collections", Token: "AstraCS:secret", Body: `{"name":"cars"}`},
+		},
+		{
+			name: "delete", run: executeCollectionDelete, args: []string{"abc", "cars"},
+			status:   http.StatusNoContent,
+			expected: "collection ns1.cars deleted",
+			call:     docCall{Method: http.MethodDelete, Path: "/api/rest/v2/namespaces/ns1/collections/cars", Token: "AstraCS:secret"},
+		},
+		{
+			name: "list", run: executeCollectionList, args: []string{"abc"},
+			status: http.StatusOK, response: collections,
+			expected: "name   upgrade available\ncars   false\npeople true",
+			call:     docCall{Method: http.MethodGet, Path: "/api/rest/v2/namespaces/ns1/collections", Token: "AstraCS:secret"},
+		},
+		{
+			name: "list json", run: executeCollectionList, args: []string{"abc"},
+			status: http.StatusOK, response: `{"data":[{"name":"cars"}]}`,
+			setup:    func() { collectionListFmt = pkg.JSONFormat },
+			expected: "[\n  {\n    \"name\": \"cars\",\n    \"upgradeAvailable\": false\n  }\n]",
+			call:     docCall{Method: http.MethodGet, Path: "/api/rest/v2/namespaces/ns1/collections", Token: "AstraCS:secret"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withDoc(t, answer(c.status, c.response))
+			if c.setup != nil {
+				c.setup()
+			}
+			out, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if out != c.expected {
+				t.Errorf("expected %q but was %q", c.expected, out)
+			}
+			if len(*calls) != 1 || (*calls)[0] != c.call {
+				t.Errorf("expected call %+v but was %+v", c.call, *calls)
+			}
+		})
+	}
+}
+
+func TestCollectionDryRun(t *testing.T) {
+	cases := []struct {
+		name   string
+		run    docCommand
+		prefix string
+		suffix string
+	}{
+		{"create", executeCollectionCreate, "POST ", `/namespaces/ns1/collections {"name":"cars"}`},
+		{"delete", executeCollectionDelete, "DELETE ", "/namespaces/ns1/collections/cars"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withDoc(t, answer(http.StatusOK, ""))
+			env.DryRun = true
+			t.Cleanup(func() {
+				env.DryRun = false
+			})
+			out, err := c.run([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !strings.HasPrefix(out, "dry run, request not sent: "+c.prefix) || !strings.HasSuffix(out, c.suffix) {
+				t.Errorf("unexpected output %q", out)
+			}
+			if len(*calls) != 0 {
+				t.Errorf("expected no calls but was %v", *calls)
+			}
+		})
+	}
+}
+
+func TestCollectionFails(t *testing.T) {
+	cases := []struct {
+		name     string
+		run      docCommand
+		args     []string
+		status   int
+		response string
+		setup    func()
+		expected string
+	}{
+		{"create", executeCollectionCreate, []string{"abc", "cars"}, http.StatusConflict, `{"description":"already exists"}`, nil, "unable to create collection ns1.cars with error already exists (status 409)"},
+		{"delete", executeCollectionDelete, []string{"abc", "cars"}, http.StatusNotFound, `{"description":"collection not found"}`, nil, "unable to delete collection ns1.cars with error collection not found (status 404)"},
+		{"list", executeCollectionList, []string{"abc"}, http.StatusNotFound, `{"description":"unknown namespace"}`, nil, "unable to list collections of ns1 with error unknown namespace (status 404)"},
+		{"list invalid format", executeCollectionList, []string{"abc"}, http.StatusOK, collections, func() { collectionListFmt = "yaml" }, `-o "yaml" is not valid option`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, mockClient := withDoc(t, answer(c.status, c.response))
+			if c.setup != nil {
+				c.setup()
+			}
+			_, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("expected '%v' but was '%v'", c.expected, err)
+			}
+		})
+	}
+}
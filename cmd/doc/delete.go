@@ -0,0 +1,63 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addNamespaceFlag(DeleteCmd)
+}
+
+// DeleteCmd deletes a document
+var DeleteCmd = &cobra.Command{
+	Use:   "delete <id|name> <collection> <document id>",
+	Short: "deletes a document",
+	Long:  `deletes the document of the id from the collection`,
+	Args:  cobra.ExactArgs(3),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeDelete(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeDelete(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	collection, id := args[1], args[2]
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(http.MethodDelete, client.URL(stargate.DocumentPath(namespace, collection, id)), nil), nil
+	}
+	if err := client.DeleteDocument(namespace, collection, id); err != nil {
+		return "", fmt.Errorf("unable to delete document %v of %v.%v with error %v", id, namespace, collection, err)
+	}
+	return fmt.Sprintf("document %v deleted", id), nil
+}
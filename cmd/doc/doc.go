@@ -0,0 +1,117 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+var docNamespace string
+var docData string
+var docFile string
+
+// stdin is where -f - reads the document from, tests replace it
+var stdin io.Reader = os.Stdin
+
+// storedToken is the application token of astra-cli login, the Document API does not take service accounts
+var storedToken = func() (string, error) {
+	creds := &pkg.Creds{}
+	token, _, err := creds.Stored()
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", errors.New("the doc commands need a token, login with astra-cli login --token")
+	}
+	return token, nil
+}
+
+// addNamespaceFlag adds the namespace of the collections
+func addNamespaceFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&docNamespace, "namespace", "n", "", "namespace of the collections, the keyspace of the database by default")
+}
+
+// addDocumentFlags adds the json document of the commands storing one
+func addDocumentFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&docData, "data", "", "document as a json object")
+	cmd.Flags().StringVarP(&docFile, "file", "f", "", "file of the json document, - reads stdin")
+}
+
+// openNamespace looks up the database and returns a client of its Document API with the namespace to use
+func openNamespace(idOrName string, makeClient func() (pkg.Client, error)) (*stargate.Client, string, error) {
+	token, err := storedToken()
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := makeClient()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to login with error %v", err)
+	}
+	db, err := pkg.ResolveDb(client, idOrName)
+	if err != nil {
+		return nil, "", err
+	}
+	baseURL, err := stargate.BaseURL(db, pkg.Env)
+	if err != nil {
+		return nil, "", err
+	}
+	namespace := docNamespace
+	if namespace == "" && db.Info.Keyspace != nil {
+		namespace = *db.Info.Keyspace
+	}
+	if namespace == "" {
+		return nil, "", fmt.Errorf("database '%s' has no keyspace, pass a namespace with -n", idOrName)
+	}
+	return stargate.NewClient(baseURL, token), namespace, nil
+}
+
+// readDocument returns the json object of --data or of the file of -f
+func readDocument() ([]byte, error) {
+	var doc []byte
+	switch {
+	case docData != "" && docFile != "":
+		return nil, errors.New("pass either --data or -f, not both")
+	case docData != "":
+		doc = []byte(docData)
+	case docFile == "-":
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read stdin with error %v", err)
+		}
+		doc = b
+	case docFile != "":
+		b, err := os.ReadFile(docFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read '%v' with error %v", docFile, err)
+		}
+		doc = b
+	default:
+		return nil, errors.New("pass the document with --data or -f")
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(doc, &object); err != nil {
+		return nil, fmt.Errorf("document is not a json object with error %v", err)
+	}
+	return doc, nil
+}
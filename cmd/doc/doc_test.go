@@ -0,0 +1,338 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// docCall is a request received by the stand-in Document API
+type docCall struct {
+	Method string
+	Path   string
+	Query  string
+	Token  string
+	Body   string
+}
+
+// withDoc serves the Document API of the database from a stand-in answering every request with the status and
+// response of answer
+func withDoc(t *testing.T, answer func(docCall) (int, string)) (*[]docCall, *tests.MockClient) {
+	// setting package variables by hand, there be dragons
+	var calls []docCall
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body %v", err)
+		}
+		call := docCall{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get(stargate.TokenHeader), string(body)}
+		calls = append(calls, call)
+		status, response := answer(call)
+		w.WriteHeader(status)
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Errorf("unable to write response %v", err)
+		}
+	}))
+	endpoint := ts.URL + "/api/rest"
+	keyspace := "ns1"
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{{
+			Id:              "abc",
+			Info:            astraops.DatabaseInfo{Keyspace: &keyspace},
+			DataEndpointUrl: &endpoint,
+		}},
+	}
+	originalToken := storedToken
+	storedToken = func() (string, error) {
+		return "AstraCS:secret", nil
+	}
+	originalStdin := stdin
+	t.Cleanup(func() {
+		ts.Close()
+		storedToken = originalToken
+		stdin = originalStdin
+		docNamespace = ""
+		docData = ""
+		docFile = ""
+		collectionListFmt = pkg.TextFormat
+		searchWhere = ""
+		searchFields = ""
+		searchPageSize = stargate.MaxDocumentPageSize
+		searchPageState = ""
+		searchLimit = 0
+		searchFmt = pkg.TextFormat
+	})
+	return &calls, mockClient
+}
+
+// answer replies to every request with the same status and response
+func answer(status int, response string) func(docCall) (int, string) {
+	return func(docCall) (int, string) {
+		return status, response
+	}
+}
+
+func TestOpenNamespaceUsesDatabaseKeyspace(t *testing.T) {
+	_, mockClient := withDoc(t, answer(http.StatusOK, ""))
+	_, namespace, err := openNamespace("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if namespace != "ns1" {
+		t.Errorf("expected ns1 but was %v", namespace)
+	}
+}
+
+func TestOpenNamespaceFlag(t *testing.T) {
+	_, mockClient := withDoc(t, answer(http.StatusOK, ""))
+	docNamespace = "other"
+	_, namespace, err := openNamespace("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if namespace != "other" {
+		t.Errorf("expected other but was %v", namespace)
+	}
+}
+
+func TestOpenNamespaceNoKeyspace(t *testing.T) {
+	_, mockClient := withDoc(t, answer(http.StatusOK, ""))
+	mockClient.Databases[0].Info.Keyspace = nil
+	_, _, err := openNamespace("abc", func() (pkg.Client, error) { return mockClient, nil })
+	expected := "database 'abc' has no keyspace, pass a namespace with -n"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestOpenNamespaceNoToken(t *testing.T) {
+	_, mockClient := withDoc(t, answer(http.StatusOK, ""))
+	storedToken = func() (string, error) {
+		return "", errors.New("no token")
+	}
+	_, _, err := openNamespace("abc", func() (pkg.Client, error) { return mockClient, nil })
+	if err == nil || err.Error() != "no token" {
+		t.Errorf("expected no token but was '%v'", err)
+	}
+}
+
+func TestOpenNamespaceLoginFails(t *testing.T) {
+	withDoc(t, answer(http.StatusOK, ""))
+	_, _, err := openNamespace("abc", func() (pkg.Client, error) { return nil, errors.New("bad creds") })
+	expected := "unable to login with error bad creds"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestReadDocumentFromFile(t *testing.T) {
+	withDoc(t, answer(http.StatusOK, ""))
+	docFile = path.Join(t.TempDir(), "car.json")
+	if err := os.WriteFile(docFile, []byte(`{"make":"vw"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := readDocument()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(doc) != `{"make":"vw"}` {
+		t.Errorf("unexpected document %s", doc)
+	}
+}
+
+func TestReadDocumentFromStdin(t *testing.T) {
+	withDoc(t, answer(http.StatusOK, ""))
+	docFile = "-"
+	stdin = strings.NewReader(`{"make":"fiat"}`)
+	doc, err := readDocument()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(doc) != `{"make":"fiat"}` {
+		t.Errorf("unexpected document %s", doc)
+	}
+}
+
+func TestReadDocumentErrors(t *testing.T) {
+	withDoc(t, answer(http.StatusOK, ""))
+	if _, err := readDocument(); err == nil || err.Error() != "pass the document with --data or -f" {
+		t.Errorf("expected missing document error but was '%v'", err)
+	}
+	docData = `{}`
+	docFile = "car.json"
+	if _, err := readDocument(); err == nil || err.Error() != "pass either --data or -f, not both" {
+		t.Errorf("expected both error but was '%v'", err)
+	}
+	docFile = ""
+	docData = `"vw"`
+	if _, err := readDocument(); err == nil || !strings.HasPrefix(err.Error(), "document is not a json object") {
+		t.Errorf("expected json error but was '%v'", err)
+	}
+	docData = ""
+	docFile = path.Join(t.TempDir(), "missing.json")
+	if _, err := readDocument(); err == nil || !strings.HasPrefix(err.Error(), "unable to read") {
+		t.Errorf("expected read error but was '%v'", err)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	cases := []struct {
+		name     string
+		run      docCommand
+		args     []string
+		status   int
+		response string
+		setup    func()
+		expected string
+		call     docCall
+	}{
+		{
+			name: "get", run: executeGet, args: []string{"abc", "cars", "1"},
+			status: http.StatusOK, response: `{"make":"vw","engine":{"size":2}}`,
+			expected: "{\n  \"make\": \"vw\",\n  \"engine\": {\n    \"size\": 2\n  }\n}",
+			call:     docCall{Method: http.MethodGet, Path: "/api/rest/v2/namespaces/ns1/collections/cars/1", Query: "raw=true", Token: "AstraCS:secret"},
+		},
+		{
+			name: "get path", run: executeGet, args: []string{"abc", "cars", "1", "engine", "size"},
+			status: http.StatusOK, response: `2`,
+			expected: "2",
+			call:     docCall{Method: http.MethodGet, Path: "/api/rest/v2/namespaces/ns1/collections/cars/1/engine/size", Query: "raw=true", Token: "AstraCS:secret"},
+		},
+		{
+			name: "put adds", run: executePut, args: []string{"abc", "cars"},
+			status: http.StatusCreated, response: `{"documentId":"generated"}`,
+			setup:    func() { docData = `{"make":"vw"}` },
+			expected: "document generated added",
+			call:     docCall{Method: http.MethodPost, Path: "/api/rest/v2/namespaces/ns1/collections/cars", Token: "AstraCS:secret", Body: `{"make":"vw"}`},
+		},
+		{
+			name: "put replaces from stdin", run: executePut, args: []string{"abc", "cars", "1"},
+			status: http.StatusOK, response: `{"documentId":"1"}`,
+			setup: func() {
+				docFile = "-"
+				stdin = strings.NewReader(`{"make":"fiat"}`)
+			},
+			expected: "document 1 stored",
+			call:     docCall{Method: http.MethodPut, Path: "/api/rest/v2/namespaces/ns1/collections/cars/1", Token: "AstraCS:secret", Body: `{"make":"fiat"}`},
+		},
+		{
+			name: "patch", run: executePatch, args: []string{"abc", "cars", "1"},
+			status: http.StatusOK, response: `{"documentId":"1"}`,
+			setup:    func() { docData = `{"color":"red"}` },
+			expected: "document 1 patched",
+			call:     docCall{Method: http.MethodPatch, Path: "/api/rest/v2/namespaces/ns1/collections/cars/1", Token: "AstraCS:secret", Body: `{"color":"red"}`},
+		},
+		{
+			name: "delete", run: executeDelete, args: []string{"abc", "cars", "1"},
+			status:   http.StatusNoContent,
+			expected: "document 1 deleted",
+			call:     docCall{Method: http.MethodDelete, Path: "/api/rest/v2/namespaces/ns1/collections/cars/1", Token: "AstraCS:secret"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withDoc(t, answer(c.status, c.response))
+			if c.setup != nil {
+				c.setup()
+			}
+			out, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if out != c.expected {
+				t.Errorf("expected %q but was %q", c.expected, out)
+			}
+			if len(*calls) != 1 || (*calls)[0] != c.call {
+				t.Errorf("expected call %+v but was %+v", c.call, *calls)
+			}
+		})
+	}
+}
+
+func TestDocumentsDryRun(t *testing.T) {
+	cases := []struct {
+		name   string
+		run    docCommand
+		data   string
+		prefix string
+		suffix string
+	}{
+		{"put", executePut, `{"make":"vw"}`, "PUT ", `/collections/cars/1 {"make":"vw"}`},
+		{"patch", executePatch, `{"color":"red"}`, "PATCH ", `/collections/cars/1 {"color":"red"}`},
+		{"delete", executeDelete, "", "DELETE ", "/collections/cars/1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls, mockClient := withDoc(t, answer(http.StatusOK, ""))
+			env.DryRun = true
+			t.Cleanup(func() {
+				env.DryRun = false
+			})
+			docData = c.data
+			out, err := c.run([]string{"abc", "cars", "1"}, func() (pkg.Client, error) { return mockClient, nil })
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !strings.HasPrefix(out, "dry run, request not sent: "+c.prefix) || !strings.HasSuffix(out, c.suffix) {
+				t.Errorf("unexpected output %q", out)
+			}
+			if len(*calls) != 0 {
+				t.Errorf("expected no calls but was %v", *calls)
+			}
+		})
+	}
+}
+
+func TestDocumentsFails(t *testing.T) {
+	cases := []struct {
+		name     string
+		run      docCommand
+		args     []string
+		data     string
+		status   int
+		response string
+		expected string
+	}{
+		{"get", executeGet, []string{"abc", "cars", "1"}, "", http.StatusNotFound, `{"description":"document not found"}`, "unable to get document 1 of ns1.cars with error document not found (status 404)"},
+		{"put", executePut, []string{"abc", "cars"}, `{"make":"vw"}`, http.StatusBadRequest, `{"description":"bad document"}`, "unable to add document to ns1.cars with error bad document (status 400)"},
+		{"patch", executePatch, []string{"abc", "cars", "1"}, `{"color":"red"}`, http.StatusNotFound, `{"description":"document not found"}`, "unable to patch document 1 in ns1.cars with error document not found (status 404)"},
+		{"patch without document", executePatch, []string{"abc", "cars", "1"}, "", http.StatusOK, "", "pass the document with --data or -f"},
+		{"delete", executeDelete, []string{"abc", "cars", "1"}, "", http.StatusUnauthorized, `{"description":"invalid token"}`, "unable to delete document 1 of ns1.cars with error invalid token (status 401)"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, mockClient := withDoc(t, answer(c.status, c.response))
+			docData = c.data
+			_, err := c.run(c.args, func() (pkg.Client, error) { return mockClient, nil })
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("expected '%v' but was '%v'", c.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addNamespaceFlag(GetCmd)
+}
+
+// GetCmd prints a document
+var GetCmd = &cobra.Command{
+	Use:   "get <id|name> <collection> <document id> [path...]",
+	Short: "gets a document",
+	Long:  `prints the json document of the id, or the part of it under the path, for example get mydb cars 1 engine size`,
+	Args:  cobra.MinimumNArgs(3),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executeGet(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executeGet(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	collection, id := args[1], args[2]
+	doc, err := client.GetDocument(namespace, collection, id, args[3:])
+	if err != nil {
+		return "", fmt.Errorf("unable to get document %v of %v.%v with error %v", id, namespace, collection, err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, doc, "", "  "); err != nil {
+		return "", fmt.Errorf("unable to format document with error %v", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,80 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addNamespaceFlag(PutCmd)
+	addDocumentFlags(PutCmd)
+}
+
+// PutCmd stores a document
+var PutCmd = &cobra.Command{
+	Use:   "put <id|name> <collection> [document id] --data '{json}'",
+	Short: "adds or replaces a document",
+	Long: `without a document id adds the document under a generated id and prints it. With a document id stores the document
+under it, replacing the previous one. The document is passed with --data or read from the file of -f, - reads stdin`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		out, err := executePut(args, creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func executePut(args []string, makeClient func() (pkg.Client, error)) (string, error) {
+	doc, err := readDocument()
+	if err != nil {
+		return "", err
+	}
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return "", err
+	}
+	collection := args[1]
+	if len(args) == 2 {
+		if env.DryRun {
+			return "dry run, request not sent: " + stargate.Request(http.MethodPost, client.URL(stargate.CollectionsPath(namespace, collection)), doc), nil
+		}
+		id, err := client.AddDocument(namespace, collection, doc)
+		if err != nil {
+			return "", fmt.Errorf("unable to add document to %v.%v with error %v", namespace, collection, err)
+		}
+		return fmt.Sprintf("document %v added", id), nil
+	}
+	id := args[2]
+	if env.DryRun {
+		return "dry run, request not sent: " + stargate.Request(http.MethodPut, client.URL(stargate.DocumentPath(namespace, collection, id)), doc), nil
+	}
+	if err := client.ReplaceDocument(namespace, collection, id, doc); err != nil {
+		return "", fmt.Errorf("unable to put document %v in %v.%v with error %v", id, namespace, collection, err)
+	}
+	return fmt.Sprintf("document %v stored", id), nil
+}
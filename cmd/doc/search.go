@@ -0,0 +1,161 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/stargate"
+	"github.com/spf13/cobra"
+)
+
+var searchWhere string
+var searchFields string
+var searchPageSize int
+var searchPageState string
+var searchLimit int
+var searchFmt string
+
+func init() {
+	addNamespaceFlag(SearchCmd)
+	SearchCmd.Flags().StringVarP(&searchWhere, "where", "w", "", `where clause as json, for example {"make":{"$eq":"vw"}}, every document without one`)
+	SearchCmd.Flags().StringVar(&searchFields, "fields", "", "comma separated fields to return, the whole document by default")
+	SearchCmd.Flags().IntVar(&searchPageSize, "page-size", stargate.MaxDocumentPageSize, "documents fetched per request, at most 20")
+	SearchCmd.Flags().StringVar(&searchPageState, "page-state", "", "page to start from, all pages are fetched from there")
+	SearchCmd.Flags().IntVar(&searchLimit, "limit", 0, "most documents to return, all of them when 0")
+	SearchCmd.Flags().StringVarP(&searchFmt, "output", "o", pkg.TextFormat, "Output format for report default is text, can also be json or jsonl")
+}
+
+// SearchCmd prints the documents of a collection matching a where clause
+var SearchCmd = &cobra.Command{
+	Use:   "search <id|name> <collection>",
+	Short: "searches the documents of a collection",
+	Long: `prints the documents of the collection matching the json where clause, following the pages of the Document API until
+every document or --limit documents are printed. -o jsonl prints a document per line as each page arrives, for large collections`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		if err := executeSearch(args, creds.Login, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// document is a document with its id as printed by search
+type document struct {
+	DocumentID string          `json:"documentId"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func executeSearch(args []string, makeClient func() (pkg.Client, error), out io.Writer) error {
+	if searchFmt != pkg.TextFormat && searchFmt != pkg.JSONFormat && searchFmt != pkg.JSONLinesFormat {
+		return fmt.Errorf("-o %q is not valid option", searchFmt)
+	}
+	if searchPageSize < 1 || searchPageSize > stargate.MaxDocumentPageSize {
+		return fmt.Errorf("--page-size has to be between 1 and %v", stargate.MaxDocumentPageSize)
+	}
+	client, namespace, err := openNamespace(args[0], makeClient)
+	if err != nil {
+		return err
+	}
+	collection := args[1]
+	var docs []document
+	err = searchDocuments(client, namespace, collection, func(doc document) error {
+		if searchFmt != pkg.JSONLinesFormat {
+			docs = append(docs, doc)
+			return nil
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("unexpected error marshaling to json: '%v'", err)
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to search %v.%v with error %v", namespace, collection, err)
+	}
+	switch searchFmt {
+	case pkg.JSONFormat:
+		b, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unexpected error marshaling to json: '%v', Try -output text instead", err)
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case pkg.TextFormat:
+		return writeDocuments(out, docs)
+	}
+	return nil
+}
+
+// searchDocuments passes every matching document to emit, a page at a time, until the last page or the limit
+func searchDocuments(client *stargate.Client, namespace, collection string, emit func(document) error) error {
+	opts := stargate.SearchOptions{Where: searchWhere, PageSize: searchPageSize, PageState: searchPageState}
+	for _, f := range strings.Split(searchFields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			opts.Fields = append(opts.Fields, f)
+		}
+	}
+	emitted := 0
+	for {
+		page, err := client.SearchDocuments(namespace, collection, opts)
+		if err != nil {
+			return err
+		}
+		for _, id := range page.IDs() {
+			if searchLimit > 0 && emitted == searchLimit {
+				return nil
+			}
+			if err := emit(document{DocumentID: id, Data: page.Data[id]}); err != nil {
+				return err
+			}
+			emitted++
+		}
+		if page.PageState == "" || (searchLimit > 0 && emitted == searchLimit) {
+			return nil
+		}
+		opts.PageState = page.PageState
+	}
+}
+
+// writeDocuments prints a row per document with the document as compact json
+func writeDocuments(out io.Writer, docs []document) error {
+	if len(docs) == 0 {
+		_, err := fmt.Fprintln(out, "no documents found")
+		return err
+	}
+	rows := [][]string{{"id", "document"}}
+	for _, doc := range docs {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, doc.Data); err != nil {
+			return fmt.Errorf("unable to format document %v with error %v", doc.DocumentID, err)
+		}
+		rows = append(rows, []string{doc.DocumentID, compact.String()})
+	}
+	if err := pkg.WriteRows(out, rows); err != nil {
+		return fmt.Errorf("unexpected error writing text output %v", err)
+	}
+	_, err := fmt.Fprintln(out)
+	return err
+}
@@ -0,0 +1,139 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package doc provides the sub-commands for the doc command
+package doc
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+)
+
+// pages answers searches with two pages of documents
+func pages(call docCall) (int, string) {
+	query, err := url.ParseQuery(call.Query)
+	if err != nil {
+		return http.StatusBadRequest, `{"description":"bad query"}`
+	}
+	if query.Get("page-state") == "p2" {
+		return http.StatusOK, `{"data":{"c":{"make":"audi"}}}`
+	}
+	return http.StatusOK, `{"pageState":"p2","data":{"b":{"make":"vw"},"a":{"make": "fiat"}}}`
+}
+
+func TestSearchFollowsPages(t *testing.T) {
+	calls, mockClient := withDoc(t, pages)
+	searchWhere = `{"make":{"$ne":"bmw"}}`
+	var out bytes.Buffer
+	if err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "id document\na  {\"make\":\"fiat\"}\nb  {\"make\":\"vw\"}\nc  {\"make\":\"audi\"}\n"
+	if out.String() != expected {
+		t.Errorf("expected %q but was %q", expected, out.String())
+	}
+	if len(*calls) != 2 {
+		t.Fatalf("expected 2 calls but was %v", len(*calls))
+	}
+	query, err := url.ParseQuery((*calls)[1].Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("where") != searchWhere || query.Get("page-size") != "20" || query.Get("page-state") != "p2" {
+		t.Errorf("unexpected query %v", (*calls)[1].Query)
+	}
+}
+
+func TestSearchJSONLines(t *testing.T) {
+	_, mockClient := withDoc(t, pages)
+	searchFmt = pkg.JSONLinesFormat
+	var out bytes.Buffer
+	if err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `{"documentId":"a","data":{"make":"fiat"}}
+{"documentId":"b","data":{"make":"vw"}}
+{"documentId":"c","data":{"make":"audi"}}
+`
+	if out.String() != expected {
+		t.Errorf("expected %q but was %q", expected, out.String())
+	}
+}
+
+func TestSearchJSON(t *testing.T) {
+	_, mockClient := withDoc(t, pages)
+	searchFmt = pkg.JSONFormat
+	searchLimit = 1
+	var out bytes.Buffer
+	if err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "[\n  {\n    \"documentId\": \"a\",\n    \"data\": {\n      \"make\": \"fiat\"\n    }\n  }\n]\n"
+	if out.String() != expected {
+		t.Errorf("expected %q but was %q", expected, out.String())
+	}
+}
+
+func TestSearchLimitStopsPaging(t *testing.T) {
+	calls, mockClient := withDoc(t, pages)
+	searchLimit = 2
+	var out bytes.Buffer
+	if err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("expected 1 call but was %v", len(*calls))
+	}
+}
+
+func TestSearchNoDocuments(t *testing.T) {
+	_, mockClient := withDoc(t, answer(http.StatusOK, `{"data":{}}`))
+	var out bytes.Buffer
+	if err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &out); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out.String() != "no documents found\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestSearchInvalidOptions(t *testing.T) {
+	_, mockClient := withDoc(t, pages)
+	searchFmt = "yaml"
+	err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	expected := `-o "yaml" is not valid option`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	searchFmt = pkg.TextFormat
+	searchPageSize = 50
+	err = executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	expected = "--page-size has to be between 1 and 20"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestSearchFails(t *testing.T) {
+	_, mockClient := withDoc(t, answer(http.StatusBadRequest, `{"description":"bad where"}`))
+	err := executeSearch([]string{"abc", "cars"}, func() (pkg.Client, error) { return mockClient, nil }, &bytes.Buffer{})
+	expected := "unable to search ns1.cars with error bad where (status 400)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
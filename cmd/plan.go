@@ -0,0 +1,102 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var planFile string
+var planPrune bool
+
+func init() {
+	planCmd.Flags().StringVarP(&planFile, "file", "f", "", "spec file in yaml or json describing the databases, - reads from stdin")
+	planCmd.Flags().BoolVar(&planPrune, "prune", false, "also plan the removal of databases and regions that are not in the spec")
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan -f <spec>",
+	Short: "Shows the changes needed for the databases to match the spec",
+	Long:  `Compares the databases described in the spec file with the existing databases and shows the creates, keyspace additions, resizes and region changes that apply would make`,
+	Args:  cobra.NoArgs,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		creds := &pkg.Creds{}
+		msg, err := executePlan(creds.Login)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(msg)
+	},
+}
+
+func executePlan(makeClient func() (pkg.Client, error)) (string, error) {
+	plan, err := makePlan(planFile, planPrune, makeClient)
+	if err != nil {
+		return "", err
+	}
+	return formatPlan(plan), nil
+}
+
+// makePlan reads the spec and compares it with the databases of the account
+func makePlan(file string, prune bool, makeClient func() (pkg.Client, error)) (spec.Plan, error) {
+	if file == "" {
+		return spec.Plan{}, fmt.Errorf("a spec file is required, use -f")
+	}
+	s, err := spec.ReadFile(file)
+	if err != nil {
+		return spec.Plan{}, err
+	}
+	client, err := makeClient()
+	if err != nil {
+		return spec.Plan{}, fmt.Errorf("unable to login with error %v", err)
+	}
+	dbs, err := client.ListDb("", "", "", pkg.MaxListLimit)
+	if err != nil {
+		return spec.Plan{}, fmt.Errorf("unable to get list of dbs with error '%v'", err)
+	}
+	return spec.NewPlan(s, dbs, prune)
+}
+
+// formatPlan lists the actions, + adds, ~ changes and - removes
+func formatPlan(plan spec.Plan) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("plan: %v change(s), %v database(s) unchanged", len(plan.Actions), len(plan.Unchanged)))
+	for _, a := range plan.Actions {
+		symbol := "+"
+		switch a.Type {
+		case spec.ActionResize:
+			symbol = "~"
+		case spec.ActionRemoveRegion, spec.ActionDelete:
+			symbol = "-"
+		case spec.ActionCreate, spec.ActionAddKeyspace, spec.ActionAddRegion:
+		}
+		lines = append(lines, fmt.Sprintf("  %v %v", symbol, a.Description()))
+	}
+	if len(plan.Unchanged) > 0 {
+		lines = append(lines, fmt.Sprintf("unchanged: %v", strings.Join(plan.Unchanged, ", ")))
+	}
+	for _, w := range plan.Warnings {
+		lines = append(lines, fmt.Sprintf("warning: %v", w))
+	}
+	return strings.Join(lines, "\n")
+}
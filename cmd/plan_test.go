@@ -0,0 +1,81 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cmd contains all fo the commands for the cli
+package cmd
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+func writeSpec(t *testing.T, content string) string {
+	f := path.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(f, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+const testSpec = `
+databases:
+  - name: app
+    keyspaces: [ks1]
+    regions: [us-east1]
+  - name: new
+    keyspaces: [ks1]
+    regions: [us-east1]
+`
+
+func TestPlan(t *testing.T) {
+	planFile = writeSpec(t, testSpec)
+	planPrune = true
+	defer func() {
+		planPrune = false
+	}()
+	mockClient := &tests.MockClient{
+		Databases: []astraops.Database{
+			{Id: "abc", Status: astraops.StatusEnumACTIVE, Info: astraops.DatabaseInfo{Name: astraops.StringPtr("app"), Keyspace: astraops.StringPtr("ks1"), Region: astraops.StringPtr("us-east1")}},
+			{Id: "def", Status: astraops.StatusEnumACTIVE, Info: astraops.DatabaseInfo{Name: astraops.StringPtr("old"), Keyspace: astraops.StringPtr("ks1"), Region: astraops.StringPtr("us-east1")}},
+		},
+	}
+	msg, err := executePlan(func() (pkg.Client, error) {
+		return mockClient, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `plan: 2 change(s), 1 database(s) unchanged
+  + create database new (serverless GCP us-east1, keyspace ks1)
+  - delete database old (def)
+unchanged: app`
+	if msg != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, msg)
+	}
+}
+
+func TestPlanMissingFile(t *testing.T) {
+	planFile = ""
+	_, err := executePlan(func() (pkg.Client, error) {
+		return &tests.MockClient{}, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
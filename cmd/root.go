@@ -27,8 +27,14 @@ import (
 func init() {
 	RootCmd.PersistentFlags().BoolVarP(&env.Verbose, "verbose", "v", false, "turns on verbose logging")
 	RootCmd.PersistentFlags().StringVarP(&pkg.Env, "env", "e", "prod", "environment to automate, other options are test and dev")
+	RootCmd.PersistentFlags().BoolVar(&env.DryRun, "dry-run", false, "resolve and validate the target, print the requests that would change something and exit without sending them")
 	RootCmd.AddCommand(loginCmd)
 	RootCmd.AddCommand(dbCmd)
+	RootCmd.AddCommand(dataCmd)
+	RootCmd.AddCommand(docCmd)
+	RootCmd.AddCommand(planCmd)
+	RootCmd.AddCommand(applyCmd)
+	RootCmd.AddCommand(auditCmd)
 }
 
 // RootCmd is the entry point for the whole app
@@ -37,6 +43,14 @@ var RootCmd = &cobra.Command{
 	Short: "An easy to use client for automating DataStax Astra",
 	Long: `Manage and provision databases on DataStax Astra
                 Complete documentation is available at https://github.com/datastax-labs/astra-cli`,
+	PersistentPreRun: func(cobraCmd *cobra.Command, args []string) {
+		env.Command = cobraCmd.CommandPath()
+	},
+	PersistentPostRun: func(cobraCmd *cobra.Command, args []string) {
+		if env.DryRun {
+			fmt.Fprintln(os.Stderr, "dry run: no changes were made")
+		}
+	},
 	Run: func(cobraCmd *cobra.Command, args []string) {
 		if err := executeRoot(cobraCmd.Usage); err != nil {
 			os.Exit(1)
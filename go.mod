@@ -4,11 +4,16 @@ go 1.17
 
 require (
 	github.com/datastax/astra-client-go/v2 v2.2.12
+	github.com/gocql/gocql v1.7.0
 	github.com/spf13/cobra v1.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/deepmap/oapi-codegen v1.9.1 // indirect
+	github.com/golang/snappy v0.0.3 // indirect
+	github.com/hailocab/go-hostpool v0.0.0-20160125115350-e80d13ce29ed // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
 )
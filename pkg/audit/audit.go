@@ -0,0 +1,193 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package audit keeps a local JSON lines log of the operations that change databases
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// FileName is the audit log in the config directory
+	FileName = "audit.log"
+	// DefaultMaxSize is the size in bytes after which the log is rotated
+	DefaultMaxSize = 10 * 1024 * 1024
+	// DefaultMaxBackups is how many rotated logs are kept, as audit.log.1 (newest) to audit.log.3 (oldest)
+	DefaultMaxBackups = 3
+)
+
+const (
+	// OutcomeSuccess is recorded when the call returned no error
+	OutcomeSuccess = "success"
+	// OutcomeFailure is recorded when the call returned an error
+	OutcomeFailure = "failure"
+)
+
+// Record is one mutating call
+type Record struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Env        string    `json:"env"`
+	Command    string    `json:"command"`
+	Operation  string    `json:"operation"`
+	Target     string    `json:"target"`
+	Details    string    `json:"details,omitempty"`
+	Outcome    string    `json:"outcome"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Log appends records to a file and rotates it once it grows over MaxSize, it is safe for concurrent use
+type Log struct {
+	Path       string
+	MaxSize    int64
+	MaxBackups int
+	// mu keeps the size check, the rotation and the write together, bulk commands append from several goroutines
+	mu sync.Mutex
+}
+
+// NewLog is the log in the config directory with the default rotation
+func NewLog(confDir string) *Log {
+	return &Log{
+		Path:       filepath.Join(confDir, FileName),
+		MaxSize:    DefaultMaxSize,
+		MaxBackups: DefaultMaxBackups,
+	}
+}
+
+// Append writes the record as one line, rotating first if the line would push the file over MaxSize
+func (l *Log) Append(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("unable to encode audit record with error '%v'", err)
+	}
+	line = append(line, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0700); err != nil {
+		return fmt.Errorf("unable to create directory for audit log with error '%v'", err)
+	}
+	if fi, err := os.Stat(l.Path); err == nil && l.MaxSize > 0 && fi.Size()+int64(len(line)) > l.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open audit log '%v' with error '%v'", l.Path, err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write audit log '%v' with error '%v'", l.Path, err)
+	}
+	return f.Close()
+}
+
+// rotate shifts audit.log.N to audit.log.N+1, dropping the oldest, and moves the current log to audit.log.1
+func (l *Log) rotate() error {
+	if l.MaxBackups < 1 {
+		if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to truncate audit log '%v' with error '%v'", l.Path, err)
+		}
+		return nil
+	}
+	for i := l.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to rotate audit log '%v' with error '%v'", l.backup(i), err)
+		}
+	}
+	if err := os.Rename(l.Path, l.backup(1)); err != nil {
+		return fmt.Errorf("unable to rotate audit log '%v' with error '%v'", l.Path, err)
+	}
+	return nil
+}
+
+func (l *Log) backup(i int) string {
+	return fmt.Sprintf("%v.%v", l.Path, i)
+}
+
+// Read returns the records at or after since from the rotated logs and the current one, oldest first,
+// lines that do not parse, like one truncated by a crash mid write, are skipped and described in the warnings
+func (l *Log) Read(since time.Time) ([]Record, []string, error) {
+	var files []string
+	for i := l.MaxBackups; i >= 1; i-- {
+		files = append(files, l.backup(i))
+	}
+	files = append(files, l.Path)
+	var records []Record
+	var warnings []string
+	for _, file := range files {
+		fileRecords, fileWarnings, err := readFile(file, since)
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, fileRecords...)
+		warnings = append(warnings, fileWarnings...)
+	}
+	return records, warnings, nil
+}
+
+func readFile(file string, since time.Time) ([]Record, []string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("unable to open audit log '%v' with error '%v'", file, err)
+	}
+	defer f.Close()
+	var records []Record
+	var warnings []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped line %v of audit log '%v' that is not a valid record with error '%v'", lineNumber, file, err))
+			continue
+		}
+		if r.Time.Before(since) {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("unable to read audit log '%v' with error '%v'", file, err)
+	}
+	return records, warnings, nil
+}
+
+// CurrentUser is the OS user running the command, it falls back to $USER when the user database is unavailable
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
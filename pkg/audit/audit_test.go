@@ -0,0 +1,182 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package audit keeps a local JSON lines log of the operations that change databases
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	l := NewLog(t.TempDir())
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err := l.Append(Record{Time: start.Add(time.Duration(i) * time.Hour), User: "jdoe", Operation: "Park", Target: "abc", Outcome: OutcomeSuccess})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	records, _, err := l.Read(start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records but was %v", len(records))
+	}
+	if !records[0].Time.Equal(start.Add(time.Hour)) || records[0].User != "jdoe" {
+		t.Errorf("unexpected record %v", records[0])
+	}
+	fi, err := os.Stat(l.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected the audit log to be 0600 but was %v", fi.Mode().Perm())
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	l := &Log{Path: filepath.Join(dir, FileName), MaxSize: 200, MaxBackups: 2}
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	total := 20
+	for i := 0; i < total; i++ {
+		if err := l.Append(Record{Time: start.Add(time.Duration(i) * time.Minute), Operation: "Terminate", Target: "abc", Outcome: OutcomeSuccess}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	for _, f := range []string{l.Path, l.Path + ".1", l.Path + ".2"} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			t.Fatalf("expected '%v' to exist with error %v", f, err)
+		}
+		if fi.Size() > l.MaxSize {
+			t.Errorf("expected '%v' to be at most %v bytes but was %v", f, l.MaxSize, fi.Size())
+		}
+	}
+	if _, err := os.Stat(l.Path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+	records, _, err := l.Read(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) == 0 || len(records) >= total {
+		t.Fatalf("expected the oldest records to be dropped but was %v records", len(records))
+	}
+	for i := 1; i < len(records); i++ {
+		if !records[i-1].Time.Before(records[i].Time) {
+			t.Errorf("expected records oldest first but %v came before %v", records[i-1].Time, records[i].Time)
+		}
+	}
+	if !records[len(records)-1].Time.Equal(start.Add(time.Duration(total-1) * time.Minute)) {
+		t.Errorf("expected the newest record last but was %v", records[len(records)-1].Time)
+	}
+}
+
+func TestConcurrentAppend(t *testing.T) {
+	dir := t.TempDir()
+	// room for every record across the backups so none are dropped
+	l := &Log{Path: filepath.Join(dir, FileName), MaxSize: 400, MaxBackups: 50}
+	total := 100
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, total)
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs <- l.Append(Record{Time: time.Now(), Operation: "Terminate", Target: fmt.Sprintf("db-%02d", i), Outcome: OutcomeSuccess})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	records, _, err := l.Read(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, r := range records {
+		seen[r.Target] = true
+	}
+	if len(records) != total || len(seen) != total {
+		t.Errorf("expected %v distinct records but was %v records for %v targets", total, len(records), len(seen))
+	}
+	for i := 1; i <= l.MaxBackups; i++ {
+		fi, err := os.Stat(l.backup(i))
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() > l.MaxSize {
+			t.Errorf("expected '%v' to be at most %v bytes but was %v", l.backup(i), l.MaxSize, fi.Size())
+		}
+	}
+}
+
+func TestReadMissingLog(t *testing.T) {
+	records, _, err := NewLog(t.TempDir()).Read(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records but was %v", records)
+	}
+}
+
+func TestReadCorruptLog(t *testing.T) {
+	l := NewLog(t.TempDir())
+	if err := l.Append(Record{User: "jdoe", Operation: "Park", Target: "abc", Outcome: OutcomeSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{not json\n{\"user\":\"trunc"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	records, warnings, err := l.Read(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 1 || records[0].User != "jdoe" {
+		t.Errorf("expected the good record to be kept but was %v", records)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("expected a warning for each bad line but was %v", warnings)
+	}
+	for i, line := range []string{"line 2 ", "line 3 "} {
+		if !strings.Contains(warnings[i], line) {
+			t.Errorf("expected warning '%v' to name %v", warnings[i], line)
+		}
+	}
+}
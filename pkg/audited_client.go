@@ -0,0 +1,170 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package pkg is the top level package for shared libraries
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg/audit"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// AuditedClient records every call of the wrapped client that changes something in the audit log.
+// Calls that only read are passed through
+type AuditedClient struct {
+	client  Client
+	log     *audit.Log
+	user    string
+	env     string
+	command string
+	warn    io.Writer
+	now     func() time.Time
+}
+
+// NewAuditedClient wraps the client, failures to write the log are reported to warn and never fail the call
+func NewAuditedClient(client Client, log *audit.Log, env, command string, warn io.Writer) *AuditedClient {
+	return &AuditedClient{
+		client:  client,
+		log:     log,
+		user:    audit.CurrentUser(),
+		env:     env,
+		command: command,
+		warn:    warn,
+		now:     time.Now,
+	}
+}
+
+// record appends the outcome of the operation on target to the log
+func (a *AuditedClient) record(operation, target, details string, start time.Time, err error) {
+	r := audit.Record{
+		Time:       start,
+		User:       a.user,
+		Env:        a.env,
+		Command:    a.command,
+		Operation:  operation,
+		Target:     target,
+		Details:    details,
+		Outcome:    audit.OutcomeSuccess,
+		DurationMs: a.now().Sub(start).Milliseconds(),
+	}
+	if err != nil {
+		r.Outcome = audit.OutcomeFailure
+		r.Error = err.Error()
+	}
+	if logErr := a.log.Append(r); logErr != nil {
+		fmt.Fprintf(a.warn, "warning: unable to write audit log with error %v\n", logErr)
+	}
+}
+
+// CreateDb records the name of the database created, the id is added to the details when known
+func (a *AuditedClient) CreateDb(createDb astraops.DatabaseInfoCreate) (astraops.Database, error) {
+	start := a.now()
+	db, err := a.client.CreateDb(createDb)
+	details := fmt.Sprintf("tier=%v cloudProvider=%v region=%v capacityUnits=%v", createDb.Tier, createDb.CloudProvider, createDb.Region, createDb.CapacityUnits)
+	if db.Id != "" {
+		details = "id=" + db.Id + " " + details
+	}
+	a.record("CreateDb", createDb.Name, details, start, err)
+	return db, err
+}
+
+// Terminate records the database terminated
+func (a *AuditedClient) Terminate(id string, preparedStateOnly bool) error {
+	start := a.now()
+	err := a.client.Terminate(id, preparedStateOnly)
+	a.record("Terminate", id, "", start, err)
+	return err
+}
+
+// FindDb is not recorded
+func (a *AuditedClient) FindDb(id string) (astraops.Database, error) {
+	return a.client.FindDb(id)
+}
+
+// ListDb is not recorded
+func (a *AuditedClient) ListDb(include string, provider string, startingAfter string, limit int) ([]astraops.Database, error) {
+	return a.client.ListDb(include, provider, startingAfter, limit)
+}
+
+// Park records the database parked
+func (a *AuditedClient) Park(id string) error {
+	start := a.now()
+	err := a.client.Park(id)
+	a.record("Park", id, "", start, err)
+	return err
+}
+
+// Unpark records the database unparked
+func (a *AuditedClient) Unpark(id string) error {
+	start := a.now()
+	err := a.client.Unpark(id)
+	a.record("Unpark", id, "", start, err)
+	return err
+}
+
+// Resize records the database resized and the capacity units requested
+func (a *AuditedClient) Resize(id string, capacityUnits int) error {
+	start := a.now()
+	err := a.client.Resize(id, capacityUnits)
+	a.record("Resize", id, fmt.Sprintf("capacityUnits=%v", capacityUnits), start, err)
+	return err
+}
+
+// GetSecureBundle is not recorded
+func (a *AuditedClient) GetSecureBundle(id string) (astraops.CredsURL, error) {
+	return a.client.GetSecureBundle(id)
+}
+
+// GetTierInfo is not recorded
+func (a *AuditedClient) GetTierInfo() ([]astraops.AvailableRegionCombination, error) {
+	return a.client.GetTierInfo()
+}
+
+// ListDatacenters is not recorded
+func (a *AuditedClient) ListDatacenters(id string, all bool) ([]astraops.Datacenter, error) {
+	return a.client.ListDatacenters(id, all)
+}
+
+// AddDatacenters records the database and the regions added
+func (a *AuditedClient) AddDatacenters(id string, dcs []astraops.Datacenter) error {
+	start := a.now()
+	err := a.client.AddDatacenters(id, dcs)
+	var regions []string
+	for _, dc := range dcs {
+		regions = append(regions, dc.Region)
+	}
+	a.record("AddDatacenters", id, "regions="+strings.Join(regions, ","), start, err)
+	return err
+}
+
+// TerminateDatacenter records the database and the datacenter removed
+func (a *AuditedClient) TerminateDatacenter(id string, datacenterID string) error {
+	start := a.now()
+	err := a.client.TerminateDatacenter(id, datacenterID)
+	a.record("TerminateDatacenter", id, "datacenter="+datacenterID, start, err)
+	return err
+}
+
+// AddKeyspaceToDb records the database and the keyspace added
+func (a *AuditedClient) AddKeyspaceToDb(id string, keyspace string) error {
+	start := a.now()
+	err := a.client.AddKeyspaceToDb(id, keyspace)
+	a.record("AddKeyspaceToDb", id, "keyspace="+keyspace, start, err)
+	return err
+}
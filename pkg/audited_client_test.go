@@ -0,0 +1,99 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package pkg is the top level package for shared libraries
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg/audit"
+	astraops "github.com/datastax/astra-client-go/v2/astra"
+)
+
+// failingClient fails every call that changes something and returns nothing for reads
+type failingClient struct {
+	Client
+	err error
+}
+
+func (f *failingClient) Park(id string) error {
+	return f.err
+}
+
+func (f *failingClient) Resize(id string, size int) error {
+	return f.err
+}
+
+func (f *failingClient) FindDb(id string) (astraops.Database, error) {
+	return astraops.Database{Id: id}, nil
+}
+
+func TestAuditedClientRecords(t *testing.T) {
+	log := audit.NewLog(t.TempDir())
+	var warn bytes.Buffer
+	client := NewAuditedClient(&failingClient{}, log, "dev", "astra-cli db park", &warn)
+	tick := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	client.now = func() time.Time {
+		tick = tick.Add(time.Second)
+		return tick
+	}
+	if err := client.Park("abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	client.client = &failingClient{err: errors.New("not classic")}
+	if err := client.Resize("abc", 3); err == nil {
+		t.Fatal("expected the error of the client")
+	}
+	if _, err := client.FindDb("abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	records, _, err := log.Read(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected the park and the resize to be recorded but was %v", records)
+	}
+	park := records[0]
+	if park.Operation != "Park" || park.Target != "abc" || park.Outcome != audit.OutcomeSuccess || park.Env != "dev" || park.Command != "astra-cli db park" {
+		t.Errorf("unexpected park record %v", park)
+	}
+	if park.DurationMs != 1000 {
+		t.Errorf("expected a duration of 1000ms but was %v", park.DurationMs)
+	}
+	resize := records[1]
+	if resize.Outcome != audit.OutcomeFailure || resize.Error != "not classic" || resize.Details != "capacityUnits=3" {
+		t.Errorf("unexpected resize record %v", resize)
+	}
+	if warn.Len() != 0 {
+		t.Errorf("unexpected warning '%v'", warn.String())
+	}
+}
+
+func TestAuditedClientLogFailureDoesNotFailCall(t *testing.T) {
+	dir := t.TempDir()
+	log := &audit.Log{Path: dir, MaxSize: audit.DefaultMaxSize}
+	var warn bytes.Buffer
+	client := NewAuditedClient(&failingClient{}, log, "prod", "astra-cli db park", &warn)
+	if err := client.Park("abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if warn.Len() == 0 {
+		t.Error("expected a warning when the audit log cannot be written")
+	}
+}
@@ -18,7 +18,9 @@ package pkg
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -77,10 +79,11 @@ func FormatErrors(es []Error) string {
 // AuthenticatedClient has a token and the methods to query the Astra DevOps API
 type AuthenticatedClient struct {
 	token          string
-	client         *http.Client
+	client         astra.HttpRequestDoer
 	astraclient    *astra.ClientWithResponses
 	timeoutSeconds int
 	verbose        bool
+	dryRun         bool
 }
 
 func newHTTPClient() *http.Client {
@@ -179,6 +182,35 @@ func Authenticate(clientInfo ClientInfo, verbose bool) (*AuthenticatedClient, er
 	return authenticatedClient, nil
 }
 
+// EnableDryRun makes the client print every request that would change something instead of sending it. The mutating
+// methods return without waiting since nothing was changed
+func (a *AuthenticatedClient) EnableDryRun(out io.Writer) error {
+	doer := &dryRunDoer{
+		doer: a.client,
+		out:  out,
+		resolve: func(idOrName string) (astra.Database, error) {
+			return ResolveDb(a, idOrName)
+		},
+		validate: func(create astra.DatabaseInfoCreate) error {
+			return ValidateCreate(a, create)
+		},
+	}
+	astraClient, err := astra.NewClientWithResponses(apiURL(), astra.WithHTTPClient(doer), func(c *astra.Client) error {
+		c.RequestEditors = append(c.RequestEditors, func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Authorization", a.token)
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unexpected error setting up devops api client for dry run: %v", err)
+	}
+	a.astraclient = astraClient
+	a.client = doer
+	a.dryRun = true
+	return nil
+}
+
 func apiURL() string {
 	if env.Verbose {
 		log.Printf("env is %v", Env)
@@ -300,6 +332,9 @@ func (a *AuthenticatedClient) CreateDb(createDb astra.DatabaseInfoCreate) (astra
 	ctx, cancel := a.ctx()
 	defer cancel()
 	response, err := a.astraclient.CreateDatabaseWithResponse(ctx, astra.CreateDatabaseJSONRequestBody(createDb))
+	if errors.Is(err, ErrDryRun) {
+		return astra.Database{}, nil
+	}
 	if err != nil {
 		return astra.Database{}, err
 	}
@@ -341,6 +376,9 @@ func (a *AuthenticatedClient) AddKeyspaceToDb(databaseID string, keyspaceName st
 	ctx, cancel := a.ctx()
 	defer cancel()
 	res, err := a.astraclient.AddKeyspaceWithResponse(ctx, astra.DatabaseIdParam(databaseID), astra.KeyspaceNameParam(keyspaceName))
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed creating request to add keyspace to db with id %s with: %w", databaseID, err)
 	}
@@ -378,6 +416,9 @@ func (a *AuthenticatedClient) Terminate(id string, preparedStateOnly bool) error
 	res, err := a.astraclient.TerminateDatabaseWithResponse(ctx, astra.DatabaseIdParam(id), &astra.TerminateDatabaseParams{
 		PreparedStateOnly: &preparedStateOnly,
 	})
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -400,6 +441,9 @@ func (a *AuthenticatedClient) ParkAsync(databaseID string) error {
 	}
 	a.setHeaders(req)
 	res, err := a.client.Do(req)
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to park database id %s with: %w", databaseID, err)
 	}
@@ -423,6 +467,9 @@ func (a *AuthenticatedClient) Park(databaseID string) error {
 	if err != nil {
 		return fmt.Errorf("park db failed because '%v'", err)
 	}
+	if a.dryRun {
+		return nil
+	}
 	tries := 30
 	interval := 30
 	_, err = a.WaitUntil(databaseID, tries, interval, astra.StatusEnumPARKED)
@@ -442,6 +489,9 @@ func (a *AuthenticatedClient) UnparkAsync(databaseID string) error {
 	}
 	a.setHeaders(req)
 	res, err := a.client.Do(req)
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to unpark database id %s with: %w", databaseID, err)
 	}
@@ -465,6 +515,9 @@ func (a *AuthenticatedClient) Unpark(databaseID string) error {
 	if err != nil {
 		return fmt.Errorf("unpark db failed because '%v'", err)
 	}
+	if a.dryRun {
+		return nil
+	}
 	tries := 60
 	interval := 30
 	_, err = a.WaitUntil(databaseID, tries, interval, astra.StatusEnumACTIVE)
@@ -484,6 +537,9 @@ func (a *AuthenticatedClient) Resize(databaseID string, capacityUnits int) error
 	res, err := a.astraclient.ResizeDatabaseWithResponse(ctx, astra.DatabaseIdParam(databaseID), astra.ResizeDatabaseJSONRequestBody{
 		CapacityUnits: &capacityUnits,
 	})
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to resize database for database id %s with: %w", databaseID, err)
 	}
@@ -505,6 +561,9 @@ func (a *AuthenticatedClient) ResetPassword(databaseID, username, password strin
 		Username: astra.StringPtr(username),
 		Password: astra.StringPtr(password),
 	})
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to reset password for database id %s with: %w", databaseID, err)
 	}
@@ -515,6 +574,140 @@ func (a *AuthenticatedClient) ResetPassword(databaseID, username, password strin
 	return nil
 }
 
+// ListDatacenters returns the datacenters configured for the database
+// * @param databaseID string representation of the database ID
+// * @param all bool when true datacenters in the TERMINATED state are also returned
+// @return ([]Datacenter, error)
+func (a *AuthenticatedClient) ListDatacenters(databaseID string, all bool) ([]astra.Datacenter, error) {
+	ctx, cancel := a.ctx()
+	defer cancel()
+	res, err := a.astraclient.ListDatacentersWithResponse(ctx, astra.DatabaseIdParam(databaseID), &astra.ListDatacentersParams{
+		All: &all,
+	})
+	if err != nil {
+		return []astra.Datacenter{}, fmt.Errorf("failed listing datacenters for database id %s with: %w", databaseID, err)
+	}
+	if res.StatusCode() != http.StatusOK {
+		return []astra.Datacenter{}, handleErrors(res.Body, res.Status())
+	}
+	return *res.JSON200, nil
+}
+
+// AddDatacenters adds the datacenters to the database and will block until every new datacenter is active
+// * @param databaseID string representation of the database ID
+// * @param datacenters the datacenters to add, region, cloud provider and tier are required
+// @return error
+func (a *AuthenticatedClient) AddDatacenters(databaseID string, datacenters []astra.Datacenter) error {
+	ctx, cancel := a.ctx()
+	defer cancel()
+	res, err := a.astraclient.AddDatacentersWithResponse(ctx, astra.DatabaseIdParam(databaseID), astra.AddDatacentersJSONRequestBody(datacenters))
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add datacenters to database id %s with: %w", databaseID, err)
+	}
+	if res.StatusCode() != http.StatusCreated {
+		return handleErrors(res.Body, res.Status())
+	}
+	tries := 90
+	interval := 30
+	for _, dc := range datacenters {
+		if _, err := a.WaitUntilDatacenter(databaseID, dc.Region, tries, interval, astra.StatusEnumACTIVE); err != nil {
+			return fmt.Errorf("waiting for status check on add datacenter failed because '%v'", err)
+		}
+	}
+	return nil
+}
+
+// TerminateDatacenter removes the datacenter from the database and will block until it shows up as terminated
+// * @param databaseID string representation of the database ID
+// * @param datacenterID string representation of the datacenter ID
+// @return error
+func (a *AuthenticatedClient) TerminateDatacenter(databaseID, datacenterID string) error {
+	ctx, cancel := a.ctx()
+	defer cancel()
+	res, err := a.astraclient.TerminateDatacenterWithResponse(ctx, astra.DatabaseIdParam(databaseID), astra.DatacenterIdParam(datacenterID))
+	if errors.Is(err, ErrDryRun) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to terminate datacenter %s for database id %s with: %w", datacenterID, databaseID, err)
+	}
+	if res.StatusCode() != http.StatusAccepted {
+		return handleErrors(res.Body, res.Status())
+	}
+	tries := 60
+	interval := 30
+	_, err = a.WaitUntilDatacenter(databaseID, datacenterID, tries, interval, astra.StatusEnumTERMINATED)
+	return err
+}
+
+// WaitUntilDatacenter will keep checking the datacenters of the database until the one matching the key is in the requested status.
+// Unlike WaitUntil this looks at the status of the single datacenter and not the status of the whole database.
+// * @param databaseID string - the database id the datacenter belongs to
+// * @param key string - the datacenter id or region to find
+// * @param tries int - number of attempts
+// * @param intervalSeconds int - seconds to wait between tries
+// * @param status StatusEnum - status to wait for
+// @returns (Datacenter, error)
+func (a *AuthenticatedClient) WaitUntilDatacenter(databaseID, key string, tries int, intervalSeconds int, status ...astra.StatusEnum) (astra.Datacenter, error) {
+	for i := 0; i < tries; i++ {
+		time.Sleep(time.Duration(intervalSeconds) * time.Second)
+		dcs, err := a.ListDatacenters(databaseID, true)
+		if err != nil {
+			if a.verbose {
+				log.Printf("datacenters for db %s not able to be listed with error '%v' trying again %v more times", databaseID, err, tries-i-1)
+			} else {
+				fmt.Print(".")
+			}
+			continue
+		}
+		dc, found := FindDatacenter(dcs, key)
+		if !found {
+			if a.verbose {
+				log.Printf("datacenter %s not yet present in db %s trying again %v more times", key, databaseID, tries-i-1)
+			} else {
+				fmt.Print(".")
+			}
+			continue
+		}
+		if dc.Status == string(astra.StatusEnumERROR) {
+			return dc, fmt.Errorf("datacenter %v of database %v in error status, exiting", key, databaseID)
+		}
+		var statusStrings []string
+		for _, s := range status {
+			if dc.Status == string(s) {
+				return dc, nil
+			}
+			statusStrings = append(statusStrings, string(s))
+		}
+		if a.verbose {
+			log.Printf("datacenter %s of db %s in state %v but expected %v trying again %v more times", key, databaseID, dc.Status, strings.Join(statusStrings, ", "), tries-i-1)
+		} else {
+			fmt.Print(".")
+		}
+	}
+	return astra.Datacenter{}, fmt.Errorf("unable to find datacenter %s of db id %s with status %s after %v seconds", key, databaseID, status, intervalSeconds*tries)
+}
+
+// FindDatacenter returns the first datacenter that has an id or a region matching the key. When there is more than one datacenter
+// in a region, the one that is not terminated wins
+func FindDatacenter(dcs []astra.Datacenter, key string) (astra.Datacenter, bool) {
+	var match astra.Datacenter
+	var found bool
+	for _, dc := range dcs {
+		if dc.Id != nil && *dc.Id == key {
+			return dc, true
+		}
+		if dc.Region == key && (!found || match.Status == string(astra.StatusEnumTERMINATED)) {
+			match = dc
+			found = true
+		}
+	}
+	return match, found
+}
+
 // GetTierInfo Returns all supported tier, cloud, region, count, and capacitity combinations
 // @return ([]TierInfo, error)
 func (a *AuthenticatedClient) GetTierInfo() ([]astra.AvailableRegionCombination, error) {
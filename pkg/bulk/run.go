@@ -0,0 +1,94 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package bulk selects databases and runs an operation on many of them concurrently
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+// Result is the outcome of the operation on one database
+type Result struct {
+	ID       string
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Failed is a result for a database the operation was never attempted on
+func Failed(id string, err error) Result {
+	return Result{ID: id, Err: err}
+}
+
+// Run calls op for every database with at most parallel calls in flight. Results are in the order of dbs
+func Run(dbs []astra.Database, parallel int, op func(astra.Database) error) []Result {
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]Result, len(dbs))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, db := range dbs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, db astra.Database) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			err := op(db)
+			results[i] = Result{ID: db.Id, Name: Name(db), Err: err, Duration: time.Since(start)}
+		}(i, db)
+	}
+	wg.Wait()
+	return results
+}
+
+// Name is the name of the database or empty when it has none
+func Name(db astra.Database) string {
+	if db.Info.Name == nil {
+		return ""
+	}
+	return *db.Info.Name
+}
+
+// CountFailed is the number of results with an error
+func CountFailed(results []Result) int {
+	var failed int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// WriteResults writes one row per database with the verb, for example deleted, on success or the error
+func WriteResults(w io.Writer, results []Result, verb string) error {
+	rows := [][]string{{"id", "name", "result", "duration"}}
+	for _, r := range results {
+		result := verb
+		if r.Err != nil {
+			result = fmt.Sprintf("failed: %v", r.Err)
+		}
+		rows = append(rows, []string{r.ID, r.Name, result, r.Duration.Round(time.Second).String()})
+	}
+	return pkg.WriteRows(w, rows)
+}
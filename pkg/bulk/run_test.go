@@ -0,0 +1,81 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package bulk selects databases and runs an operation on many of them concurrently
+package bulk
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestRunKeepsOrderAndLimitsParallelism(t *testing.T) {
+	var dbs []astra.Database
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		dbs = append(dbs, astra.Database{Id: id})
+	}
+	var mu sync.Mutex
+	var running, maxRunning int
+	results := Run(dbs, 2, func(db astra.Database) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if db.Id == "c" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 operations at a time but was %v", maxRunning)
+	}
+	for i, r := range results {
+		if r.ID != dbs[i].Id {
+			t.Errorf("expected result %v to be for '%v' but was '%v'", i, dbs[i].Id, r.ID)
+		}
+	}
+	if CountFailed(results) != 1 || results[2].Err == nil {
+		t.Errorf("expected only c to fail but was %v", results)
+	}
+}
+
+func TestWriteResults(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteResults(&out, []Result{
+		{ID: "a", Name: "one"},
+		Failed("b", errors.New("not found")),
+	}, "parked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but was %v", lines)
+	}
+	if !strings.Contains(lines[1], "parked") || !strings.Contains(lines[2], "failed: not found") {
+		t.Errorf("unexpected results %v", out.String())
+	}
+}
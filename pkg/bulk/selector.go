@@ -0,0 +1,166 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package bulk selects databases and runs an operation on many of them concurrently
+package bulk
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+// Selector picks databases by their attributes, every criteria set has to match
+type Selector struct {
+	Name      string        // glob pattern as understood by path.Match
+	Status    string        // compared ignoring case
+	Region    string        // any region of the database
+	Tier      string        // compared ignoring case
+	OlderThan time.Duration // created more than this long ago
+	NewerThan time.Duration // created less than this long ago
+}
+
+// ParseSelector reads a comma separated list of criteria, for example
+// name=loadtest-*,status=ACTIVE,region=us-east1,tier=serverless,age>24h
+// age accepts Go durations plus a d suffix for days, age>7d selects databases created more than 7 days ago
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	if strings.TrimSpace(s) == "" {
+		return sel, fmt.Errorf("selector is empty, it would select every database")
+	}
+	for _, criteria := range strings.Split(s, ",") {
+		criteria = strings.TrimSpace(criteria)
+		if strings.HasPrefix(criteria, "age>") || strings.HasPrefix(criteria, "age<") {
+			d, err := ParseAge(criteria[len("age>"):])
+			if err != nil {
+				return Selector{}, fmt.Errorf("invalid selector '%v' with error %v", criteria, err)
+			}
+			if criteria[3] == '>' {
+				sel.OlderThan = d
+			} else {
+				sel.NewerThan = d
+			}
+			continue
+		}
+		tokens := strings.SplitN(criteria, "=", 2)
+		if len(tokens) != 2 || tokens[1] == "" {
+			return Selector{}, fmt.Errorf("invalid selector '%v', expected key=value or age>duration", criteria)
+		}
+		key, value := tokens[0], tokens[1]
+		switch key {
+		case "name":
+			if _, err := path.Match(value, ""); err != nil {
+				return Selector{}, fmt.Errorf("invalid name pattern '%v' with error %v", value, err)
+			}
+			sel.Name = value
+		case "status":
+			sel.Status = value
+		case "region":
+			sel.Region = value
+		case "tier":
+			sel.Tier = value
+		default:
+			return Selector{}, fmt.Errorf("unknown selector key '%v', valid keys are name, status, region, tier and age", key)
+		}
+	}
+	return sel, nil
+}
+
+// ParseAge reads a Go duration or a number of days with a d suffix, 7d is 168h
+func ParseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse days '%v'", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Matches is true when the database meets every criteria of the selector at the time now
+func (s Selector) Matches(db astra.Database, now time.Time) bool {
+	if s.Name != "" {
+		if db.Info.Name == nil {
+			return false
+		}
+		if ok, _ := path.Match(s.Name, *db.Info.Name); !ok {
+			return false
+		}
+	}
+	if s.Status != "" && !strings.EqualFold(s.Status, string(db.Status)) {
+		return false
+	}
+	if s.Tier != "" && (db.Info.Tier == nil || !strings.EqualFold(s.Tier, string(*db.Info.Tier))) {
+		return false
+	}
+	if s.Region != "" && !hasRegion(db, s.Region) {
+		return false
+	}
+	if s.OlderThan > 0 || s.NewerThan > 0 {
+		created, ok := CreationTime(db)
+		if !ok {
+			return false
+		}
+		age := now.Sub(created)
+		if s.OlderThan > 0 && age <= s.OlderThan {
+			return false
+		}
+		if s.NewerThan > 0 && age >= s.NewerThan {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the databases matching the selector in their original order
+func (s Selector) Select(dbs []astra.Database, now time.Time) []astra.Database {
+	var selected []astra.Database
+	for _, db := range dbs {
+		if s.Matches(db, now) {
+			selected = append(selected, db)
+		}
+	}
+	return selected
+}
+
+// CreationTime parses the creation time of the database, it is false when missing or invalid
+func CreationTime(db astra.Database) (time.Time, bool) {
+	if db.CreationTime == nil {
+		return time.Time{}, false
+	}
+	created, err := time.Parse(time.RFC3339, *db.CreationTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return created, true
+}
+
+func hasRegion(db astra.Database, region string) bool {
+	if db.Info.Region != nil && *db.Info.Region == region {
+		return true
+	}
+	if db.Info.Datacenters != nil {
+		for _, dc := range *db.Info.Datacenters {
+			if dc.Region == region {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package bulk selects databases and runs an operation on many of them concurrently
+package bulk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+var now = time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+
+func testDb(id, name, status, region, tier string, created time.Time) astra.Database {
+	t := astra.Tier(tier)
+	return astra.Database{
+		Id:           id,
+		Status:       astra.StatusEnum(status),
+		CreationTime: astra.StringPtr(created.Format(time.RFC3339)),
+		Info: astra.DatabaseInfo{
+			Name:   astra.StringPtr(name),
+			Region: astra.StringPtr(region),
+			Tier:   &t,
+			Datacenters: &[]astra.Datacenter{
+				{Region: region},
+				{Region: "eu-west1"},
+			},
+		},
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	sel, err := ParseSelector("name=loadtest-*, status=ACTIVE,region=us-east1,tier=serverless,age>2d,age<30d")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := Selector{
+		Name:      "loadtest-*",
+		Status:    "ACTIVE",
+		Region:    "us-east1",
+		Tier:      "serverless",
+		OlderThan: 48 * time.Hour,
+		NewerThan: 30 * 24 * time.Hour,
+	}
+	if sel != expected {
+		t.Errorf("expected %v but was %v", expected, sel)
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	for _, s := range []string{"", "name", "owner=me", "age>soon", "name=[", "status="} {
+		if _, err := ParseSelector(s); err == nil {
+			t.Errorf("expected an error for '%v'", s)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	dbs := []astra.Database{
+		testDb("1", "loadtest-1", "ACTIVE", "us-east1", "serverless", now.Add(-72*time.Hour)),
+		testDb("2", "loadtest-2", "ACTIVE", "us-east1", "serverless", now.Add(-time.Hour)),
+		testDb("3", "loadtest-3", "PARKED", "us-east1", "serverless", now.Add(-72*time.Hour)),
+		testDb("4", "prod", "ACTIVE", "us-east1", "serverless", now.Add(-72*time.Hour)),
+		testDb("5", "loadtest-5", "active", "us-west2", "SERVERLESS", now.Add(-72*time.Hour)),
+	}
+	sel, err := ParseSelector("name=loadtest-*,status=active,region=eu-west1,tier=serverless,age>24h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected := sel.Select(dbs, now)
+	if len(selected) != 2 || selected[0].Id != "1" || selected[1].Id != "5" {
+		t.Errorf("expected databases 1 and 5 but was %v", selected)
+	}
+	sel, err = ParseSelector("region=us-west2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected := sel.Select(dbs, now); len(selected) != 1 || selected[0].Id != "5" {
+		t.Errorf("expected database 5 but was %v", selected)
+	}
+}
+
+func TestSelectAgeWithoutCreationTime(t *testing.T) {
+	sel := Selector{OlderThan: time.Hour}
+	if sel.Matches(astra.Database{Id: "1"}, now) {
+		t.Error("expected a database without creation time not to match an age selector")
+	}
+}
@@ -0,0 +1,262 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package bundle reads and checks secure connect bundles
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigFile is the file describing how to connect in the bundle
+const ConfigFile = "config.json"
+
+// Config is the content of config.json
+type Config struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port"`
+	CqlPort            int    `json:"cql_port"`
+	Keyspace           string `json:"keyspace"`
+	LocalDC            string `json:"localDC"`
+	CaCertLocation     string `json:"caCertLocation"`
+	KeyLocation        string `json:"keyLocation"`
+	CertLocation       string `json:"certLocation"`
+	KeyStoreLocation   string `json:"keyStoreLocation"`
+	KeyStorePassword   string `json:"keyStorePassword"`
+	TrustStoreLocation string `json:"trustStoreLocation"`
+	TrustStorePassword string `json:"trustStorePassword"`
+	PfxCertPassword    string `json:"pfxCertPassword"`
+}
+
+// Bundle is an opened secure connect bundle
+type Bundle struct {
+	Config Config
+	Files  map[string][]byte
+}
+
+// Certificate describes one certificate found in the bundle
+type Certificate struct {
+	File      string
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	IsCA      bool
+}
+
+// Open reads the bundle at the path
+func Open(file string) (Bundle, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("unable to read bundle '%v' with error '%v'", file, err)
+	}
+	return Read(b)
+}
+
+// Read parses the zip content of a bundle. A bundle without config.json is an error
+func Read(b []byte) (Bundle, error) {
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return Bundle{}, fmt.Errorf("bundle is not a valid zip with error '%v'", err)
+	}
+	bundle := Bundle{Files: make(map[string][]byte)}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return Bundle{}, err
+		}
+		bundle.Files[path.Clean(f.Name)] = content
+	}
+	config, ok := bundle.Files[ConfigFile]
+	if !ok {
+		return Bundle{}, fmt.Errorf("bundle has no %v", ConfigFile)
+	}
+	if err := json.Unmarshal(config, &bundle.Config); err != nil {
+		return Bundle{}, fmt.Errorf("unable to parse %v with error '%v'", ConfigFile, err)
+	}
+	return bundle, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to open '%v' in bundle with error '%v'", f.Name, err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read '%v' in bundle with error '%v'", f.Name, err)
+	}
+	return content, nil
+}
+
+// File returns a file of the bundle by the location used in config.json, ./ca.crt and ca.crt are the same file
+func (b Bundle) File(location string) ([]byte, bool) {
+	if location == "" {
+		return nil, false
+	}
+	content, ok := b.Files[path.Clean(location)]
+	return content, ok
+}
+
+// FileNames are the files of the bundle sorted
+func (b Bundle) FileNames() []string {
+	var names []string
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TLSConfig trusts the CA of the bundle and presents its client certificate
+func (b Bundle) TLSConfig() (*tls.Config, error) {
+	ca, ok := b.File(b.Config.CaCertLocation)
+	if !ok {
+		return nil, fmt.Errorf("caCertLocation '%v' is missing from the bundle", b.Config.CaCertLocation)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate found in '%v'", b.Config.CaCertLocation)
+	}
+	cert, hasCert := b.File(b.Config.CertLocation)
+	key, hasKey := b.File(b.Config.KeyLocation)
+	if !hasCert || !hasKey {
+		return nil, fmt.Errorf("client certificate '%v' or key '%v' is missing from the bundle", b.Config.CertLocation, b.Config.KeyLocation)
+	}
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load client certificate with error '%v'", err)
+	}
+	return &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// Certificates parses every PEM certificate of the CA and client certificate files
+func (b Bundle) Certificates() ([]Certificate, error) {
+	var certs []Certificate
+	for _, location := range []string{b.Config.CaCertLocation, b.Config.CertLocation} {
+		content, ok := b.File(location)
+		if !ok {
+			continue
+		}
+		fileCerts, err := parseCertificates(path.Clean(location), content)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, fileCerts...)
+	}
+	return certs, nil
+}
+
+func parseCertificates(name string, content []byte) ([]Certificate, error) {
+	var certs []Certificate
+	rest := content
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate in '%v' with error '%v'", name, err)
+		}
+		certs = append(certs, Certificate{
+			File:      name,
+			Subject:   c.Subject.String(),
+			Issuer:    c.Issuer.String(),
+			NotBefore: c.NotBefore,
+			NotAfter:  c.NotAfter,
+			IsCA:      c.IsCA,
+		})
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in '%v'", name)
+	}
+	return certs, nil
+}
+
+// Check returns everything wrong with the bundle at the time now: missing settings or files, certificates that are
+// expired or not valid yet and a client key that does not match the client certificate. Empty means the bundle is usable
+func (b Bundle) Check(now time.Time) []string {
+	var problems []string
+	if b.Config.Host == "" {
+		problems = append(problems, "config.json has no host")
+	}
+	if b.Config.Port == 0 {
+		problems = append(problems, "config.json has no port")
+	}
+	if b.Config.Keyspace == "" {
+		problems = append(problems, "config.json has no keyspace")
+	}
+	required := []struct {
+		setting  string
+		location string
+	}{
+		{"caCertLocation", b.Config.CaCertLocation},
+		{"certLocation", b.Config.CertLocation},
+		{"keyLocation", b.Config.KeyLocation},
+	}
+	for _, r := range required {
+		if r.location == "" {
+			problems = append(problems, fmt.Sprintf("config.json has no %v", r.setting))
+			continue
+		}
+		if _, ok := b.File(r.location); !ok {
+			problems = append(problems, fmt.Sprintf("%v '%v' is missing from the bundle", r.setting, r.location))
+		}
+	}
+	certs, err := b.Certificates()
+	if err != nil {
+		return append(problems, err.Error())
+	}
+	for _, c := range certs {
+		if now.After(c.NotAfter) {
+			problems = append(problems, fmt.Sprintf("certificate '%v' in %v expired on %v", c.Subject, c.File, c.NotAfter.Format(time.RFC3339)))
+		}
+		if now.Before(c.NotBefore) {
+			problems = append(problems, fmt.Sprintf("certificate '%v' in %v is not valid before %v", c.Subject, c.File, c.NotBefore.Format(time.RFC3339)))
+		}
+	}
+	cert, hasCert := b.File(b.Config.CertLocation)
+	key, hasKey := b.File(b.Config.KeyLocation)
+	if hasCert && hasKey {
+		if _, err := tls.X509KeyPair(cert, key); err != nil {
+			problems = append(problems, fmt.Sprintf("client key does not match the client certificate with error '%v'", strings.TrimPrefix(err.Error(), "tls: ")))
+		}
+	}
+	return problems
+}
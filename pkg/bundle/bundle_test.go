@@ -0,0 +1,139 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package bundle reads and checks secure connect bundles
+package bundle
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+)
+
+func TestReadValidBundle(t *testing.T) {
+	zip, err := tests.SecureBundle(tests.BundleOptions{Keyspace: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Read(zip)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if b.Config.Keyspace != "app" || b.Config.Port != 29080 || b.Config.CqlPort != 29042 {
+		t.Errorf("unexpected config %v", b.Config)
+	}
+	if _, ok := b.File("./ca.crt"); !ok {
+		t.Error("expected ./ca.crt to resolve to ca.crt")
+	}
+	certs, err := b.Certificates()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(certs) != 2 || !certs[0].IsCA || certs[1].File != "cert" {
+		t.Errorf("expected the CA then the client certificate but was %v", certs)
+	}
+	if problems := b.Check(time.Now()); len(problems) != 0 {
+		t.Errorf("expected no problems but was %v", problems)
+	}
+}
+
+func TestCheckExpired(t *testing.T) {
+	zip, err := tests.SecureBundle(tests.BundleOptions{NotAfter: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Read(zip)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	problems := b.Check(time.Now())
+	if len(problems) != 1 || !strings.Contains(problems[0], "expired") {
+		t.Errorf("expected an expired certificate but was %v", problems)
+	}
+}
+
+func TestCheckMissingFiles(t *testing.T) {
+	zip, err := tests.SecureBundle(tests.BundleOptions{Omit: []string{"ca.crt", "key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Read(zip)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	problems := b.Check(time.Now())
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems but was %v", problems)
+	}
+	if !strings.Contains(problems[0], "caCertLocation './ca.crt' is missing") || !strings.Contains(problems[1], "keyLocation './key' is missing") {
+		t.Errorf("unexpected problems %v", problems)
+	}
+}
+
+func TestReadWithoutConfig(t *testing.T) {
+	zip, err := tests.SecureBundle(tests.BundleOptions{Omit: []string{"config.json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(zip); err == nil {
+		t.Error("expected an error without config.json")
+	}
+}
+
+func TestOpenNotAZip(t *testing.T) {
+	f := path.Join(t.TempDir(), "bundle.zip")
+	if err := os.WriteFile(f, []byte("<html>AccessDenied</html>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Open(f)
+	if err == nil || !strings.Contains(err.Error(), "not a valid zip") {
+		t.Errorf("expected a zip error but was %v", err)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	zip, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Read(zip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := b.TLSConfig()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if config.RootCAs == nil || len(config.Certificates) != 1 {
+		t.Errorf("expected the CA and client certificate but was %v", config)
+	}
+}
+
+func TestTLSConfigMissingKey(t *testing.T) {
+	zip, err := tests.SecureBundle(tests.BundleOptions{Omit: []string{"key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Read(zip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.TLSConfig(); err == nil || !strings.Contains(err.Error(), "is missing from the bundle") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
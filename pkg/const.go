@@ -20,4 +20,10 @@ const (
 	JSONFormat = "json"
 	// TextFormat is for the command line flag -o
 	TextFormat = "text"
+	// YAMLFormat is for the command line flag -o
+	YAMLFormat = "yaml"
+	// JSONLinesFormat is for the command line flag -o, one json document per line
+	JSONLinesFormat = "jsonl"
+	// CSVFormat is for the command line flag --format, comma separated values with a header
+	CSVFormat = "csv"
 )
@@ -0,0 +1,160 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	"github.com/gocql/gocql"
+)
+
+// ContactInfo is how the metadata service of a database says to connect. Astra routes every node through one
+// proxy, the node is picked by sending its host id as the TLS server name
+type ContactInfo struct {
+	SNIProxyAddress string   `json:"sni_proxy_address"`
+	ContactPoints   []string `json:"contact_points"`
+	LocalDC         string   `json:"local_dc"`
+}
+
+type metadata struct {
+	ContactInfo ContactInfo `json:"contact_info"`
+}
+
+// DialBundle asks the metadata service of the bundle for the nodes and connects through the proxy to the first one
+// that answers
+func DialBundle(b bundle.Bundle, username, password string, timeout time.Duration) (*Session, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	tlsConfig, err := b.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	info, err := FetchContactInfo(MetadataClient(tlsConfig, timeout), MetadataURL(b))
+	if err != nil {
+		return nil, err
+	}
+	proxyHost, proxyPort, err := net.SplitHostPort(info.SNIProxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sni_proxy_address '%v' with error %v", info.SNIProxyAddress, err)
+	}
+	port, err := strconv.Atoi(proxyPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sni_proxy_address '%v' with error %v", info.SNIProxyAddress, err)
+	}
+	cluster := newCluster(username, password, timeout)
+	cluster.Hosts = []string{proxyHost}
+	cluster.Port = port
+	cluster.HostDialer = sniDialer{info: info, tlsConfig: tlsConfig, timeout: timeout}
+	return connect(cluster)
+}
+
+// sniDialer connects every connection of the driver through the proxy, the contact points are tried in order
+type sniDialer struct {
+	info      ContactInfo
+	tlsConfig *tls.Config
+	timeout   time.Duration
+}
+
+// DialHost ignores the address of the host, the node is picked by the server name
+func (d sniDialer) DialHost(ctx context.Context, _ *gocql.HostInfo) (*gocql.DialedHost, error) {
+	var err error
+	for _, hostID := range d.info.ContactPoints {
+		var conn net.Conn
+		conn, err = dialSNI(ctx, d.info.SNIProxyAddress, d.tlsConfig, hostID, d.timeout)
+		if err == nil {
+			// a TLS connection does not support writev which coalescing relies on
+			return &gocql.DialedHost{Conn: conn, DisableCoalesce: true}, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to connect to any of %v nodes, last error %v", len(d.info.ContactPoints), err)
+}
+
+// MetadataURL is the metadata service of the bundle
+func MetadataURL(b bundle.Bundle) string {
+	return "https://" + net.JoinHostPort(b.Config.Host, strconv.Itoa(b.Config.Port)) + "/metadata"
+}
+
+// MetadataClient calls the metadata service with the client certificate of the bundle
+func MetadataClient(tlsConfig *tls.Config, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+}
+
+// Handshake completes a TLS handshake with the proxy for the first contact point and closes the connection, it
+// checks the proxy is reachable and accepts the certificates of the bundle without logging in
+func Handshake(info ContactInfo, tlsConfig *tls.Config, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if len(info.ContactPoints) == 0 {
+		return errors.New("metadata has no contact_points")
+	}
+	conn, err := dialSNI(context.Background(), info.SNIProxyAddress, tlsConfig, info.ContactPoints[0], timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// FetchContactInfo reads the contact info from the metadata service
+func FetchContactInfo(client *http.Client, metadataURL string) (ContactInfo, error) {
+	resp, err := client.Get(metadataURL)
+	if err != nil {
+		return ContactInfo{}, fmt.Errorf("unable to get metadata with error %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ContactInfo{}, fmt.Errorf("unable to read metadata with error %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ContactInfo{}, fmt.Errorf("unable to get metadata, %v returned status %v", metadataURL, resp.StatusCode)
+	}
+	var m metadata
+	if err := json.Unmarshal(body, &m); err != nil {
+		return ContactInfo{}, fmt.Errorf("unable to parse metadata with error %v", err)
+	}
+	if m.ContactInfo.SNIProxyAddress == "" || len(m.ContactInfo.ContactPoints) == 0 {
+		return ContactInfo{}, fmt.Errorf("metadata has no sni_proxy_address or contact_points")
+	}
+	return m.ContactInfo, nil
+}
+
+// dialSNI completes a TLS handshake with the proxy sending the host id as the server name, the proxy routes the
+// connection to that node
+func dialSNI(ctx context.Context, proxyAddress string, tlsConfig *tls.Config, hostID string, timeout time.Duration) (net.Conn, error) {
+	config := tlsConfig.Clone()
+	config.ServerName = hostID
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: timeout}, Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to complete TLS handshake with %v with error %v", proxyAddress, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,210 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg/bundle"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+)
+
+const hostID = "8a2f3b60-1f3e-4d6b-9a53-3b5c2e2f1d11"
+
+// astraStandIn serves the metadata service and the CQL proxy with certificates signed by the CA of the returned bundle
+func astraStandIn(t *testing.T, contactPoints string) (bundle.Bundle, *tests.CQLServer) {
+	port := freePort(t)
+	zipContent, serverTLS, err := tests.ServerBundle(tests.BundleOptions{Host: "127.0.0.1", Port: port, ServerNames: []string{hostID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := bundle.Read(zipContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cqlServer, err := tests.NewCQLServer("token", "AstraCS:secret", serverTLS, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := cqlServer.Close(); err != nil {
+			t.Logf("unable to close server %v", err)
+		}
+	})
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"version":1,"contact_info":{"type":"sni_proxy","local_dc":"dc-1","contact_points":%v,"sni_proxy_address":"%v"}}`, contactPoints, cqlServer.Addr())
+	}))
+	ts.Listener.Close()
+	ts.Listener = l
+	ts.TLS = serverTLS
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return b, cqlServer
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestDialBundle(t *testing.T) {
+	b, cqlServer := astraStandIn(t, `["`+hostID+`"]`)
+	session, err := DialBundle(b, "token", "AstraCS:secret", 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer session.Close()
+	if _, err := session.Query("SELECT now() FROM system.local"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	names := cqlServer.ServerNames()
+	if len(names) == 0 {
+		t.Fatal("expected connections to the proxy")
+	}
+	for _, name := range names {
+		if name != hostID {
+			t.Errorf("expected the host id as server name but was %v", names)
+		}
+	}
+}
+
+func TestDialBundleOtherCA(t *testing.T) {
+	_, cqlServer := astraStandIn(t, `["`+hostID+`"]`)
+	other, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := bundle.Read(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig, err := b.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dialSNI(context.Background(), cqlServer.Addr(), tlsConfig, hostID, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "unknown authority") {
+		t.Errorf("expected the proxy certificate to be rejected but was %v", err)
+	}
+}
+
+func TestDialBundleOtherHostID(t *testing.T) {
+	b, cqlServer := astraStandIn(t, `["`+hostID+`"]`)
+	tlsConfig, err := b.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dialSNI(context.Background(), cqlServer.Addr(), tlsConfig, "6d1e4b3c-0000-4000-8000-000000000000", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "certificate is valid for") {
+		t.Errorf("expected the proxy certificate to be rejected but was %v", err)
+	}
+}
+
+func TestFetchContactInfoMissing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"contact_info":{"contact_points":[]}}`)
+	}))
+	defer ts.Close()
+	_, err := FetchContactInfo(ts.Client(), ts.URL+"/metadata")
+	if err == nil || !strings.Contains(err.Error(), "no sni_proxy_address") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestFetchContactInfoStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	_, err := FetchContactInfo(ts.Client(), ts.URL+"/metadata")
+	if err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	b, cqlServer := astraStandIn(t, `["`+hostID+`"]`)
+	tlsConfig, err := b.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := FetchContactInfo(MetadataClient(tlsConfig, time.Second), MetadataURL(b))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if info.SNIProxyAddress != cqlServer.Addr() {
+		t.Errorf("expected proxy %v but was %v", cqlServer.Addr(), info.SNIProxyAddress)
+	}
+	if err := Handshake(info, tlsConfig, time.Second); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestHandshakeOtherCA(t *testing.T) {
+	_, cqlServer := astraStandIn(t, `["`+hostID+`"]`)
+	other, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := bundle.Read(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig, err := b.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Handshake(ContactInfo{SNIProxyAddress: cqlServer.Addr(), ContactPoints: []string{hostID}}, tlsConfig, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "unknown authority") {
+		t.Errorf("expected the proxy certificate to be rejected but was %v", err)
+	}
+}
+
+func TestHandshakeNoContactPoints(t *testing.T) {
+	err := Handshake(ContactInfo{SNIProxyAddress: "127.0.0.1:29042"}, nil, time.Second)
+	if err == nil || err.Error() != "metadata has no contact_points" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMetadataURL(t *testing.T) {
+	var b bundle.Bundle
+	b.Config.Host = "abc-us-east1.db.astra.datastax.com"
+	b.Config.Port = 29080
+	if u := MetadataURL(b); u != "https://abc-us-east1.db.astra.datastax.com:29080/metadata" {
+		t.Errorf("unexpected url %v", u)
+	}
+}
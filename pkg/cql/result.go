@@ -0,0 +1,227 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// Result is what a statement returned. Values are formatted the way cqlsh prints them, null values are null
+type Result struct {
+	Kind     string     `json:"kind"`
+	Columns  []string   `json:"columns,omitempty"`
+	Rows     [][]string `json:"rows,omitempty"`
+	Keyspace string     `json:"keyspace,omitempty"`
+	// MorePages is true when the server paged the rows, only the first page is read
+	MorePages bool `json:"morePages,omitempty"`
+}
+
+// Error is an error returned by the server
+type Error struct {
+	Code    int32
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v (error code 0x%04x)", e.Message, e.Code)
+}
+
+// serverError turns the errors the server returned into *Error
+func serverError(err error) error {
+	var requestErr gocql.RequestError
+	if errors.As(err, &requestErr) {
+		return &Error{Code: int32(requestErr.Code()), Message: requestErr.Message()}
+	}
+	return err
+}
+
+// readResult reads the rows of the first page, a statement without columns returned no rows
+func readResult(iter *gocql.Iter) Result {
+	columns := iter.Columns()
+	if len(columns) == 0 {
+		return Result{Kind: "void"}
+	}
+	result := Result{Kind: "rows"}
+	// the driver scans a tuple column into a value per field
+	var dest []interface{}
+	cells := make([]value, len(columns))
+	fields := make([][]element, len(columns))
+	for i, c := range columns {
+		result.Columns = append(result.Columns, c.Name)
+		if tuple, ok := c.TypeInfo.(gocql.TupleTypeInfo); ok {
+			fields[i] = make([]element, len(tuple.Elems))
+			for j := range fields[i] {
+				dest = append(dest, &fields[i][j])
+			}
+		} else {
+			dest = append(dest, &cells[i])
+		}
+	}
+	for {
+		if iter.WillSwitchPage() {
+			result.MorePages = true
+			return result
+		}
+		if !iter.Scan(dest...) {
+			return result
+		}
+		row := make([]string, len(columns))
+		for i := range columns {
+			if fields[i] != nil {
+				row[i] = "(" + join(fields[i]) + ")"
+			} else {
+				row[i] = cells[i].text
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+}
+
+// value is a column formatted as it is read
+type value struct {
+	text string
+}
+
+func (v *value) UnmarshalCQL(info gocql.TypeInfo, data []byte) error {
+	v.text = format(info, data)
+	return nil
+}
+
+// element is a value inside a collection, tuple or udt where text is quoted like cqlsh does
+type element struct {
+	text string
+}
+
+func (e *element) UnmarshalCQL(info gocql.TypeInfo, data []byte) error {
+	e.text = format(info, data)
+	if data != nil && isText(info) {
+		e.text = "'" + strings.ReplaceAll(e.text, "'", "''") + "'"
+	}
+	return nil
+}
+
+// udt collects the fields of a user defined type in order
+type udt struct {
+	fields []string
+}
+
+func (u *udt) UnmarshalUDT(name string, info gocql.TypeInfo, data []byte) error {
+	var e element
+	if err := e.UnmarshalCQL(info, data); err != nil {
+		return err
+	}
+	u.fields = append(u.fields, name+": "+e.text)
+	return nil
+}
+
+func isText(info gocql.TypeInfo) bool {
+	switch info.Type() {
+	case gocql.TypeAscii, gocql.TypeVarchar, gocql.TypeText:
+		return true
+	}
+	return false
+}
+
+// format prints the value like cqlsh, values that do not decode are printed as blobs
+func format(info gocql.TypeInfo, data []byte) string {
+	if data == nil {
+		return "null"
+	}
+	switch info.Type() {
+	case gocql.TypeAscii, gocql.TypeVarchar, gocql.TypeText:
+		return string(data)
+	case gocql.TypeBlob, gocql.TypeCustom:
+		return formatBlob(data)
+	case gocql.TypeTimestamp:
+		var t time.Time
+		if gocql.Unmarshal(info, data, &t) == nil {
+			return t.UTC().Format("2006-01-02 15:04:05.000Z")
+		}
+	case gocql.TypeDate:
+		var t time.Time
+		if gocql.Unmarshal(info, data, &t) == nil {
+			return t.UTC().Format("2006-01-02")
+		}
+	case gocql.TypeTime:
+		var d time.Duration
+		if gocql.Unmarshal(info, data, &d) == nil {
+			return time.Time{}.Add(d).Format("15:04:05.000000000")
+		}
+	case gocql.TypeList:
+		var items []element
+		if gocql.Unmarshal(info, data, &items) == nil {
+			return "[" + join(items) + "]"
+		}
+	case gocql.TypeSet:
+		var items []element
+		if gocql.Unmarshal(info, data, &items) == nil {
+			return "{" + join(items) + "}"
+		}
+	case gocql.TypeMap:
+		var items map[element]element
+		if gocql.Unmarshal(info, data, &items) == nil {
+			// the order of the server is lost in a map, the entries are sorted to print the same every time
+			var pairs []string
+			for k, v := range items {
+				pairs = append(pairs, k.text+": "+v.text)
+			}
+			sort.Strings(pairs)
+			return "{" + strings.Join(pairs, ", ") + "}"
+		}
+	case gocql.TypeTuple:
+		if tuple, ok := info.(gocql.TupleTypeInfo); ok {
+			items := make([]element, len(tuple.Elems))
+			dest := make([]interface{}, len(items))
+			for i := range items {
+				dest[i] = &items[i]
+			}
+			if gocql.Unmarshal(info, data, dest) == nil {
+				return "(" + join(items) + ")"
+			}
+		}
+	case gocql.TypeUDT:
+		var u udt
+		if gocql.Unmarshal(info, data, &u) == nil {
+			return "{" + strings.Join(u.fields, ", ") + "}"
+		}
+	default:
+		if v, err := info.NewWithError(); err == nil && gocql.Unmarshal(info, data, v) == nil {
+			return fmt.Sprint(reflect.ValueOf(v).Elem().Interface())
+		}
+	}
+	return formatBlob(data)
+}
+
+// join lists the elements of a collection or tuple
+func join(items []element) string {
+	texts := make([]string, len(items))
+	for i, e := range items {
+		texts[i] = e.text
+	}
+	return strings.Join(texts, ", ")
+}
+
+func formatBlob(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
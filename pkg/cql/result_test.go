@@ -0,0 +1,61 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+)
+
+func native(t gocql.Type) gocql.NativeType {
+	return gocql.NewNativeType(4, t, "")
+}
+
+func TestFormat(t *testing.T) {
+	text := native(gocql.TypeVarchar)
+	integer := native(gocql.TypeInt)
+	cases := []struct {
+		name     string
+		info     gocql.TypeInfo
+		value    []byte
+		expected string
+	}{
+		{"null", text, nil, "null"},
+		{"text", text, []byte("hello"), "hello"},
+		{"int", integer, []byte{0xff, 0xff, 0xff, 0xfe}, "-2"},
+		{"bigint", native(gocql.TypeBigInt), []byte{0, 0, 0, 0, 0, 0, 1, 0}, "256"},
+		{"boolean", native(gocql.TypeBoolean), []byte{1}, "true"},
+		{"double", native(gocql.TypeDouble), []byte{0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, "1.5"},
+		{"varint", native(gocql.TypeVarint), []byte{0xff, 0x00}, "-256"},
+		{"decimal", native(gocql.TypeDecimal), []byte{0, 0, 0, 2, 0xfb, 0x2e}, "-12.34"},
+		{"timestamp", native(gocql.TypeTimestamp), []byte{0, 0, 0x01, 0x7e, 0x3e, 0x1f, 0x9c, 0x00}, "2022-01-09 09:16:06.016Z"},
+		{"date", native(gocql.TypeDate), []byte{0x80, 0, 0, 1}, "1970-01-02"},
+		{"uuid", native(gocql.TypeUUID), []byte{0x2c, 0x3b, 0xc0, 0xd6, 0x5e, 0x3e, 0x4d, 0x77, 0x81, 0xc8, 0xd9, 0x5a, 0x35, 0xbd, 0xc5, 0x8b}, "2c3bc0d6-5e3e-4d77-81c8-d95a35bdc58b"},
+		{"inet", native(gocql.TypeInet), []byte{127, 0, 0, 1}, "127.0.0.1"},
+		{"blob", native(gocql.TypeBlob), []byte{0xca, 0xfe}, "0xcafe"},
+		{"list", gocql.CollectionType{NativeType: native(gocql.TypeList), Elem: text}, []byte{0, 0, 0, 2, 0, 0, 0, 1, 'a', 0, 0, 0, 2, 'b', '\''}, "['a', 'b''']"},
+		{"map", gocql.CollectionType{NativeType: native(gocql.TypeMap), Key: text, Elem: integer}, []byte{0, 0, 0, 2, 0, 0, 0, 1, 'b', 0, 0, 0, 4, 0, 0, 0, 3, 0, 0, 0, 1, 'a', 0, 0, 0, 4, 0, 0, 0, 4}, "{'a': 4, 'b': 3}"},
+		{"tuple", gocql.TupleTypeInfo{NativeType: native(gocql.TypeTuple), Elems: []gocql.TypeInfo{integer, text}}, []byte{0, 0, 0, 4, 0, 0, 0, 1, 0xff, 0xff, 0xff, 0xff}, "(1, null)"},
+		{"udt", gocql.UDTTypeInfo{NativeType: native(gocql.TypeUDT), Elements: []gocql.UDTField{{Name: "id", Type: integer}, {Name: "name", Type: text}}}, []byte{0, 0, 0, 4, 0, 0, 0, 9, 0, 0, 0, 3, 'a', 'n', 'n'}, "{id: 9, name: 'ann'}"},
+		{"wrong length", native(gocql.TypeUUID), []byte{1, 2, 3}, "0x010203"},
+	}
+	for _, c := range cases {
+		if actual := format(c.info, c.value); actual != c.expected {
+			t.Errorf("%v: expected %q but was %q", c.name, c.expected, actual)
+		}
+	}
+}
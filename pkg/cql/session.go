@@ -0,0 +1,134 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// DefaultTimeout bounds connecting and every query when no timeout is passed
+const DefaultTimeout = 30 * time.Second
+
+// useStatement matches USE with a quoted or unquoted keyspace, the driver does not run USE itself
+var useStatement = regexp.MustCompile(`(?is)^USE\s+("(?:[^"]|"")+"|\w+)$`)
+
+// Session runs statements one at a time on a single node
+type Session struct {
+	cluster gocql.ClusterConfig
+	session *gocql.Session
+}
+
+// Dial connects to the node at addr, over TLS when tlsConfig is set, and logs in with the password authenticator
+func Dial(addr string, tlsConfig *tls.Config, username, password string, timeout time.Duration) (*Session, error) {
+	host, portText, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address '%v' with error %v", addr, err)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address '%v' with error %v", addr, err)
+	}
+	cluster := newCluster(username, password, timeout)
+	cluster.Hosts = []string{host}
+	cluster.Port = port
+	if tlsConfig != nil {
+		cluster.SslOpts = &gocql.SslOptions{Config: tlsConfig.Clone(), EnableHostVerification: true}
+	}
+	return connect(cluster)
+}
+
+// newCluster configures the driver for a command line tool: one connection, no peer discovery or events and
+// LOCAL_QUORUM which Astra requires for writes. The credentials are only sent when the server asks for them
+func newCluster(username, password string, timeout time.Duration) gocql.ClusterConfig {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	cluster := gocql.NewCluster()
+	cluster.ProtoVersion = 4
+	cluster.Consistency = gocql.LocalQuorum
+	cluster.Timeout = timeout
+	cluster.ConnectTimeout = timeout
+	cluster.NumConns = 1
+	// the driver prepares DML statements, the rows of a statement run once are read with the metadata they came with
+	cluster.DisableSkipMetadata = true
+	cluster.DisableInitialHostLookup = true
+	cluster.Events.DisableNodeStatusEvents = true
+	cluster.Events.DisableTopologyEvents = true
+	cluster.Events.DisableSchemaEvents = true
+	cluster.Logger = log.New(io.Discard, "", 0)
+	if username != "" || password != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{Username: username, Password: password}
+	}
+	return *cluster
+}
+
+func connect(cluster gocql.ClusterConfig) (*Session, error) {
+	session, err := gocql.NewSession(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect with error %v", err)
+	}
+	return &Session{cluster: cluster, session: session}, nil
+}
+
+// Query runs the statement and reads the first page of rows, errors returned by the server are *Error
+func (s *Session) Query(statement string) (Result, error) {
+	if m := useStatement.FindStringSubmatch(strings.TrimSpace(statement)); m != nil {
+		return s.use(keyspaceName(m[1]))
+	}
+	iter := s.session.Query(statement).Iter()
+	result := readResult(iter)
+	if err := iter.Close(); err != nil {
+		return Result{}, serverError(err)
+	}
+	return result, nil
+}
+
+// use reconnects with the keyspace, the session is unchanged when that fails
+func (s *Session) use(keyspace string) (Result, error) {
+	cluster := s.cluster
+	cluster.Keyspace = keyspace
+	session, err := gocql.NewSession(cluster)
+	if err != nil {
+		return Result{}, err
+	}
+	s.session.Close()
+	s.cluster, s.session = cluster, session
+	return Result{Kind: "set_keyspace", Keyspace: keyspace}, nil
+}
+
+// keyspaceName is the keyspace a USE statement names, unquoted names are case insensitive
+func keyspaceName(name string) string {
+	if strings.HasPrefix(name, `"`) {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return strings.ToLower(name)
+}
+
+// Close closes the connections
+func (s *Session) Close() error {
+	s.session.Close()
+	return nil
+}
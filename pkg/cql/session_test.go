@@ -0,0 +1,145 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+)
+
+func startServer(t *testing.T, handler func(query string) tests.CQLResult) *tests.CQLServer {
+	server, err := tests.NewCQLServer("token", "AstraCS:secret", nil, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := server.Close(); err != nil {
+			t.Logf("unable to close server %v", err)
+		}
+	})
+	return server
+}
+
+func TestQuery(t *testing.T) {
+	server := startServer(t, func(query string) tests.CQLResult {
+		switch {
+		case strings.HasPrefix(query, "SELECT"):
+			return tests.CQLResult{Columns: []string{"id", "name"}, Rows: [][]string{{"1", "ann"}, {"2", "bob"}}}
+		case strings.HasPrefix(query, "CREATE"):
+			return tests.CQLResult{Change: "CREATED", Keyspace: "ks1", Table: "users"}
+		default:
+			return tests.CQLResult{ErrorMessage: "line 1:0 no viable alternative"}
+		}
+	})
+	session, err := Dial(server.Addr(), nil, "token", "AstraCS:secret", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer session.Close()
+	result, err := session.Query("SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := Result{Kind: "rows", Columns: []string{"id", "name"}, Rows: [][]string{{"1", "ann"}, {"2", "bob"}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v but was %v", expected, result)
+	}
+	result, err = session.Query("CREATE TABLE users (id int PRIMARY KEY)")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.Kind != "void" {
+		t.Errorf("expected void result but was %v", result)
+	}
+	_, err = session.Query("SELEC")
+	var serverErr *Error
+	if !errors.As(err, &serverErr) || serverErr.Code != 0x2000 {
+		t.Errorf("expected syntax error but was %v", err)
+	}
+	expectedQueries := []string{"SELECT id, name FROM users", "CREATE TABLE users (id int PRIMARY KEY)", "SELEC"}
+	if !reflect.DeepEqual(server.Queries(), expectedQueries) {
+		t.Errorf("expected %v but was %v", expectedQueries, server.Queries())
+	}
+}
+
+func TestQueryUse(t *testing.T) {
+	server := startServer(t, nil)
+	session, err := Dial(server.Addr(), nil, "token", "AstraCS:secret", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer session.Close()
+	for _, c := range []struct {
+		statement string
+		keyspace  string
+	}{
+		{"USE Ks1", "ks1"},
+		{`use "MyKs"`, "MyKs"},
+	} {
+		result, err := session.Query(c.statement)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if result.Kind != "set_keyspace" || result.Keyspace != c.keyspace {
+			t.Errorf("%v: expected keyspace %v but was %v", c.statement, c.keyspace, result)
+		}
+	}
+	expectedQueries := []string{`USE "ks1"`, `USE "MyKs"`}
+	if !reflect.DeepEqual(server.Queries(), expectedQueries) {
+		t.Errorf("expected %v but was %v", expectedQueries, server.Queries())
+	}
+}
+
+func TestDialBadCredentials(t *testing.T) {
+	server := startServer(t, nil)
+	_, err := Dial(server.Addr(), nil, "token", "wrong", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "Provided username and/or password are incorrect") {
+		t.Errorf("expected authentication error but was %v", err)
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	server := startServer(t, nil)
+	addr := server.Addr()
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Dial(addr, nil, "token", "AstraCS:secret", time.Second); err == nil {
+		t.Error("expected error connecting to a closed server")
+	}
+}
+
+func TestDialWithoutAuthentication(t *testing.T) {
+	server, err := tests.NewCQLServer("", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	session, err := Dial(server.Addr(), nil, "", "", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer session.Close()
+	result, err := session.Query("TRUNCATE users")
+	if err != nil || result.Kind != "void" {
+		t.Errorf("expected void result but was %v %v", result, err)
+	}
+}
@@ -0,0 +1,135 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// batchStart and batchEnd find a BATCH block, the semicolons between its statements do not end it
+var (
+	batchStart = regexp.MustCompile(`(?is)^\s*BEGIN\s+((UNLOGGED|COUNTER)\s+)?BATCH\b`)
+	batchEnd   = regexp.MustCompile(`(?is)\bAPPLY\s+BATCH\s*$`)
+)
+
+// Statement is one statement of a script, Line is where it starts counting from 1
+type Statement struct {
+	Text string
+	Line int
+}
+
+// Split cuts a script into statements on semicolons. Semicolons inside 'strings', "identifiers", $$strings$$,
+// comments and BEGIN BATCH ... APPLY BATCH blocks do not end a statement. Comments (--, // and /* */) are left out of the statements and statements
+// that are only whitespace are dropped
+func Split(script string) []Statement {
+	var statements []Statement
+	var current strings.Builder
+	line, start := 1, 0
+	flush := func() {
+		text := strings.TrimSpace(current.String())
+		if text != "" {
+			statements = append(statements, Statement{Text: text, Line: start})
+		}
+		current.Reset()
+		start = 0
+	}
+	// write adds text to the statement, the first text that is not whitespace sets the line the statement starts on
+	write := func(s string) {
+		if start == 0 && strings.TrimSpace(s) != "" {
+			start = line
+		}
+		current.WriteString(s)
+	}
+	for i := 0; i < len(script); {
+		rest := script[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			comment := rest[:end]
+			line += strings.Count(comment, "\n")
+			current.WriteByte(' ')
+			i += end
+		case rest[0] == '\'' || rest[0] == '"':
+			quoted := quotedLength(rest, rest[:1])
+			write(rest[:quoted])
+			line += strings.Count(rest[:quoted], "\n")
+			i += quoted
+		case strings.HasPrefix(rest, "$$"):
+			end := strings.Index(rest[2:], "$$")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			write(rest[:end])
+			line += strings.Count(rest[:end], "\n")
+			i += end
+		case rest[0] == ';':
+			if text := current.String(); batchStart.MatchString(text) && !batchEnd.MatchString(text) {
+				current.WriteByte(';')
+			} else {
+				flush()
+			}
+			i++
+		default:
+			if rest[0] == '\n' {
+				line++
+			}
+			write(rest[:1])
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// quotedLength is the length of the quoted text at the start of s, doubled quotes are escaped quotes.
+// An unterminated quote runs to the end of the script
+func quotedLength(s, quote string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i:i+1] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1:i+2] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(s)
+}
+
+// QuoteIdentifier quotes a keyspace, table or column name so it is used exactly as written
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteString makes a string literal of the text
+func QuoteString(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
@@ -0,0 +1,83 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package cql runs CQL statements with gocql and formats their results the way cqlsh prints them
+package cql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	script := `-- create the schema; twice
+CREATE TABLE users (id int PRIMARY KEY, name text); // users
+INSERT INTO users (id, name) VALUES (1, 'it''s; fine');
+/* a block
+   comment; */ INSERT INTO "Quoted;Table" (id) VALUES (2);
+CREATE FUNCTION f (x int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE java AS $$ return x; $$;
+
+;;
+SELECT * FROM users`
+	expected := []Statement{
+		{Text: "CREATE TABLE users (id int PRIMARY KEY, name text)", Line: 2},
+		{Text: "INSERT INTO users (id, name) VALUES (1, 'it''s; fine')", Line: 3},
+		{Text: `INSERT INTO "Quoted;Table" (id) VALUES (2)`, Line: 5},
+		{Text: "CREATE FUNCTION f (x int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE java AS $$ return x; $$", Line: 6},
+		{Text: "SELECT * FROM users", Line: 9},
+	}
+	statements := Split(script)
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected\n%v\nbut was\n%v", expected, statements)
+	}
+}
+
+func TestSplitBatch(t *testing.T) {
+	script := `BEGIN UNLOGGED BATCH
+  INSERT INTO users (id, name) VALUES (1, 'ann; again');
+  -- the second user; ignored
+  UPDATE users SET name = 'bob' WHERE id = 2;
+apply batch;
+SELECT * FROM users;`
+	expected := []Statement{
+		{Text: "BEGIN UNLOGGED BATCH\n  INSERT INTO users (id, name) VALUES (1, 'ann; again');\n  \n  UPDATE users SET name = 'bob' WHERE id = 2;\napply batch", Line: 1},
+		{Text: "SELECT * FROM users", Line: 6},
+	}
+	statements := Split(script)
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected\n%q\nbut was\n%q", expected, statements)
+	}
+}
+
+func TestSplitOnlyComments(t *testing.T) {
+	if statements := Split("-- nothing\n/* to */ // run\n  ;\n"); len(statements) != 0 {
+		t.Errorf("expected no statements but was %v", statements)
+	}
+}
+
+func TestSplitUnterminatedString(t *testing.T) {
+	expected := []Statement{{Text: "INSERT INTO t (a) VALUES ('oops; still a string", Line: 1}}
+	if statements := Split("INSERT INTO t (a) VALUES ('oops; still a string"); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected %v but was %v", expected, statements)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if q := QuoteIdentifier(`My"Table`); q != `"My""Table"` {
+		t.Errorf("unexpected identifier %v", q)
+	}
+	if q := QuoteString("it's"); q != "'it''s'" {
+		t.Errorf("unexpected string %v", q)
+	}
+}
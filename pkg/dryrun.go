@@ -0,0 +1,180 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package pkg is the top level package for shared libraries
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+// MaxListLimit is the page size used when every database needs to be looked at
+const MaxListLimit = 1000
+
+// ErrDryRun is returned in place of the response of a request that was only printed because of --dry-run
+var ErrDryRun = errors.New("dry run, request not sent")
+
+// dryRunDoer sends read only requests and prints every other request instead of sending it. Before printing
+// the database the request targets is looked up, so a database name can be used in place of the id
+type dryRunDoer struct {
+	doer     astra.HttpRequestDoer
+	out      io.Writer
+	resolve  func(idOrName string) (astra.Database, error)
+	validate func(create astra.DatabaseInfoCreate) error
+}
+
+// readOnly is true for requests without side effects. The secure bundle url is generated with a POST but changes nothing
+func readOnly(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	return strings.HasSuffix(req.URL.Path, "/secureBundleURL")
+}
+
+// Do sends read only requests, all others are validated and printed and ErrDryRun is returned
+func (d *dryRunDoer) Do(req *http.Request) (*http.Response, error) {
+	if readOnly(req) {
+		return d.doer.Do(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read request body for dry run with error '%v'", err)
+		}
+	}
+	var target string
+	tokens := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	const dbPathTokens = 3
+	switch {
+	case len(tokens) == 2 && tokens[1] == "databases" && req.Method == http.MethodPost:
+		var create astra.DatabaseInfoCreate
+		if err := json.Unmarshal(body, &create); err != nil {
+			return nil, fmt.Errorf("dry run: invalid database definition '%v'", err)
+		}
+		if err := d.validate(create); err != nil {
+			return nil, fmt.Errorf("dry run: %v", err)
+		}
+	case len(tokens) >= dbPathTokens && tokens[1] == "databases":
+		db, err := d.resolve(tokens[2])
+		if err != nil {
+			return nil, fmt.Errorf("dry run: %v", err)
+		}
+		if db.Id != tokens[2] {
+			tokens[2] = db.Id
+			req.URL.Path = "/" + strings.Join(tokens, "/")
+		}
+		target = describeDb(db)
+	}
+	fmt.Fprintf(d.out, "dry run: would send %v %v\n", req.Method, req.URL.String())
+	if target != "" {
+		fmt.Fprintf(d.out, "target: %v\n", target)
+	}
+	if len(body) > 0 {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err != nil {
+			indented.Reset()
+			indented.Write(body)
+		}
+		fmt.Fprintf(d.out, "body:\n%v\n", indented.String())
+	}
+	return nil, ErrDryRun
+}
+
+func describeDb(db astra.Database) string {
+	var name, region string
+	if db.Info.Name != nil {
+		name = *db.Info.Name
+	}
+	if db.Info.Region != nil {
+		region = *db.Info.Region
+	}
+	return fmt.Sprintf("%v (%v) status %v region %v", name, db.Id, db.Status, region)
+}
+
+// ResolveDb finds the database by id and when there is none, by name. A name has to match exactly one database
+// that is not terminated
+func ResolveDb(client Client, idOrName string) (astra.Database, error) {
+	db, findErr := client.FindDb(idOrName)
+	if findErr == nil {
+		return db, nil
+	}
+	dbs, err := client.ListDb("", "", "", MaxListLimit)
+	if err != nil {
+		return astra.Database{}, fmt.Errorf("unable to find database '%v' with error '%v'", idOrName, findErr)
+	}
+	var matches []astra.Database
+	for _, db := range dbs {
+		if db.Info.Name != nil && *db.Info.Name == idOrName && db.Status != astra.StatusEnumTERMINATED {
+			matches = append(matches, db)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return astra.Database{}, fmt.Errorf("no database with id or name '%v' found", idOrName)
+	case 1:
+		return matches[0], nil
+	default:
+		return astra.Database{}, fmt.Errorf("%v databases are named '%v', use the id instead", len(matches), idOrName)
+	}
+}
+
+// ValidateCreate checks the new database has a name and keyspace, that the tier, cloud provider and region combination
+// is available with quota left and that no other database already has the name
+func ValidateCreate(client Client, create astra.DatabaseInfoCreate) error {
+	if create.Name == "" {
+		return errors.New("database name is required")
+	}
+	if create.Keyspace == "" {
+		return errors.New("keyspace is required")
+	}
+	tiers, err := client.GetTierInfo()
+	if err != nil {
+		return fmt.Errorf("unable to get tiers with error '%v'", err)
+	}
+	var found bool
+	for _, t := range tiers {
+		if strings.EqualFold(string(t.Tier), string(create.Tier)) &&
+			strings.EqualFold(string(t.CloudProvider), string(create.CloudProvider)) &&
+			t.Region == create.Region {
+			if t.DatabaseCountLimit <= t.DatabaseCountUsed {
+				return fmt.Errorf("no database quota left for tier %v in %v %v", create.Tier, create.CloudProvider, create.Region)
+			}
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("tier %v is not available in %v %v, see astra-cli db tiers", create.Tier, create.CloudProvider, create.Region)
+	}
+	dbs, err := client.ListDb("", "", "", MaxListLimit)
+	if err != nil {
+		return fmt.Errorf("unable to list databases with error '%v'", err)
+	}
+	for _, db := range dbs {
+		if db.Info.Name != nil && *db.Info.Name == create.Name && db.Status != astra.StatusEnumTERMINATED {
+			return fmt.Errorf("database %v already exists with id %v", create.Name, db.Id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package pkg is the top level package for shared libraries
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+func newTestDoer(out *bytes.Buffer, dbs ...astra.Database) *dryRunDoer {
+	return &dryRunDoer{
+		doer: http.DefaultClient,
+		out:  out,
+		resolve: func(idOrName string) (astra.Database, error) {
+			for _, db := range dbs {
+				if db.Id == idOrName || (db.Info.Name != nil && *db.Info.Name == idOrName) {
+					return db, nil
+				}
+			}
+			return astra.Database{}, errors.New("not found")
+		},
+		validate: func(create astra.DatabaseInfoCreate) error {
+			if create.Name == "" {
+				return errors.New("database name is required")
+			}
+			return nil
+		},
+	}
+}
+
+func TestDryRunDoesNotSendMutatingRequests(t *testing.T) {
+	var hits int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer ts.Close()
+	var out bytes.Buffer
+	doer := newTestDoer(&out, astra.Database{Id: "abc", Info: astra.DatabaseInfo{Name: astra.StringPtr("mydb")}, Status: astra.StatusEnumACTIVE})
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/v2/databases/mydb/resize", strings.NewReader(`{"capacityUnits":2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = doer.Do(req)
+	if !errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected %v but was %v", ErrDryRun, err)
+	}
+	if hits != 0 {
+		t.Errorf("expected no request to be sent but there were %v", hits)
+	}
+	expected := "dry run: would send POST " + ts.URL + "/v2/databases/abc/resize\ntarget: mydb (abc) status ACTIVE region \nbody:\n{\n  \"capacityUnits\": 2\n}\n"
+	if out.String() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, out.String())
+	}
+}
+
+func TestDryRunSendsReads(t *testing.T) {
+	var hits int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer ts.Close()
+	var out bytes.Buffer
+	doer := newTestDoer(&out)
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/v2/databases/abc"},
+		{http.MethodPost, "/v2/databases/abc/secureBundleURL"},
+	} {
+		r, err := http.NewRequest(req.method, ts.URL+req.path, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := doer.Do(r)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		res.Body.Close()
+	}
+	if hits != 2 {
+		t.Errorf("expected 2 requests to be sent but there were %v", hits)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output but was '%v'", out.String())
+	}
+}
+
+func TestDryRunUnknownTarget(t *testing.T) {
+	var out bytes.Buffer
+	doer := newTestDoer(&out)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/v2/databases/nope/terminate", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = doer.Do(req)
+	if err == nil || errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected a resolution error but was %v", err)
+	}
+}
+
+func TestDryRunInvalidCreate(t *testing.T) {
+	var out bytes.Buffer
+	doer := newTestDoer(&out)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/v2/databases", strings.NewReader(`{"keyspace":"ks"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = doer.Do(req)
+	expected := "dry run: database name is required"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestResolveDbByName(t *testing.T) {
+	client := &tests.MockClient{
+		ErrorQueue: []error{errors.New("not found")},
+		Databases: []astra.Database{
+			{Id: "old", Info: astra.DatabaseInfo{Name: astra.StringPtr("mydb")}, Status: astra.StatusEnumTERMINATED},
+			{Id: "abc", Info: astra.DatabaseInfo{Name: astra.StringPtr("mydb")}, Status: astra.StatusEnumACTIVE},
+			{Id: "def", Info: astra.DatabaseInfo{Name: astra.StringPtr("other")}, Status: astra.StatusEnumACTIVE},
+		},
+	}
+	db, err := ResolveDb(client, "mydb")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if db.Id != "abc" {
+		t.Errorf("expected 'abc' but was '%v'", db.Id)
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	client := &tests.MockClient{
+		Tiers: []astra.AvailableRegionCombination{
+			{Tier: "serverless", CloudProvider: "GCP", Region: "us-east1", DatabaseCountLimit: 5},
+		},
+		Databases: []astra.Database{
+			{Id: "abc", Info: astra.DatabaseInfo{Name: astra.StringPtr("taken")}, Status: astra.StatusEnumACTIVE},
+		},
+	}
+	create := astra.DatabaseInfoCreate{Name: "new", Keyspace: "ks", Tier: "serverless", CloudProvider: "GCP", Region: "us-east1"}
+	if err := ValidateCreate(client, create); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	create.Region = "mars-1"
+	expected := "tier serverless is not available in GCP mars-1, see astra-cli db tiers"
+	if err := ValidateCreate(client, create); err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+	create.Region = "us-east1"
+	create.Name = "taken"
+	expected = "database taken already exists with id abc"
+	if err := ValidateCreate(client, create); err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
@@ -17,3 +17,9 @@ package env
 
 // Verbose sets the verbose mode for the command
 var Verbose bool
+
+// DryRun prints the requests that would change something instead of sending them
+var DryRun bool
+
+// Command is the command being run, for example astra-cli db delete, it is recorded in the audit log
+var Command string
@@ -16,11 +16,18 @@
 package httputils
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -48,32 +55,146 @@ func NewHTTPClient() *http.Client {
 	}
 }
 
-// DownloadZip pulls down the URL listed and saves it to the specified location
-func DownloadZip(downloadURL string, secBundleLoc string) (int64, error) {
+// DownloadZip pulls down the URL listed and saves it to the specified location. The response has to be a 200 with a
+// zip content type and a valid zip archive, it is written to a temporary file next to the location and only renamed over
+// it once complete so a failed download never leaves a truncated bundle behind. Returns the bytes written and the SHA-256
+func DownloadZip(downloadURL string, secBundleLoc string) (int64, string, error) {
 	httpClient := NewHTTPClient()
 	res, err := httpClient.Get(downloadURL)
 	if err != nil {
-		return -1, fmt.Errorf("unable to download zip with error %v", err)
+		return -1, "", fmt.Errorf("unable to download zip with error %v", err)
 	}
 	defer func() {
-		err = res.Body.Close()
-		if err != nil {
+		if err := res.Body.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "Warn: error closing http response body %v\n for request %v with status code %v\n", err, downloadURL, res.StatusCode)
 		}
 	}()
-	f, err := os.Create(secBundleLoc)
+	if err := checkZipResponse(res); err != nil {
+		return -1, "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(secBundleLoc), "."+filepath.Base(secBundleLoc)+"-*.tmp")
 	if err != nil {
-		return -1, fmt.Errorf("unable to create file to save too %v", err)
+		return -1, "", fmt.Errorf("unable to create file to save too %v", err)
 	}
+	tmpName := tmp.Name()
 	defer func() {
-		err = f.Close()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warn: error closing file %v for file %v\n", err, secBundleLoc)
+		// once renamed this fails with not exist, any other time it cleans up a partial download
+		if err := os.Remove(tmpName); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Warn: unable to remove temporary file %v with error %v\n", tmpName, err)
 		}
 	}()
-	i, err := io.Copy(f, res.Body)
+	hash := sha256.New()
+	i, err := io.Copy(io.MultiWriter(tmp, hash), res.Body)
+	if err != nil {
+		tmp.Close()
+		return -1, "", fmt.Errorf("unable to copy downloaded file to %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return -1, "", fmt.Errorf("unable to save downloaded file with error %v", err)
+	}
+	if err := ValidateZip(tmpName); err != nil {
+		return -1, "", err
+	}
+	if err := os.Chmod(tmpName, 0600); err != nil {
+		return -1, "", fmt.Errorf("unable to set permissions on downloaded file with error %v", err)
+	}
+	if err := os.Rename(tmpName, secBundleLoc); err != nil {
+		return -1, "", fmt.Errorf("unable to move downloaded file to %v with error %v", secBundleLoc, err)
+	}
+	return i, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// DownloadZipToMemory pulls down the URL listed and keeps it in memory, for bundles that must never be written to disk
+// as is. The same checks as DownloadZip apply
+func DownloadZipToMemory(downloadURL string) ([]byte, error) {
+	httpClient := NewHTTPClient()
+	res, err := httpClient.Get(downloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download zip with error %v", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warn: error closing http response body %v\n for request %v with status code %v\n", err, downloadURL, res.StatusCode)
+		}
+	}()
+	if err := checkZipResponse(res); err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read downloaded zip with error %v", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("downloaded file is not a valid zip with error %v", err)
+	}
+	if err := validateZipFiles(r.File); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// checkZipResponse rejects responses that are not a 200 or that have the content type of an error page
+func checkZipResponse(res *http.Response) error {
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download zip, expected status 200 but was %v with body '%v'", res.StatusCode, bodySnippet(res.Body))
+	}
+	if err := checkZipContentType(res.Header.Get("Content-Type")); err != nil {
+		return fmt.Errorf("%v with body '%v'", err, bodySnippet(res.Body))
+	}
+	return nil
+}
+
+// ValidateZip opens the archive and reads every file so both a truncated download and a corrupted entry are caught
+func ValidateZip(zipFile string) error {
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return fmt.Errorf("downloaded file is not a valid zip with error %v", err)
+	}
+	defer r.Close()
+	return validateZipFiles(r.File)
+}
+
+func validateZipFiles(files []*zip.File) error {
+	if len(files) == 0 {
+		return fmt.Errorf("downloaded zip is empty")
+	}
+	for _, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("unable to open '%v' in downloaded zip with error %v", f.Name, err)
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("downloaded zip is corrupted at '%v' with error %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkZipContentType rejects the text, html, xml and json bodies storage services answer with on errors. Zip is
+// served under several types, application/zip, application/octet-stream and binary/octet-stream among them, so any other is accepted
+func checkZipContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("unable to parse content type '%v' of download with error %v", contentType, err)
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+json") {
+		return fmt.Errorf("unable to download zip, content type was %v", mediaType)
+	}
+	return nil
+}
+
+// bodySnippet is the start of an error body to show in messages
+func bodySnippet(body io.Reader) string {
+	maxBody := int64(512)
+	b, err := io.ReadAll(io.LimitReader(body, maxBody))
 	if err != nil {
-		return -1, fmt.Errorf("unable to copy downloaded file to %v", err)
+		return ""
 	}
-	return i, nil
+	return strings.TrimSpace(string(b))
 }
@@ -16,37 +16,134 @@
 package httputils
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"testing"
+
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
 )
 
-func TestDownloadUrl(t *testing.T) {
-	zipContent := "zip file content"
+func serve(t *testing.T, status int, contentType string, body []byte) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, zipContent)
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		if _, err := w.Write(body); err != nil {
+			t.Logf("unable to write body %v", err)
+		}
 	}))
-	defer ts.Close()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestDownloadUrl(t *testing.T) {
+	zipContent, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := serve(t, http.StatusOK, "application/zip", zipContent)
 	tmpDir := t.TempDir()
 	zipFile := path.Join(tmpDir, "bundle.zip")
-	bytesWritten, err := DownloadZip(ts.URL, zipFile)
-	if bytesWritten == 0 {
-		t.Fatal("Expected bytes to be written but none were")
-	}
-
+	bytesWritten, sum, err := DownloadZip(ts.URL, zipFile)
 	if err != nil {
 		t.Fatalf("Unexpected error test '%v'", err)
 	}
-
+	if bytesWritten != int64(len(zipContent)) {
+		t.Errorf("expected %v bytes written but was %v", len(zipContent), bytesWritten)
+	}
+	expectedSum := sha256.Sum256(zipContent)
+	if sum != hex.EncodeToString(expectedSum[:]) {
+		t.Errorf("expected sha256 %v but was %v", hex.EncodeToString(expectedSum[:]), sum)
+	}
 	b, err := os.ReadFile(zipFile)
 	if err != nil {
 		t.Fatalf("Unexpected error reading file '%v'", err)
 	}
-	expectedZipContent := fmt.Sprintf("%v\n", zipContent)
-	if expectedZipContent != string(b) {
-		t.Errorf("expected/actual \n'%q'\n'%q'", expectedZipContent, string(b))
+	if string(zipContent) != string(b) {
+		t.Error("expected the saved file to be the zip served")
+	}
+	fi, err := os.Stat(zipFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions 0600 but was %v", fi.Mode().Perm())
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the bundle in the directory but was %v", entries)
+	}
+}
+
+func TestDownloadFailuresKeepExistingFile(t *testing.T) {
+	zipContent, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		status      int
+		contentType string
+		body        []byte
+		expected    string
+	}{
+		{"expired url", http.StatusForbidden, "application/xml", []byte("<Error><Code>AccessDenied</Code></Error>"), "expected status 200 but was 403"},
+		{"error page", http.StatusOK, "text/html; charset=utf-8", []byte("<html></html>"), "content type was text/html"},
+		{"truncated", http.StatusOK, "application/octet-stream", zipContent[:len(zipContent)/2], "not a valid zip"},
+		{"not a zip", http.StatusOK, "application/zip", []byte("zip file content"), "not a valid zip"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ts := serve(t, c.status, c.contentType, c.body)
+			tmpDir := t.TempDir()
+			zipFile := path.Join(tmpDir, "bundle.zip")
+			if err := os.WriteFile(zipFile, []byte("previous bundle"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err := DownloadZip(ts.URL, zipFile)
+			if err == nil || !strings.Contains(err.Error(), c.expected) {
+				t.Fatalf("expected error containing '%v' but was '%v'", c.expected, err)
+			}
+			b, err := os.ReadFile(zipFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != "previous bundle" {
+				t.Errorf("expected the existing file to be untouched but was '%v'", string(b))
+			}
+			entries, err := os.ReadDir(tmpDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected the temporary file to be removed but was %v", entries)
+			}
+		})
+	}
+}
+
+func TestDownloadZipToMemory(t *testing.T) {
+	zipContent, err := tests.SecureBundle(tests.BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := serve(t, http.StatusOK, "application/zip", zipContent)
+	b, err := DownloadZipToMemory(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(b) != string(zipContent) {
+		t.Error("expected the zip served")
+	}
+	truncated := serve(t, http.StatusOK, "application/zip", zipContent[:len(zipContent)/2])
+	if _, err := DownloadZipToMemory(truncated.URL); err == nil {
+		t.Error("expected an error for a truncated zip")
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/datastax-labs/astra-cli/pkg/audit"
 	"github.com/datastax-labs/astra-cli/pkg/env"
 	astraops "github.com/datastax/astra-client-go/v2/astra"
 )
@@ -39,6 +40,10 @@ type Client interface {
 	Resize(string, int) error
 	GetSecureBundle(string) (astraops.CredsURL, error)
 	GetTierInfo() ([]astraops.AvailableRegionCombination, error)
+	ListDatacenters(string, bool) ([]astraops.Datacenter, error)
+	AddDatacenters(string, []astraops.Datacenter) error
+	TerminateDatacenter(string, string) error
+	AddKeyspaceToDb(string, string) error
 }
 
 // Creds knows how handle and store credentials
@@ -46,42 +51,92 @@ type Creds struct {
 	GetHomeFunc func() (string, error) // optional. If not specified os.UserHomeDir is used for log base directory to find creds
 }
 
-// Login logs into the Astra DevOps API using the local configuration provided by the 'astra-cli login' command
-func (c *Creds) Login() (Client, error) {
+// Stored returns the credentials saved by 'astra-cli login', either the token or the service account is set
+func (c *Creds) Stored() (string, ClientInfo, error) {
+	_, token, clientInfo, err := c.stored()
+	return token, clientInfo, err
+}
+
+// stored reads the token, or the service account when there is no token, from the configuration directory
+func (c *Creds) stored() (confDir string, token string, clientInfo ClientInfo, err error) {
 	getHome := c.GetHomeFunc
 	if getHome == nil {
 		getHome = os.UserHomeDir
 	}
 	confDir, confFile, err := GetHome(getHome)
 	if err != nil {
-		return &AuthenticatedClient{}, fmt.Errorf("unable to read conf dir with error '%v'", err)
+		return "", "", ClientInfo{}, fmt.Errorf("unable to read conf dir with error '%v'", err)
 	}
 	hasToken, err := confFile.HasToken()
 	if err != nil {
-		return &AuthenticatedClient{}, fmt.Errorf("unable to read token file '%v' with error '%v'", confFile.TokenPath, err)
+		return "", "", ClientInfo{}, fmt.Errorf("unable to read token file '%v' with error '%v'", confFile.TokenPath, err)
 	}
-	var client *AuthenticatedClient
 	if hasToken {
-		token, err := ReadToken(confFile.TokenPath)
+		token, err = ReadToken(confFile.TokenPath)
 		if err != nil {
-			return &AuthenticatedClient{}, fmt.Errorf("found token at '%v' but unable to read token with error '%v'", confFile.TokenPath, err)
+			return "", "", ClientInfo{}, fmt.Errorf("found token at '%v' but unable to read token with error '%v'", confFile.TokenPath, err)
 		}
-		return AuthenticateToken(token, env.Verbose)
+		return confDir, token, ClientInfo{}, nil
 	}
 	hasSa, err := confFile.HasServiceAccount()
 	if err != nil {
-		return &AuthenticatedClient{}, fmt.Errorf("unable to read service account file '%v' with error '%v'", confFile.SaPath, err)
+		return "", "", ClientInfo{}, fmt.Errorf("unable to read service account file '%v' with error '%v'", confFile.SaPath, err)
 	}
 	if !hasSa {
-		return &AuthenticatedClient{}, fmt.Errorf("unable to access any file for directory `%v`, run astra-cli login first", confDir)
+		return "", "", ClientInfo{}, fmt.Errorf("unable to access any file for directory `%v`, run astra-cli login first", confDir)
 	}
-	clientInfo, err := ReadLogin(confFile.SaPath)
+	clientInfo, err = ReadLogin(confFile.SaPath)
+	if err != nil {
+		return "", "", ClientInfo{}, err
+	}
+	return confDir, "", clientInfo, nil
+}
+
+// Login logs into the Astra DevOps API using the local configuration provided by the 'astra-cli login' command
+func (c *Creds) Login() (Client, error) {
+	confDir, token, clientInfo, err := c.stored()
 	if err != nil {
 		return &AuthenticatedClient{}, err
 	}
+	var client *AuthenticatedClient
+	if token != "" {
+		client, err = AuthenticateToken(token, env.Verbose)
+		if err != nil {
+			return &AuthenticatedClient{}, err
+		}
+		return decorate(client, confDir)
+	}
 	client, err = Authenticate(clientInfo, env.Verbose)
 	if err != nil {
 		return &AuthenticatedClient{}, fmt.Errorf("authenticate failed with error %v", err)
 	}
+	return decorate(client, confDir)
+}
+
+// decorate applies the dry run and audit settings to the client
+func decorate(client *AuthenticatedClient, confDir string) (Client, error) {
+	c, err := withDryRun(client)
+	if err != nil {
+		return c, err
+	}
+	return withAudit(c, confDir), nil
+}
+
+// withAudit records the calls that change something in the audit log of the config directory. A dry run
+// changes nothing so it is not recorded
+func withAudit(client Client, confDir string) Client {
+	if env.DryRun {
+		return client
+	}
+	return NewAuditedClient(client, audit.NewLog(confDir), Env, env.Command, os.Stderr)
+}
+
+// withDryRun switches the client to only print the requests that change something when --dry-run is used
+func withDryRun(client *AuthenticatedClient) (Client, error) {
+	if env.DryRun {
+		if err := client.EnableDryRun(os.Stdout); err != nil {
+			return &AuthenticatedClient{}, err
+		}
+	}
 	return client, nil
 }
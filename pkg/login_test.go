@@ -91,6 +91,23 @@ func TestLoginValidToken(t *testing.T) {
 	}
 }
 
+func TestStoredToken(t *testing.T) {
+	valid := func() (string, error) { return path.Join("testdata", "with_token"), nil }
+	creds := &Creds{
+		GetHomeFunc: valid,
+	}
+	token, clientInfo, err := creds.Stored()
+	if err != nil {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if token == "" {
+		t.Error("expected the token to be read")
+	}
+	if clientInfo != (ClientInfo{}) {
+		t.Errorf("expected no service account but was %v", clientInfo)
+	}
+}
+
 func TestLoginWithInvalidSA(t *testing.T) {
 	invalid := func() (string, error) { return path.Join("testdata", "with_invalid_sa"), nil }
 	creds := &Creds{
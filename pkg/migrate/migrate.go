@@ -0,0 +1,243 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package migrate finds versioned CQL migrations and works out which ones to apply or revert
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// downSuffix marks the file that reverts the migration of the same version
+const downSuffix = ".down.cql"
+
+// fileName matches 0001_create_users.cql and 2_add_index.down.cql
+var fileName = regexp.MustCompile(`^(\d+)[_-](.+?)(\.down)?\.cql$`)
+
+// Migration is a numbered .cql file and the optional .down.cql file reverting it
+type Migration struct {
+	Version  int64
+	Name     string
+	File     string
+	DownFile string
+	Checksum string
+}
+
+// Load reads the migrations of the directory sorted by version. Two files with the same version are an error,
+// other files are ignored
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations in '%v' with error %v", dir, err)
+	}
+	byVersion := make(map[int64]*Migration)
+	downs := make(map[int64]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version in '%v' with error %v", e.Name(), err)
+		}
+		file := filepath.Join(dir, e.Name())
+		if match[3] != "" {
+			if other, ok := downs[version]; ok {
+				return nil, fmt.Errorf("version %v has two down files '%v' and '%v'", version, other, file)
+			}
+			downs[version] = file
+			continue
+		}
+		if other, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("version %v is used by both '%v' and '%v'", version, other.File, file)
+		}
+		sum, err := Checksum(file)
+		if err != nil {
+			return nil, err
+		}
+		byVersion[version] = &Migration{Version: version, Name: match[2], File: file, Checksum: sum}
+	}
+	var migrations []Migration
+	for version, file := range downs {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down file '%v' has no migration with version %v", file, version)
+		}
+		m.DownFile = file
+	}
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Checksum is the sha256 of the file content
+func Checksum(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read '%v' with error %v", file, err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Applied is a migration recorded in the tracking table
+type Applied struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	Checksum  string `json:"checksum"`
+	AppliedAt string `json:"appliedAt"`
+}
+
+// states of a migration in Status
+const (
+	StateApplied  = "applied"
+	StatePending  = "pending"
+	StateModified = "modified"
+	StateMissing  = "missing"
+)
+
+// Status is the state of one version, applied versions whose file is gone are missing
+type Status struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	AppliedAt string `json:"appliedAt,omitempty"`
+}
+
+// Statuses merges the files and the applied versions sorted by version
+func Statuses(migrations []Migration, applied []Applied) []Status {
+	byVersion := appliedByVersion(applied)
+	seen := make(map[int64]bool)
+	var statuses []Status
+	for _, m := range migrations {
+		seen[m.Version] = true
+		s := Status{Version: m.Version, Name: m.Name, State: StatePending}
+		if a, ok := byVersion[m.Version]; ok {
+			s.State = StateApplied
+			s.AppliedAt = a.AppliedAt
+			if a.Checksum != m.Checksum {
+				s.State = StateModified
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	for _, a := range applied {
+		if !seen[a.Version] {
+			statuses = append(statuses, Status{Version: a.Version, Name: a.Name, State: StateMissing, AppliedAt: a.AppliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses
+}
+
+// Verify fails when an applied file was changed since it was applied
+func Verify(migrations []Migration, applied []Applied) error {
+	var modified []string
+	for _, s := range Statuses(migrations, applied) {
+		if s.State == StateModified {
+			modified = append(modified, fmt.Sprintf("%v_%v", s.Version, s.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migration(s) %v were modified since they were applied, restore them or add a new migration instead", strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// Up returns the pending migrations up to and including target in order, a target of 0 is the latest version.
+// A pending migration older than the latest applied one is an error since it would run out of order
+func Up(migrations []Migration, applied []Applied, target int64) ([]Migration, error) {
+	if err := Verify(migrations, applied); err != nil {
+		return nil, err
+	}
+	byVersion := appliedByVersion(applied)
+	var latest int64
+	for _, a := range applied {
+		if a.Version > latest {
+			latest = a.Version
+		}
+	}
+	if target != 0 && target < latest {
+		return nil, fmt.Errorf("target %v is older than the latest applied version %v, use down instead", target, latest)
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if _, ok := byVersion[m.Version]; ok || (target != 0 && m.Version > target) {
+			continue
+		}
+		if m.Version < latest {
+			return nil, fmt.Errorf("migration %v_%v is older than the latest applied version %v and would run out of order", m.Version, m.Name, latest)
+		}
+		pending = append(pending, m)
+	}
+	return pending, nil
+}
+
+// Down returns the applied migrations newer than target, newest first. A negative target reverts only the latest
+// applied migration. Every migration reverted needs a down file
+func Down(migrations []Migration, applied []Applied, target int64) ([]Migration, error) {
+	if err := Verify(migrations, applied); err != nil {
+		return nil, err
+	}
+	files := make(map[int64]Migration)
+	for _, m := range migrations {
+		files[m.Version] = m
+	}
+	sorted := append([]Applied{}, applied...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version > sorted[j].Version
+	})
+	latestOnly := target < 0
+	var revert []Migration
+	for _, a := range sorted {
+		if (latestOnly && len(revert) == 1) || (!latestOnly && a.Version <= target) {
+			break
+		}
+		m, ok := files[a.Version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %v_%v has no file to revert it", a.Version, a.Name)
+		}
+		if m.DownFile == "" {
+			return nil, fmt.Errorf("migration %v_%v has no %v file to revert it", m.Version, m.Name, downSuffix)
+		}
+		revert = append(revert, m)
+	}
+	return revert, nil
+}
+
+func appliedByVersion(applied []Applied) map[int64]Applied {
+	byVersion := make(map[int64]Applied)
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+	return byVersion
+}
@@ -0,0 +1,172 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package migrate finds versioned CQL migrations and works out which ones to apply or revert
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeMigrations writes the files into a new directory
+func writeMigrations(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var schema = map[string]string{
+	"0001_create_users.cql":      "CREATE TABLE users (id int PRIMARY KEY);",
+	"0001_create_users.down.cql": "DROP TABLE users;",
+	"0002_add_name.cql":          "ALTER TABLE users ADD name text;",
+	"10-add_index.cql":           "CREATE INDEX ON users (name);",
+	"10-add_index.down.cql":      "DROP INDEX users_name_idx;",
+	"README.md":                  "not a migration",
+}
+
+func loadSchema(t *testing.T) []Migration {
+	migrations, err := Load(writeMigrations(t, schema))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return migrations
+}
+
+func applied(migrations []Migration, versions ...int64) []Applied {
+	var a []Applied
+	for _, m := range migrations {
+		for _, v := range versions {
+			if m.Version == v {
+				a = append(a, Applied{Version: v, Name: m.Name, Checksum: m.Checksum})
+			}
+		}
+	}
+	return a
+}
+
+func versions(migrations []Migration) []int64 {
+	var v []int64
+	for _, m := range migrations {
+		v = append(v, m.Version)
+	}
+	return v
+}
+
+func TestLoad(t *testing.T) {
+	migrations := loadSchema(t)
+	if !reflect.DeepEqual(versions(migrations), []int64{1, 2, 10}) {
+		t.Fatalf("unexpected versions %v", versions(migrations))
+	}
+	if migrations[0].Name != "create_users" || filepath.Base(migrations[0].DownFile) != "0001_create_users.down.cql" {
+		t.Errorf("unexpected migration %v", migrations[0])
+	}
+	if migrations[1].DownFile != "" || len(migrations[1].Checksum) != 64 {
+		t.Errorf("unexpected migration %v", migrations[1])
+	}
+}
+
+func TestLoadDuplicateVersion(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{"1_a.cql": "", "001_b.cql": ""})
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "version 1 is used by both") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLoadDownWithoutUp(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{"1_a.down.cql": ""})
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "has no migration with version 1") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	migrations := loadSchema(t)
+	a := append(applied(migrations, 1, 2), Applied{Version: 5, Name: "gone"})
+	a[1].Checksum = "changed"
+	var states []string
+	for _, s := range Statuses(migrations, a) {
+		states = append(states, s.State)
+	}
+	expected := []string{StateApplied, StateModified, StateMissing, StatePending}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("expected %v but was %v", expected, states)
+	}
+}
+
+func TestUp(t *testing.T) {
+	migrations := loadSchema(t)
+	pending, err := Up(migrations, applied(migrations, 1), 0)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(versions(pending), []int64{2, 10}) {
+		t.Errorf("unexpected pending %v", versions(pending))
+	}
+	pending, err = Up(migrations, nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(versions(pending), []int64{1, 2}) {
+		t.Errorf("unexpected pending up to 2 %v", versions(pending))
+	}
+}
+
+func TestUpRefusesModified(t *testing.T) {
+	migrations := loadSchema(t)
+	a := applied(migrations, 1)
+	a[0].Checksum = "changed"
+	if _, err := Up(migrations, a, 0); err == nil || !strings.Contains(err.Error(), "1_create_users were modified") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestUpOutOfOrder(t *testing.T) {
+	migrations := loadSchema(t)
+	if _, err := Up(migrations, applied(migrations, 1, 10), 0); err == nil || !strings.Contains(err.Error(), "out of order") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDown(t *testing.T) {
+	migrations := loadSchema(t)
+	revert, err := Down(migrations, applied(migrations, 1, 10), -1)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(versions(revert), []int64{10}) {
+		t.Errorf("expected only the latest to be reverted but was %v", versions(revert))
+	}
+	revert, err = Down(migrations, applied(migrations, 1, 10), 0)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(versions(revert), []int64{10, 1}) {
+		t.Errorf("expected everything reverted newest first but was %v", versions(revert))
+	}
+}
+
+func TestDownWithoutDownFile(t *testing.T) {
+	migrations := loadSchema(t)
+	if _, err := Down(migrations, applied(migrations, 1, 2), 0); err == nil || !strings.Contains(err.Error(), "2_add_name has no .down.cql") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package migrate finds versioned CQL migrations and works out which ones to apply or revert
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+)
+
+// Table records the applied migrations in the keyspace they were applied to
+const Table = "astra_schema_migrations"
+
+// Session runs statements, *cql.Session is one
+type Session interface {
+	Query(statement string) (cql.Result, error)
+}
+
+// Tracker applies and reverts migrations and records them in the tracking table of the keyspace
+type Tracker struct {
+	session  Session
+	keyspace string
+	table    string
+}
+
+// NewTracker tracks the migrations of the keyspace
+func NewTracker(session Session, keyspace string) *Tracker {
+	return &Tracker{session: session, keyspace: keyspace, table: cql.QuoteIdentifier(keyspace) + "." + Table}
+}
+
+// EnsureTable creates the tracking table when it does not exist yet
+func (t *Tracker) EnsureTable() error {
+	if _, err := t.session.Query("CREATE TABLE IF NOT EXISTS " + t.table + " (version bigint PRIMARY KEY, name text, checksum text, applied_at timestamp)"); err != nil {
+		return fmt.Errorf("unable to create %v with error %v", t.table, err)
+	}
+	return nil
+}
+
+// Applied reads the tracking table, nothing is applied when the table does not exist yet
+func (t *Tracker) Applied() ([]Applied, error) {
+	tables, err := t.session.Query(fmt.Sprintf("SELECT table_name FROM system_schema.tables WHERE keyspace_name = %v AND table_name = %v",
+		cql.QuoteString(t.keyspace), cql.QuoteString(Table)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to look for %v with error %v", t.table, err)
+	}
+	if len(tables.Rows) == 0 {
+		return nil, nil
+	}
+	result, err := t.session.Query("SELECT version, name, checksum, applied_at FROM " + t.table)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %v with error %v", t.table, err)
+	}
+	var applied []Applied
+	for _, row := range result.Rows {
+		if len(row) != 4 {
+			return nil, fmt.Errorf("unexpected row %v in %v", row, t.table)
+		}
+		version, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version '%v' in %v with error %v", row[0], t.table, err)
+		}
+		applied = append(applied, Applied{Version: version, Name: row[1], Checksum: row[2], AppliedAt: row[3]})
+	}
+	return applied, nil
+}
+
+// Apply runs the statements of the migration and records it. CQL has no transactions, when a statement fails the
+// ones before it stay applied and the migration is not recorded
+func (t *Tracker) Apply(m Migration) error {
+	if err := t.run(m, m.File); err != nil {
+		return err
+	}
+	insert := fmt.Sprintf("INSERT INTO %v (version, name, checksum, applied_at) VALUES (%v, %v, %v, toTimestamp(now()))",
+		t.table, m.Version, cql.QuoteString(m.Name), cql.QuoteString(m.Checksum))
+	if _, err := t.session.Query(insert); err != nil {
+		return fmt.Errorf("migration %v_%v was applied but could not be recorded in %v with error %v", m.Version, m.Name, t.table, err)
+	}
+	return nil
+}
+
+// Revert runs the down file of the migration and removes it from the tracking table
+func (t *Tracker) Revert(m Migration) error {
+	if err := t.run(m, m.DownFile); err != nil {
+		return err
+	}
+	if _, err := t.session.Query(fmt.Sprintf("DELETE FROM %v WHERE version = %v", t.table, m.Version)); err != nil {
+		return fmt.Errorf("migration %v_%v was reverted but could not be removed from %v with error %v", m.Version, m.Name, t.table, err)
+	}
+	return nil
+}
+
+func (t *Tracker) run(m Migration, file string) error {
+	script, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read '%v' with error %v", file, err)
+	}
+	statements := cql.Split(string(script))
+	for i, s := range statements {
+		if _, err := t.session.Query(s.Text); err != nil {
+			return fmt.Errorf("%v failed at line %v with error %v, %v of %v statement(s) before it were run", file, s.Line, err, i, len(statements))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package migrate finds versioned CQL migrations and works out which ones to apply or revert
+package migrate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+)
+
+// fakeSession records the statements, fails the ones containing fail and answers selects with rows. The tracking
+// table exists when there are rows
+type fakeSession struct {
+	statements []string
+	rows       [][]string
+}
+
+func (f *fakeSession) Query(statement string) (cql.Result, error) {
+	f.statements = append(f.statements, statement)
+	if strings.Contains(statement, "fail") {
+		return cql.Result{}, errors.New("syntax error")
+	}
+	if strings.HasPrefix(statement, "SELECT") {
+		return cql.Result{Kind: "rows", Rows: f.rows}, nil
+	}
+	return cql.Result{Kind: "void"}, nil
+}
+
+func TestTrackerApplyAndRevert(t *testing.T) {
+	migrations := loadSchema(t)
+	session := &fakeSession{}
+	tracker := NewTracker(session, "ks1")
+	if err := tracker.EnsureTable(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Apply(migrations[0]); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := tracker.Revert(migrations[0]); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{
+		`CREATE TABLE IF NOT EXISTS "ks1".astra_schema_migrations (version bigint PRIMARY KEY, name text, checksum text, applied_at timestamp)`,
+		"CREATE TABLE users (id int PRIMARY KEY)",
+		`INSERT INTO "ks1".astra_schema_migrations (version, name, checksum, applied_at) VALUES (1, 'create_users', '` + migrations[0].Checksum + `', toTimestamp(now()))`,
+		"DROP TABLE users",
+		`DELETE FROM "ks1".astra_schema_migrations WHERE version = 1`,
+	}
+	if !reflect.DeepEqual(session.statements, expected) {
+		t.Errorf("expected\n%v\nbut was\n%v", strings.Join(expected, "\n"), strings.Join(session.statements, "\n"))
+	}
+}
+
+func TestTrackerApplyFailureNotRecorded(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{"1_a.cql": "CREATE TABLE a (id int PRIMARY KEY);\n\nfail;\nCREATE TABLE b (id int PRIMARY KEY);"})
+	migrations, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := &fakeSession{}
+	err = NewTracker(session, "ks1").Apply(migrations[0])
+	if err == nil || !strings.Contains(err.Error(), "failed at line 3 with error syntax error, 1 of 3 statement(s) before it were run") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(session.statements) != 2 {
+		t.Errorf("expected no statement after the failure but was %v", session.statements)
+	}
+}
+
+func TestTrackerApplied(t *testing.T) {
+	session := &fakeSession{rows: [][]string{{"2", "add_name", "abc", "2022-01-09 09:16:06.016Z"}}}
+	applied, err := NewTracker(session, "ks1").Applied()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []Applied{{Version: 2, Name: "add_name", Checksum: "abc", AppliedAt: "2022-01-09 09:16:06.016Z"}}
+	if !reflect.DeepEqual(applied, expected) {
+		t.Errorf("expected %v but was %v", expected, applied)
+	}
+}
+
+func TestTrackerAppliedInvalidVersion(t *testing.T) {
+	session := &fakeSession{rows: [][]string{{"two", "add_name", "abc", ""}}}
+	if _, err := NewTracker(session, "ks1").Applied(); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
+
+func TestTrackerAppliedNoTable(t *testing.T) {
+	session := &fakeSession{}
+	applied, err := NewTracker(session, "ks1").Applied()
+	if err != nil || applied != nil {
+		t.Errorf("expected nothing applied but was %v %v", applied, err)
+	}
+	expected := []string{"SELECT table_name FROM system_schema.tables WHERE keyspace_name = 'ks1' AND table_name = 'astra_schema_migrations'"}
+	if !reflect.DeepEqual(session.statements, expected) {
+		t.Errorf("expected only the table lookup but was %v", session.statements)
+	}
+}
@@ -0,0 +1,181 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package protect keeps databases listed in a local policy file from being deleted, parked or resized by accident
+package protect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the policy file read from the config directory
+	FileName = "protection.yaml"
+	// RepoFileName is the policy file looked up from the working directory towards the root
+	RepoFileName = ".astra-protection.yaml"
+	// OverrideLogName is where overrides are recorded in the config directory
+	OverrideLogName = "protection-overrides.log"
+)
+
+// Rules are the protected databases of one environment. Names are glob patterns as understood by path.Match
+type Rules struct {
+	IDs   []string `json:"ids" yaml:"ids"`
+	Names []string `json:"names" yaml:"names"`
+}
+
+// Policy maps an environment (prod, dev, test) to its protected databases
+type Policy struct {
+	Environments map[string]Rules `json:"environments" yaml:"environments"`
+}
+
+// Empty is true when the environment has nothing protected
+func (p Policy) Empty(env string) bool {
+	rules := p.Environments[env]
+	return len(rules.IDs) == 0 && len(rules.Names) == 0
+}
+
+// Match returns the rule protecting the database in the environment, it is false when the database is not protected
+func (p Policy) Match(env string, db astra.Database) (string, bool) {
+	rules := p.Environments[env]
+	for _, id := range rules.IDs {
+		if id == db.Id {
+			return "id " + id, true
+		}
+	}
+	if db.Info.Name == nil {
+		return "", false
+	}
+	for _, pattern := range rules.Names {
+		if ok, _ := path.Match(pattern, *db.Info.Name); ok {
+			return "name " + pattern, true
+		}
+	}
+	return "", false
+}
+
+// merge adds the rules of other to the policy
+func (p *Policy) merge(other Policy) {
+	if p.Environments == nil {
+		p.Environments = make(map[string]Rules)
+	}
+	for env, rules := range other.Environments {
+		existing := p.Environments[env]
+		existing.IDs = append(existing.IDs, rules.IDs...)
+		existing.Names = append(existing.Names, rules.Names...)
+		p.Environments[env] = existing
+	}
+}
+
+// Parse reads a policy in yaml or json and checks the name patterns are valid
+func Parse(b []byte) (Policy, error) {
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && err != io.EOF {
+		return Policy{}, fmt.Errorf("unable to parse protection policy with error '%v'", err)
+	}
+	for env, rules := range p.Environments {
+		for _, pattern := range rules.Names {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return Policy{}, fmt.Errorf("invalid name pattern '%v' for env %v with error '%v'", pattern, env, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Load merges the policy of the config directory with the closest repo-level policy found from workDir upwards.
+// Missing files are not an error, an unreadable or invalid file is
+func Load(confDir, workDir string) (Policy, error) {
+	var p Policy
+	files := []string{path.Join(confDir, FileName)}
+	if repoFile, ok := findUp(workDir, RepoFileName); ok {
+		files = append(files, repoFile)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return Policy{}, fmt.Errorf("unable to read protection policy '%v' with error '%v'", f, err)
+		}
+		filePolicy, err := Parse(b)
+		if err != nil {
+			return Policy{}, fmt.Errorf("%v in '%v'", err, f)
+		}
+		p.merge(filePolicy)
+	}
+	return p, nil
+}
+
+func findUp(dir, name string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	dir = filepath.Clean(dir)
+	for {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Override is one use of --override-protection
+type Override struct {
+	Time      time.Time
+	User      string
+	Env       string
+	Operation string
+	ID        string
+	Name      string
+	Rule      string
+}
+
+// String is the line written to the override log
+func (o Override) String() string {
+	return fmt.Sprintf("%v user=%v env=%v operation=%v id=%v name=%v rule=%q",
+		o.Time.UTC().Format(time.RFC3339), o.User, o.Env, o.Operation, o.ID, o.Name, o.Rule)
+}
+
+// Record appends the override to the log file, creating it readable only by the user
+func Record(logFile string, o Override) error {
+	if err := os.MkdirAll(filepath.Dir(logFile), 0700); err != nil {
+		return fmt.Errorf("unable to create directory for override log with error '%v'", err)
+	}
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open override log '%v' with error '%v'", logFile, err)
+	}
+	if _, err := fmt.Fprintln(f, o.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write override log '%v' with error '%v'", logFile, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,144 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package protect keeps databases listed in a local policy file from being deleted, parked or resized by accident
+package protect
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+func db(id, name string) astra.Database {
+	return astra.Database{Id: id, Info: astra.DatabaseInfo{Name: astra.StringPtr(name)}}
+}
+
+func TestMatch(t *testing.T) {
+	p, err := Parse([]byte(`
+environments:
+  prod:
+    ids: [abc]
+    names: ["prod-*"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if rule, ok := p.Match("prod", db("abc", "other")); !ok || rule != "id abc" {
+		t.Errorf("expected id match but was '%v' %v", rule, ok)
+	}
+	if rule, ok := p.Match("prod", db("xyz", "prod-users")); !ok || rule != "name prod-*" {
+		t.Errorf("expected name match but was '%v' %v", rule, ok)
+	}
+	if _, ok := p.Match("prod", db("xyz", "staging-users")); ok {
+		t.Error("expected no match")
+	}
+	if _, ok := p.Match("dev", db("abc", "prod-users")); ok {
+		t.Error("expected rules of prod not to apply to dev")
+	}
+	if p.Empty("prod") || !p.Empty("dev") {
+		t.Error("expected only prod to have rules")
+	}
+}
+
+func TestParseInvalidPattern(t *testing.T) {
+	_, err := Parse([]byte(`
+environments:
+  prod:
+    names: ["prod-["]
+`))
+	if err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse([]byte(`
+environments:
+  prod:
+    databases: [abc]
+`))
+	if err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+}
+
+func TestLoadMergesConfigAndRepo(t *testing.T) {
+	confDir := t.TempDir()
+	repo := t.TempDir()
+	workDir := path.Join(repo, "sub", "dir")
+	if err := os.MkdirAll(workDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(confDir, FileName), []byte("environments:\n  prod:\n    ids: [abc]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(repo, RepoFileName), []byte("environments:\n  prod:\n    names: [\"app-*\"]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(confDir, workDir)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := p.Match("prod", db("abc", "x")); !ok {
+		t.Error("expected the config directory rule")
+	}
+	if _, ok := p.Match("prod", db("def", "app-1")); !ok {
+		t.Error("expected the repo rule")
+	}
+}
+
+func TestLoadMissingFiles(t *testing.T) {
+	p, err := Load(t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !p.Empty("prod") {
+		t.Error("expected an empty policy")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	logFile := path.Join(t.TempDir(), "logs", OverrideLogName)
+	o := Override{
+		Time:      time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		User:      "jdoe",
+		Env:       "prod",
+		Operation: "delete",
+		ID:        "abc",
+		Name:      "app",
+		Rule:      "id abc",
+	}
+	for i := 0; i < 2; i++ {
+		if err := Record(logFile, o); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	b, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines but was %v", len(lines))
+	}
+	expected := `2021-01-02T03:04:05Z user=jdoe env=prod operation=delete id=abc name=app rule="id abc"`
+	if lines[0] != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, lines[0])
+	}
+}
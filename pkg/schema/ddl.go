@@ -0,0 +1,213 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package schema reads the definitions of keyspaces from system_schema and writes them as CQL DDL
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+)
+
+// unquoted are the names that do not need quotes
+var unquoted = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// identifierWords finds the names in a type like frozen<map<text, "Address">>
+var identifierWords = regexp.MustCompile(`"(?:[^"]|"")+"|[A-Za-z0-9_]+`)
+
+// reserved are the CQL keywords that have to be quoted to be used as names
+var reserved = map[string]bool{
+	"add": true, "allow": true, "alter": true, "and": true, "apply": true, "asc": true, "authorize": true, "batch": true,
+	"begin": true, "by": true, "columnfamily": true, "create": true, "delete": true, "desc": true, "describe": true,
+	"drop": true, "entries": true, "execute": true, "from": true, "full": true, "grant": true, "if": true, "in": true,
+	"index": true, "infinity": true, "insert": true, "into": true, "is": true, "keyspace": true, "limit": true,
+	"materialized": true, "mbean": true, "mbeans": true, "modify": true, "nan": true, "norecursive": true, "not": true,
+	"null": true, "of": true, "on": true, "or": true, "order": true, "primary": true, "rename": true, "replace": true,
+	"revoke": true, "schema": true, "select": true, "set": true, "table": true, "to": true, "token": true,
+	"truncate": true, "unlogged": true, "unset": true, "update": true, "use": true, "using": true, "view": true,
+	"where": true, "with": true,
+}
+
+// File is a file of a dump split by table
+type File struct {
+	Name    string
+	Content string
+}
+
+// Dump is the DDL of the keyspace: the keyspace, its types in dependency order, then its tables with their indexes
+func Dump(ks Keyspace) string {
+	var b strings.Builder
+	b.WriteString(keyspaceDDL(ks))
+	for _, t := range ks.Types {
+		b.WriteString("\n")
+		b.WriteString(typeDDL(ks.Name, t))
+	}
+	for _, t := range ks.Tables {
+		b.WriteString("\n")
+		b.WriteString(tableDDL(ks.Name, t))
+	}
+	return b.String()
+}
+
+// DumpFiles splits the DDL of the keyspace in <keyspace>/keyspace.cql with the keyspace and its types, and a
+// <keyspace>/tables/<table>.cql per table with its indexes. Tables have their own directory as a table can be named keyspace
+func DumpFiles(ks Keyspace) []File {
+	var b strings.Builder
+	b.WriteString(keyspaceDDL(ks))
+	for _, t := range ks.Types {
+		b.WriteString("\n")
+		b.WriteString(typeDDL(ks.Name, t))
+	}
+	files := []File{{Name: ks.Name + "/keyspace.cql", Content: b.String()}}
+	for _, t := range ks.Tables {
+		files = append(files, File{Name: ks.Name + "/tables/" + t.Name + ".cql", Content: tableDDL(ks.Name, t)})
+	}
+	return files
+}
+
+func keyspaceDDL(ks Keyspace) string {
+	return fmt.Sprintf("CREATE KEYSPACE %v WITH replication = %v AND durable_writes = %v;\n", identifier(ks.Name), ks.Replication, ks.DurableWrites)
+}
+
+func typeDDL(keyspace string, t Type) string {
+	var fields []string
+	for _, f := range t.Fields {
+		fields = append(fields, fmt.Sprintf("    %v %v", identifier(f.Name), f.Type))
+	}
+	return fmt.Sprintf("CREATE TYPE %v.%v (\n%v\n);\n", identifier(keyspace), identifier(t.Name), strings.Join(fields, ",\n"))
+}
+
+func tableDDL(keyspace string, t Table) string {
+	var lines, partition, clustering, order []string
+	for _, c := range t.Columns {
+		line := fmt.Sprintf("    %v %v", identifier(c.Name), c.Type)
+		switch c.Kind {
+		case "partition_key":
+			partition = append(partition, identifier(c.Name))
+		case "clustering":
+			clustering = append(clustering, identifier(c.Name))
+			order = append(order, identifier(c.Name)+" "+strings.ToUpper(c.ClusteringOrder))
+		case "static":
+			line += " static"
+		}
+		lines = append(lines, line)
+	}
+	key := strings.Join(partition, ", ")
+	if len(partition) > 1 {
+		key = "(" + key + ")"
+	}
+	key = strings.Join(append([]string{key}, clustering...), ", ")
+	lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%v)", key))
+	var options []string
+	if len(order) > 0 {
+		options = append(options, fmt.Sprintf("CLUSTERING ORDER BY (%v)", strings.Join(order, ", ")))
+	}
+	if t.Comment != "" {
+		options = append(options, "comment = "+cql.QuoteString(t.Comment))
+	}
+	if t.DefaultTimeToLive != 0 {
+		options = append(options, fmt.Sprintf("default_time_to_live = %v", t.DefaultTimeToLive))
+	}
+	ddl := fmt.Sprintf("CREATE TABLE %v.%v (\n%v\n)", identifier(keyspace), identifier(t.Name), strings.Join(lines, ",\n"))
+	if len(options) > 0 {
+		ddl += " WITH " + strings.Join(options, "\n    AND ")
+	}
+	ddl += ";\n"
+	for _, index := range t.Indexes {
+		ddl += indexDDL(keyspace, t.Name, index)
+	}
+	return ddl
+}
+
+// indexDDL creates the index on its target, custom indexes keep their class and their other options
+func indexDDL(keyspace, table string, index Index) string {
+	on := fmt.Sprintf("%v ON %v.%v (%v)", identifier(index.Name), identifier(keyspace), identifier(table), index.Options["target"])
+	if index.Kind != "CUSTOM" {
+		return fmt.Sprintf("CREATE INDEX %v;\n", on)
+	}
+	ddl := fmt.Sprintf("CREATE CUSTOM INDEX %v USING %v", on, cql.QuoteString(index.Options["class_name"]))
+	var keys []string
+	for k := range index.Options {
+		if k != "target" && k != "class_name" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		var pairs []string
+		for _, k := range keys {
+			pairs = append(pairs, cql.QuoteString(k)+": "+cql.QuoteString(index.Options[k]))
+		}
+		ddl += " WITH OPTIONS = {" + strings.Join(pairs, ", ") + "}"
+	}
+	return ddl + ";\n"
+}
+
+// identifier is the name as is when it needs no quotes, quoted otherwise
+func identifier(name string) string {
+	if unquoted.MatchString(name) && !reserved[name] {
+		return name
+	}
+	return cql.QuoteIdentifier(name)
+}
+
+// sortTypes orders the types so each one comes after the types it uses, by name otherwise
+func sortTypes(types []Type) []Type {
+	byName := make(map[string]Type, len(types))
+	for _, t := range types {
+		byName[t.Name] = t
+	}
+	uses := make(map[string][]string, len(types))
+	for _, t := range types {
+		for _, f := range t.Fields {
+			for _, word := range identifierWords.FindAllString(f.Type, -1) {
+				name := word
+				if strings.HasPrefix(word, `"`) {
+					name = strings.ReplaceAll(word[1:len(word)-1], `""`, `"`)
+				}
+				if _, ok := byName[name]; ok && name != t.Name {
+					uses[t.Name] = append(uses[t.Name], name)
+				}
+			}
+		}
+	}
+	var sorted []Type
+	done := make(map[string]bool, len(types))
+	var visit func(name string)
+	visit = func(name string) {
+		if done[name] {
+			return
+		}
+		done[name] = true
+		deps := uses[name]
+		sort.Strings(deps)
+		for _, dep := range deps {
+			visit(dep)
+		}
+		sorted = append(sorted, byName[name])
+	}
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(name)
+	}
+	return sorted
+}
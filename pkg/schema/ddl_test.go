@@ -0,0 +1,111 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package schema reads the definitions of keyspaces from system_schema and writes them as CQL DDL
+package schema
+
+import (
+	"testing"
+)
+
+const shopDDL = `CREATE KEYSPACE shop WITH replication = {'class': 'org.apache.cassandra.locator.NetworkTopologyStrategy', 'us-east1': '3'} AND durable_writes = true;
+
+CREATE TYPE shop.address (
+    street text,
+    zip int
+);
+
+CREATE TYPE shop.customer (
+    name text,
+    home frozen<address>
+);
+
+CREATE TABLE shop."Items" (
+    sku text,
+    "order" int,
+    PRIMARY KEY (sku)
+);
+
+CREATE TABLE shop.orders (
+    customer_id uuid,
+    region text,
+    placed timestamp,
+    id timeuuid,
+    buyer frozen<customer> static,
+    total decimal,
+    PRIMARY KEY ((customer_id, region), placed, id)
+) WITH CLUSTERING ORDER BY (placed DESC, id ASC)
+    AND comment = 'orders by customer'
+    AND default_time_to_live = 86400;
+CREATE INDEX orders_buyer_idx ON shop.orders (buyer);
+CREATE CUSTOM INDEX orders_total_idx ON shop.orders (total) USING 'StorageAttachedIndex' WITH OPTIONS = {'case_sensitive': 'false'};
+`
+
+func TestDump(t *testing.T) {
+	ks, err := Load(shop(), "shop")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if ddl := Dump(ks); ddl != shopDDL {
+		t.Errorf("expected\n%v\nbut was\n%v", shopDDL, ddl)
+	}
+}
+
+func TestDumpFiles(t *testing.T) {
+	ks, err := Load(shop(), "shop")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	files := DumpFiles(ks)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files but was %v", files)
+	}
+	names := []string{"shop/keyspace.cql", "shop/tables/Items.cql", "shop/tables/orders.cql"}
+	for i, name := range names {
+		if files[i].Name != name {
+			t.Errorf("expected %v but was %v", name, files[i].Name)
+		}
+	}
+	joined := files[0].Content + "\n" + files[1].Content + "\n" + files[2].Content
+	if joined != shopDDL {
+		t.Errorf("expected the files to have the whole dump but was\n%v", joined)
+	}
+}
+
+func TestDumpFilesTableNamedKeyspace(t *testing.T) {
+	ks := Keyspace{Name: "shop", Tables: []Table{{Name: "keyspace", Columns: []Column{{Name: "id", Type: "uuid", Kind: "partition_key"}}}}}
+	files := DumpFiles(ks)
+	if len(files) != 2 || files[0].Name == files[1].Name {
+		t.Errorf("expected the table and the keyspace in different files but was %v", files)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	cases := map[string]string{"users": "users", "Users": `"Users"`, "select": `"select"`, "a b": `"a b"`, `x"y`: `"x""y"`, "_x": `"_x"`}
+	for name, expected := range cases {
+		if quoted := identifier(name); quoted != expected {
+			t.Errorf("expected %v for %v but was %v", expected, name, quoted)
+		}
+	}
+}
+
+func TestSortTypesQuotedNames(t *testing.T) {
+	types := sortTypes([]Type{
+		{Name: "a", Fields: []Field{{Name: "b", Type: `frozen<list<frozen<"B">>>`}}},
+		{Name: "B", Fields: []Field{{Name: "x", Type: "int"}}},
+	})
+	if types[0].Name != "B" || types[1].Name != "a" {
+		t.Errorf("expected B before a but was %v", types)
+	}
+}
@@ -0,0 +1,90 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package schema reads the definitions of keyspaces from system_schema and writes them as CQL DDL
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseList reads a list of text as the cql package prints it: ['a', 'b']
+func parseList(literal string) ([]string, error) {
+	items, err := parseItems(literal, '[', ']')
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse list %v with error %v", literal, err)
+	}
+	return items, nil
+}
+
+// parseMap reads a map of text to text as the cql package prints it: {'a': 'b'}
+func parseMap(literal string) (map[string]string, error) {
+	items, err := parseItems(literal, '{', '}')
+	if err != nil || len(items)%2 != 0 {
+		return nil, fmt.Errorf("unable to parse map %v with error %v", literal, err)
+	}
+	m := make(map[string]string, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		m[items[i]] = items[i+1]
+	}
+	return m, nil
+}
+
+// parseItems reads the quoted strings between start and end separated by commas, or colons in maps
+func parseItems(literal string, start, end byte) ([]string, error) {
+	s := strings.TrimSpace(literal)
+	if s == "null" {
+		return nil, nil
+	}
+	if len(s) < 2 || s[0] != start || s[len(s)-1] != end {
+		return nil, fmt.Errorf("expected %c...%c", start, end)
+	}
+	s = s[1 : len(s)-1]
+	var items []string
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case ' ', ',', ':':
+			i++
+		case '\'':
+			item, next, err := readQuoted(s, i)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			i = next
+		default:
+			return nil, fmt.Errorf("unexpected %q at %v", s[i], i)
+		}
+	}
+	return items, nil
+}
+
+// readQuoted reads the string literal starting at the quote at start, where two quotes are one, and returns the index after it
+func readQuoted(s string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated string at %v", start)
+}
@@ -0,0 +1,60 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package schema reads the definitions of keyspaces from system_schema and writes them as CQL DDL
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	items, err := parseList("['street', 'it''s', 'frozen<map<text, int>>']")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{"street", "it's", "frozen<map<text, int>>"}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %v but was %v", expected, items)
+	}
+	if items, err := parseList("[]"); err != nil || len(items) != 0 {
+		t.Errorf("expected an empty list but was %v %v", items, err)
+	}
+	if items, err := parseList("null"); err != nil || items != nil {
+		t.Errorf("expected no items for null but was %v %v", items, err)
+	}
+}
+
+func TestParseMap(t *testing.T) {
+	m, err := parseMap("{'class_name': 'StorageAttachedIndex', 'target': 'name, a'}")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := map[string]string{"class_name": "StorageAttachedIndex", "target": "name, a"}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v but was %v", expected, m)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, literal := range []string{"'a'", "['a", "['a', b]", "['unterminated]"} {
+		if _, err := parseList(literal); err == nil {
+			t.Errorf("expected error for %v", literal)
+		}
+	}
+	if _, err := parseMap("{'a'}"); err == nil {
+		t.Error("expected error for a key without value")
+	}
+}
@@ -0,0 +1,247 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package schema reads the definitions of keyspaces from system_schema and writes them as CQL DDL
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+)
+
+// Session runs CQL statements
+type Session interface {
+	Query(statement string) (cql.Result, error)
+}
+
+// Keyspace is a keyspace with its user defined types and tables
+type Keyspace struct {
+	Name          string
+	Replication   string
+	DurableWrites bool
+	Types         []Type
+	Tables        []Table
+}
+
+// Type is a user defined type
+type Type struct {
+	Name   string
+	Fields []Field
+}
+
+// Field is a field of a user defined type
+type Field struct {
+	Name string
+	Type string
+}
+
+// Table is a table with its columns, options and indexes
+type Table struct {
+	Name              string
+	Columns           []Column
+	Comment           string
+	DefaultTimeToLive int
+	Indexes           []Index
+}
+
+// Column is a column of a table, Kind is partition_key, clustering, regular or static
+type Column struct {
+	Name            string
+	Type            string
+	Kind            string
+	Position        int
+	ClusteringOrder string
+}
+
+// Index is a secondary index, custom indexes like storage attached indexes have a class_name option
+type Index struct {
+	Name    string
+	Kind    string
+	Options map[string]string
+}
+
+// Load reads the definition of the keyspace from system_schema, types, tables, columns and indexes are sorted
+func Load(session Session, keyspace string) (Keyspace, error) {
+	ks := Keyspace{Name: keyspace}
+	where := " WHERE keyspace_name = " + cql.QuoteString(keyspace)
+	rows, err := query(session, "SELECT replication, durable_writes FROM system_schema.keyspaces"+where)
+	if err != nil {
+		return ks, err
+	}
+	if len(rows) == 0 {
+		return ks, fmt.Errorf("keyspace '%v' not found", keyspace)
+	}
+	ks.Replication = rows[0]["replication"]
+	ks.DurableWrites = rows[0]["durable_writes"] != "false"
+	if ks.Types, err = loadTypes(session, where); err != nil {
+		return ks, err
+	}
+	if ks.Tables, err = loadTables(session, where); err != nil {
+		return ks, err
+	}
+	return ks, nil
+}
+
+func loadTypes(session Session, where string) ([]Type, error) {
+	rows, err := query(session, "SELECT type_name, field_names, field_types FROM system_schema.types"+where)
+	if err != nil {
+		return nil, err
+	}
+	var types []Type
+	for _, row := range rows {
+		names, err := parseList(row["field_names"])
+		if err != nil {
+			return nil, err
+		}
+		fieldTypes, err := parseList(row["field_types"])
+		if err != nil {
+			return nil, err
+		}
+		if len(names) != len(fieldTypes) {
+			return nil, fmt.Errorf("type %v has %v field names and %v field types", row["type_name"], len(names), len(fieldTypes))
+		}
+		t := Type{Name: row["type_name"]}
+		for i := range names {
+			t.Fields = append(t.Fields, Field{Name: names[i], Type: fieldTypes[i]})
+		}
+		types = append(types, t)
+	}
+	return sortTypes(types), nil
+}
+
+func loadTables(session Session, where string) ([]Table, error) {
+	rows, err := query(session, "SELECT table_name, comment, default_time_to_live FROM system_schema.tables"+where)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]*Table)
+	var names []string
+	for _, row := range rows {
+		ttl, err := strconv.Atoi(row["default_time_to_live"])
+		if err != nil && row["default_time_to_live"] != "null" {
+			return nil, fmt.Errorf("unable to read default_time_to_live of %v with error %v", row["table_name"], err)
+		}
+		comment := row["comment"]
+		if comment == "null" {
+			comment = ""
+		}
+		tables[row["table_name"]] = &Table{Name: row["table_name"], Comment: comment, DefaultTimeToLive: ttl}
+		names = append(names, row["table_name"])
+	}
+	if err := loadColumns(session, where, tables); err != nil {
+		return nil, err
+	}
+	if err := loadIndexes(session, where, tables); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var sorted []Table
+	for _, name := range names {
+		sorted = append(sorted, *tables[name])
+	}
+	return sorted, nil
+}
+
+func loadColumns(session Session, where string, tables map[string]*Table) error {
+	rows, err := query(session, "SELECT table_name, column_name, type, kind, position, clustering_order FROM system_schema.columns"+where)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		t, ok := tables[row["table_name"]]
+		if !ok {
+			continue
+		}
+		position, err := strconv.Atoi(row["position"])
+		if err != nil {
+			return fmt.Errorf("unable to read position of %v.%v with error %v", row["table_name"], row["column_name"], err)
+		}
+		t.Columns = append(t.Columns, Column{
+			Name:            row["column_name"],
+			Type:            row["type"],
+			Kind:            row["kind"],
+			Position:        position,
+			ClusteringOrder: row["clustering_order"],
+		})
+	}
+	for _, t := range tables {
+		sortColumns(t.Columns)
+	}
+	return nil
+}
+
+// sortColumns puts the partition key then the clustering columns in key order, then the other columns by name
+func sortColumns(columns []Column) {
+	rank := map[string]int{"partition_key": 0, "clustering": 1}
+	kindRank := func(c Column) int {
+		if r, ok := rank[c.Kind]; ok {
+			return r
+		}
+		return 2
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		a, b := columns[i], columns[j]
+		if kindRank(a) != kindRank(b) {
+			return kindRank(a) < kindRank(b)
+		}
+		if kindRank(a) < 2 && a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.Name < b.Name
+	})
+}
+
+func loadIndexes(session Session, where string, tables map[string]*Table) error {
+	rows, err := query(session, "SELECT table_name, index_name, kind, options FROM system_schema.indexes"+where)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		t, ok := tables[row["table_name"]]
+		if !ok {
+			continue
+		}
+		options, err := parseMap(row["options"])
+		if err != nil {
+			return err
+		}
+		t.Indexes = append(t.Indexes, Index{Name: row["index_name"], Kind: row["kind"], Options: options})
+	}
+	for _, t := range tables {
+		sort.Slice(t.Indexes, func(i, j int) bool { return t.Indexes[i].Name < t.Indexes[j].Name })
+	}
+	return nil
+}
+
+// query returns the rows of the result by column name
+func query(session Session, statement string) ([]map[string]string, error) {
+	result, err := session.Query(statement)
+	if err != nil {
+		return nil, fmt.Errorf("unable to run '%v' with error %v", statement, err)
+	}
+	var rows []map[string]string
+	for _, values := range result.Rows {
+		row := make(map[string]string, len(result.Columns))
+		for i, column := range result.Columns {
+			if i < len(values) {
+				row[column] = values[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,112 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package schema reads the definitions of keyspaces from system_schema and writes them as CQL DDL
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg/cql"
+)
+
+// systemSchema answers the queries of Load from system_schema rows by table
+type systemSchema map[string]cql.Result
+
+func (s systemSchema) Query(statement string) (cql.Result, error) {
+	for table, result := range s {
+		if strings.Contains(statement, "FROM system_schema."+table+" ") {
+			return result, nil
+		}
+	}
+	return cql.Result{}, errors.New("unexpected statement " + statement)
+}
+
+// shop is a keyspace with a type using another, a table with a composite key and indexes
+func shop() systemSchema {
+	return systemSchema{
+		"keyspaces": {Columns: []string{"replication", "durable_writes"}, Rows: [][]string{
+			{"{'class': 'org.apache.cassandra.locator.NetworkTopologyStrategy', 'us-east1': '3'}", "true"},
+		}},
+		"types": {Columns: []string{"type_name", "field_names", "field_types"}, Rows: [][]string{
+			{"customer", "['name', 'home']", "['text', 'frozen<address>']"},
+			{"address", "['street', 'zip']", "['text', 'int']"},
+		}},
+		"tables": {Columns: []string{"table_name", "comment", "default_time_to_live"}, Rows: [][]string{
+			{"orders", "orders by customer", "86400"},
+			{"Items", "", "0"},
+		}},
+		"columns": {Columns: []string{"table_name", "column_name", "type", "kind", "position", "clustering_order"}, Rows: [][]string{
+			{"orders", "total", "decimal", "regular", "-1", "none"},
+			{"orders", "placed", "timestamp", "clustering", "0", "desc"},
+			{"orders", "region", "text", "partition_key", "1", "none"},
+			{"orders", "customer_id", "uuid", "partition_key", "0", "none"},
+			{"orders", "buyer", "frozen<customer>", "static", "-1", "none"},
+			{"orders", "id", "timeuuid", "clustering", "1", "asc"},
+			{"Items", "sku", "text", "partition_key", "0", "none"},
+			{"Items", "order", "int", "regular", "-1", "none"},
+		}},
+		"indexes": {Columns: []string{"table_name", "index_name", "kind", "options"}, Rows: [][]string{
+			{"orders", "orders_total_idx", "CUSTOM", "{'class_name': 'StorageAttachedIndex', 'case_sensitive': 'false', 'target': 'total'}"},
+			{"orders", "orders_buyer_idx", "COMPOSITES", "{'target': 'buyer'}"},
+		}},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ks, err := Load(shop(), "shop")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !ks.DurableWrites || !strings.Contains(ks.Replication, "'us-east1': '3'") {
+		t.Errorf("unexpected keyspace %+v", ks)
+	}
+	if len(ks.Types) != 2 || ks.Types[0].Name != "address" || ks.Types[1].Fields[1] != (Field{Name: "home", Type: "frozen<address>"}) {
+		t.Errorf("unexpected types %+v", ks.Types)
+	}
+	if len(ks.Tables) != 2 || ks.Tables[0].Name != "Items" || ks.Tables[1].DefaultTimeToLive != 86400 {
+		t.Fatalf("unexpected tables %+v", ks.Tables)
+	}
+	var columns []string
+	for _, c := range ks.Tables[1].Columns {
+		columns = append(columns, c.Name)
+	}
+	expected := []string{"customer_id", "region", "placed", "id", "buyer", "total"}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("expected columns %v but was %v", expected, columns)
+	}
+	if ks.Tables[1].Indexes[0].Name != "orders_buyer_idx" || ks.Tables[1].Indexes[1].Options["case_sensitive"] != "false" {
+		t.Errorf("unexpected indexes %+v", ks.Tables[1].Indexes)
+	}
+}
+
+func TestLoadMissingKeyspace(t *testing.T) {
+	s := shop()
+	s["keyspaces"] = cql.Result{Columns: []string{"replication", "durable_writes"}}
+	if _, err := Load(s, "nope"); err == nil || err.Error() != "keyspace 'nope' not found" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLoadQueryFails(t *testing.T) {
+	s := shop()
+	delete(s, "indexes")
+	_, err := Load(s, "shop")
+	if err == nil || !strings.HasPrefix(err.Error(), "unable to run 'SELECT table_name, index_name, kind, options FROM system_schema.indexes WHERE keyspace_name = 'shop''") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package spec describes databases declaratively and computes the changes needed to reach that description
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+// WaitInterval is the time between two status checks while waiting for a database to be active again
+var WaitInterval = 30 * time.Second
+
+// WaitTries is the number of status checks before giving up on a database becoming active again
+var WaitTries = 60
+
+// Result is the outcome of one action of a plan
+type Result struct {
+	Action  Action
+	Err     error
+	Skipped bool
+	// DryRun is set when the action was only printed because of --dry-run
+	DryRun   bool
+	Duration time.Duration
+}
+
+// Apply executes the actions of the plan in order and waits for the database to be active after every change.
+// When an action fails the remaining actions of the same database are skipped, other databases carry on. Guard is
+// called before a database or a region is removed and the action fails with its error, it can be nil when the plan
+// removes nothing. Under --dry-run the requests are printed by the client and the actions on a database the plan
+// creates are only listed, that database has no id yet
+func Apply(client pkg.Client, plan Plan, out io.Writer, guard func(Action) error) []Result {
+	var results []Result
+	ids := make(map[string]string)
+	blocked := make(map[string]error)
+	notCreated := make(map[string]bool)
+	for _, a := range plan.Actions {
+		if err, ok := blocked[a.Database]; ok {
+			results = append(results, Result{Action: a, Skipped: true, Err: err})
+			continue
+		}
+		if notCreated[a.Database] {
+			fmt.Fprintf(out, "dry run, would %v\n", a.Description())
+			results = append(results, Result{Action: a, DryRun: true})
+			continue
+		}
+		id := a.ID
+		if id == "" {
+			id = ids[a.Database]
+		}
+		fmt.Fprintln(out, a.Description())
+		start := time.Now()
+		var newID string
+		var err error
+		if a.Removes() && guard != nil {
+			err = guard(a)
+		}
+		if err == nil {
+			newID, err = execute(client, a, id)
+		}
+		results = append(results, Result{Action: a, Err: err, DryRun: env.DryRun && err == nil, Duration: time.Since(start)})
+		switch {
+		case err != nil:
+			blocked[a.Database] = fmt.Errorf("skipped because '%v' failed", a.Description())
+		case a.Type == ActionCreate && env.DryRun:
+			notCreated[a.Database] = true
+		case a.Type == ActionCreate && newID == "":
+			blocked[a.Database] = errors.New("skipped because the id of the new database is not known")
+		case a.Type == ActionCreate:
+			ids[a.Database] = newID
+		}
+	}
+	return results
+}
+
+func execute(client pkg.Client, a Action, id string) (string, error) {
+	switch a.Type {
+	case ActionCreate:
+		db, err := client.CreateDb(astra.DatabaseInfoCreate{
+			Name:          a.Spec.Name,
+			Keyspace:      a.Spec.Keyspaces[0],
+			CapacityUnits: DefaultCapacityUnits,
+			Region:        a.Spec.Regions[0],
+			Tier:          astra.Tier(a.Spec.Tier),
+			CloudProvider: astra.CloudProvider(a.Spec.CloudProvider),
+		})
+		return db.Id, err
+	case ActionAddKeyspace:
+		if err := client.AddKeyspaceToDb(id, a.Keyspace); err != nil {
+			return id, err
+		}
+		return id, waitActive(client, id)
+	case ActionResize:
+		if err := client.Resize(id, a.CapacityUnits); err != nil {
+			return id, err
+		}
+		return id, waitActive(client, id)
+	case ActionAddRegion:
+		capacityUnits := DefaultCapacityUnits
+		return id, client.AddDatacenters(id, []astra.Datacenter{{
+			Region:        a.Region,
+			CloudProvider: astra.CloudProvider(a.Spec.CloudProvider),
+			Tier:          astra.Tier(a.Spec.Tier),
+			CapacityUnits: &capacityUnits,
+		}})
+	case ActionRemoveRegion:
+		return id, client.TerminateDatacenter(id, a.DatacenterID)
+	case ActionDelete:
+		return id, client.Terminate(id, false)
+	default:
+		return id, fmt.Errorf("unknown action %v", a.Type)
+	}
+}
+
+// waitActive polls the database until it is active again. Right after a change the database still reports ACTIVE
+// until the change starts, so it waits before the first check like WaitUntil. Under --dry-run nothing changed and
+// there is nothing to wait for
+func waitActive(client pkg.Client, id string) error {
+	if env.DryRun {
+		return nil
+	}
+	var last astra.StatusEnum
+	for i := 0; i < WaitTries; i++ {
+		time.Sleep(WaitInterval)
+		db, err := client.FindDb(id)
+		if err == nil {
+			if db.Status == astra.StatusEnumACTIVE {
+				return nil
+			}
+			if db.Status == astra.StatusEnumERROR {
+				return fmt.Errorf("database %v in error status", id)
+			}
+			last = db.Status
+		}
+	}
+	return fmt.Errorf("database %v still %v after %v", id, last, WaitInterval*time.Duration(WaitTries))
+}
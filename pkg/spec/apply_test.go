@@ -0,0 +1,152 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package spec describes databases declaratively and computes the changes needed to reach that description
+package spec
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg/env"
+	tests "github.com/datastax-labs/astra-cli/pkg/tests"
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestApply(t *testing.T) {
+	WaitInterval = 0
+	d := Database{Name: "app", Keyspaces: []string{"ks1", "ks2"}, Regions: []string{"us-east1"}, Tier: "serverless", CloudProvider: "GCP"}
+	plan := Plan{Actions: createActions(d)}
+	mockClient := &tests.MockClient{
+		Databases: []astra.Database{
+			{Id: "new-id"},
+			{Id: "new-id", Status: astra.StatusEnumMAINTENANCE},
+			{Id: "new-id", Status: astra.StatusEnumACTIVE},
+		},
+	}
+	results := Apply(mockClient, plan, ioutil.Discard, nil)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results but was %v", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("unexpected error %v", r.Err)
+		}
+	}
+	addKeyspace := mockClient.Call(1).([]interface{})
+	if addKeyspace[0] != "new-id" || addKeyspace[1] != "ks2" {
+		t.Errorf("expected keyspace ks2 to be added to new-id but was %v", addKeyspace)
+	}
+	// create, add keyspace and two status checks
+	if len(mockClient.Calls()) != 4 {
+		t.Errorf("expected 4 calls but was %v", len(mockClient.Calls()))
+	}
+}
+
+func TestApplySkipsAfterFailure(t *testing.T) {
+	WaitInterval = 0
+	plan := Plan{Actions: []Action{
+		{Type: ActionAddKeyspace, Database: "app", ID: "abc", Keyspace: "ks2"},
+		{Type: ActionAddKeyspace, Database: "app", ID: "abc", Keyspace: "ks3"},
+		{Type: ActionDelete, Database: "old", ID: "def"},
+	}}
+	mockClient := &tests.MockClient{
+		ErrorQueue: []error{errors.New("keyspace limit")},
+	}
+	results := Apply(mockClient, plan, ioutil.Discard, nil)
+	if results[0].Err == nil {
+		t.Error("expected first action to fail")
+	}
+	if !results[1].Skipped {
+		t.Error("expected second action to be skipped")
+	}
+	if results[2].Err != nil || results[2].Skipped {
+		t.Errorf("expected delete of another database to run but was %v", results[2])
+	}
+}
+
+func TestApplyGuardsRemovals(t *testing.T) {
+	WaitInterval = 0
+	plan := Plan{Actions: []Action{
+		{Type: ActionAddKeyspace, Database: "app", ID: "abc", Keyspace: "ks2"},
+		{Type: ActionRemoveRegion, Database: "app", ID: "abc", Region: "us-west1", DatacenterID: "abc-2"},
+		{Type: ActionDelete, Database: "old", ID: "def"},
+	}}
+	mockClient := &tests.MockClient{
+		Databases: []astra.Database{{Id: "abc", Status: astra.StatusEnumACTIVE}},
+	}
+	var guarded []ActionType
+	results := Apply(mockClient, plan, ioutil.Discard, func(a Action) error {
+		guarded = append(guarded, a.Type)
+		return errors.New("protected")
+	})
+	if len(guarded) != 2 || guarded[0] != ActionRemoveRegion || guarded[1] != ActionDelete {
+		t.Errorf("expected only the removals to be guarded but was %v", guarded)
+	}
+	if results[0].Err != nil {
+		t.Errorf("unexpected error %v", results[0].Err)
+	}
+	if results[1].Err == nil || results[2].Err == nil {
+		t.Errorf("expected the removals to fail but was %v", results[1:])
+	}
+	for _, c := range mockClient.Calls() {
+		if c == "def" {
+			t.Error("expected def not to be terminated")
+		}
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	// long enough for the test to time out if the dry run waited
+	WaitInterval = time.Hour
+	env.DryRun = true
+	t.Cleanup(func() {
+		WaitInterval = 0
+		env.DryRun = false
+	})
+	d := Database{Name: "app", Keyspaces: []string{"ks1", "ks2"}, Regions: []string{"us-east1", "us-west1"}, Tier: "serverless", CloudProvider: "GCP"}
+	plan := Plan{Actions: append(createActions(d), Action{Type: ActionAddKeyspace, Database: "other", ID: "abc", Keyspace: "ks2"})}
+	// the dry run client returns an empty database for the create
+	mockClient := &tests.MockClient{}
+	results := Apply(mockClient, plan, ioutil.Discard, nil)
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results but was %v", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil || r.Skipped || !r.DryRun {
+			t.Errorf("expected '%v' to be a dry run but was %+v", r.Action.Description(), r)
+		}
+	}
+	// the create and the keyspace of the existing database, nothing is sent for the database without an id
+	if len(mockClient.Calls()) != 2 {
+		t.Errorf("expected 2 calls but was %v", mockClient.Calls())
+	}
+}
+
+func TestWaitActiveWaitsBeforeFirstCheck(t *testing.T) {
+	WaitInterval = 20 * time.Millisecond
+	defer func() { WaitInterval = 0 }()
+	mockClient := &tests.MockClient{
+		Databases: []astra.Database{{Id: "abc", Status: astra.StatusEnumACTIVE}},
+	}
+	start := time.Now()
+	if err := waitActive(mockClient, "abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < WaitInterval {
+		t.Errorf("expected a wait of %v before the first check but was %v", WaitInterval, elapsed)
+	}
+}
@@ -0,0 +1,237 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package spec describes databases declaratively and computes the changes needed to reach that description
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+// ActionType is the kind of change an action makes
+type ActionType string
+
+const (
+	// ActionCreate creates a database with its first keyspace and region
+	ActionCreate ActionType = "create"
+	// ActionAddKeyspace adds a keyspace to a database
+	ActionAddKeyspace ActionType = "add-keyspace"
+	// ActionResize changes the capacity units of a database
+	ActionResize ActionType = "resize"
+	// ActionAddRegion adds a datacenter to a database
+	ActionAddRegion ActionType = "add-region"
+	// ActionRemoveRegion terminates a datacenter of a database, only planned with prune
+	ActionRemoveRegion ActionType = "remove-region"
+	// ActionDelete terminates a database, only planned with prune
+	ActionDelete ActionType = "delete"
+)
+
+// Action is a single change to make. ID is empty for databases that are created by the same plan
+type Action struct {
+	Type     ActionType
+	Database string
+	ID       string
+	// Keyspace, Region, DatacenterID and CapacityUnits are set depending on the type
+	Keyspace      string
+	Region        string
+	DatacenterID  string
+	CapacityUnits int
+	Spec          Database
+}
+
+// Description is the human readable form of the action
+func (a Action) Description() string {
+	switch a.Type {
+	case ActionCreate:
+		return fmt.Sprintf("create database %v (%v %v %v, keyspace %v)", a.Database, a.Spec.Tier, a.Spec.CloudProvider, a.Spec.Regions[0], a.Spec.Keyspaces[0])
+	case ActionAddKeyspace:
+		return fmt.Sprintf("add keyspace %v to %v", a.Keyspace, a.Database)
+	case ActionResize:
+		return fmt.Sprintf("resize %v to %v capacity units", a.Database, a.CapacityUnits)
+	case ActionAddRegion:
+		return fmt.Sprintf("add region %v to %v", a.Region, a.Database)
+	case ActionRemoveRegion:
+		return fmt.Sprintf("remove region %v (datacenter %v) from %v", a.Region, a.DatacenterID, a.Database)
+	case ActionDelete:
+		return fmt.Sprintf("delete database %v (%v)", a.Database, a.ID)
+	default:
+		return fmt.Sprintf("%v %v", a.Type, a.Database)
+	}
+}
+
+// Removes is true for the actions that terminate a database or one of its regions
+func (a Action) Removes() bool {
+	return a.Type == ActionDelete || a.Type == ActionRemoveRegion
+}
+
+// Plan is the ordered list of actions that takes the existing databases to the spec
+type Plan struct {
+	Actions []Action
+	// Unchanged are the databases of the spec that already match
+	Unchanged []string
+	// Warnings are differences that cannot be changed by the plan, such as the tier of an existing database
+	Warnings []string
+}
+
+// NewPlan compares the spec with the existing databases, matched by name. Terminated databases are ignored.
+// Regions and databases missing from the spec are only removed when prune is true
+func NewPlan(s Spec, existing []astra.Database, prune bool) (Plan, error) {
+	byName := make(map[string]astra.Database)
+	for _, db := range existing {
+		if db.Status == astra.StatusEnumTERMINATED || db.Status == astra.StatusEnumTERMINATING || db.Info.Name == nil {
+			continue
+		}
+		if other, ok := byName[*db.Info.Name]; ok {
+			return Plan{}, fmt.Errorf("databases %v and %v are both named %v, names must be unique to be managed by a spec", other.Id, db.Id, *db.Info.Name)
+		}
+		byName[*db.Info.Name] = db
+	}
+	var plan Plan
+	for _, d := range s.Databases {
+		db, ok := byName[d.Name]
+		if !ok {
+			plan.Actions = append(plan.Actions, createActions(d)...)
+			continue
+		}
+		delete(byName, d.Name)
+		actions, warnings := updateActions(d, db, prune)
+		plan.Warnings = append(plan.Warnings, warnings...)
+		if len(actions) == 0 {
+			plan.Unchanged = append(plan.Unchanged, d.Name)
+		}
+		plan.Actions = append(plan.Actions, actions...)
+	}
+	if prune {
+		var names []string
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			plan.Actions = append(plan.Actions, Action{Type: ActionDelete, Database: name, ID: byName[name].Id})
+		}
+	}
+	return plan, nil
+}
+
+func createActions(d Database) []Action {
+	actions := []Action{{Type: ActionCreate, Database: d.Name, Spec: d}}
+	// databases are always created with one capacity unit
+	if !strings.EqualFold(d.Tier, DefaultTier) && d.CapacityUnits != nil && *d.CapacityUnits > DefaultCapacityUnits {
+		actions = append(actions, Action{Type: ActionResize, Database: d.Name, CapacityUnits: *d.CapacityUnits})
+	}
+	for _, ks := range d.Keyspaces[1:] {
+		actions = append(actions, Action{Type: ActionAddKeyspace, Database: d.Name, Keyspace: ks})
+	}
+	for _, r := range d.Regions[1:] {
+		actions = append(actions, Action{Type: ActionAddRegion, Database: d.Name, Region: r, Spec: d})
+	}
+	return actions
+}
+
+func updateActions(d Database, db astra.Database, prune bool) ([]Action, []string) {
+	var actions []Action
+	var warnings []string
+	if db.Info.Tier != nil && !strings.EqualFold(string(*db.Info.Tier), d.Tier) {
+		warnings = append(warnings, fmt.Sprintf("%v has tier %v but the spec has %v, the tier of an existing database cannot be changed", d.Name, *db.Info.Tier, d.Tier))
+	}
+	if db.Info.CloudProvider != nil && !strings.EqualFold(string(*db.Info.CloudProvider), d.CloudProvider) {
+		warnings = append(warnings, fmt.Sprintf("%v is on %v but the spec has %v, the cloud provider of an existing database cannot be changed", d.Name, *db.Info.CloudProvider, d.CloudProvider))
+	}
+
+	keyspaces := Keyspaces(db)
+	for _, ks := range d.Keyspaces {
+		if !contains(keyspaces, ks) {
+			actions = append(actions, Action{Type: ActionAddKeyspace, Database: d.Name, ID: db.Id, Keyspace: ks})
+		}
+	}
+
+	// serverless databases scale on their own, capacity units only apply to the classic tiers and only when the spec sets them
+	if !strings.EqualFold(d.Tier, DefaultTier) && d.CapacityUnits != nil && db.Info.CapacityUnits != nil && *db.Info.CapacityUnits != *d.CapacityUnits {
+		actions = append(actions, Action{Type: ActionResize, Database: d.Name, ID: db.Id, CapacityUnits: *d.CapacityUnits})
+	}
+
+	datacenters := Datacenters(db)
+	for _, r := range d.Regions {
+		if _, ok := datacenters[r]; !ok {
+			actions = append(actions, Action{Type: ActionAddRegion, Database: d.Name, ID: db.Id, Region: r, Spec: d})
+		}
+	}
+	var extra []string
+	for r := range datacenters {
+		if !contains(d.Regions, r) {
+			extra = append(extra, r)
+		}
+	}
+	sort.Strings(extra)
+	for _, r := range extra {
+		if prune {
+			actions = append(actions, Action{Type: ActionRemoveRegion, Database: d.Name, ID: db.Id, Region: r, DatacenterID: datacenters[r]})
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%v has region %v which is not in the spec, use --prune to remove it", d.Name, r))
+		}
+	}
+	return actions, warnings
+}
+
+// Keyspaces returns the main keyspace of the database followed by the additional ones
+func Keyspaces(db astra.Database) []string {
+	var keyspaces []string
+	if db.Info.Keyspace != nil {
+		keyspaces = append(keyspaces, *db.Info.Keyspace)
+	}
+	if db.Info.AdditionalKeyspaces != nil {
+		for _, ks := range *db.Info.AdditionalKeyspaces {
+			if !contains(keyspaces, ks) {
+				keyspaces = append(keyspaces, ks)
+			}
+		}
+	}
+	return keyspaces
+}
+
+// Datacenters maps the region of every datacenter that is not terminated to its id. Databases without datacenter
+// information only have their main region
+func Datacenters(db astra.Database) map[string]string {
+	datacenters := make(map[string]string)
+	if db.Info.Datacenters != nil {
+		for _, dc := range *db.Info.Datacenters {
+			if dc.Status == string(astra.StatusEnumTERMINATED) || dc.Status == string(astra.StatusEnumTERMINATING) {
+				continue
+			}
+			var id string
+			if dc.Id != nil {
+				id = *dc.Id
+			}
+			datacenters[dc.Region] = id
+		}
+	}
+	if len(datacenters) == 0 && db.Info.Region != nil {
+		datacenters[*db.Info.Region] = ""
+	}
+	return datacenters
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
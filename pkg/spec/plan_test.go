@@ -0,0 +1,179 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package spec describes databases declaratively and computes the changes needed to reach that description
+package spec
+
+import (
+	"testing"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+func existingDb(id, name, region string, keyspaces ...string) astra.Database {
+	tier := astra.Tier("serverless")
+	cloud := astra.CloudProvider("GCP")
+	additional := keyspaces[1:]
+	return astra.Database{
+		Id:     id,
+		Status: astra.StatusEnumACTIVE,
+		Info: astra.DatabaseInfo{
+			Name:                astra.StringPtr(name),
+			Keyspace:            astra.StringPtr(keyspaces[0]),
+			AdditionalKeyspaces: &additional,
+			Region:              astra.StringPtr(region),
+			Tier:                &tier,
+			CloudProvider:       &cloud,
+		},
+	}
+}
+
+func actionTypes(plan Plan) []ActionType {
+	var types []ActionType
+	for _, a := range plan.Actions {
+		types = append(types, a.Type)
+	}
+	return types
+}
+
+func TestPlanCreate(t *testing.T) {
+	s := Spec{Databases: []Database{
+		{Name: "app", Keyspaces: []string{"ks1", "ks2"}, Regions: []string{"us-east1", "us-west1"}, Tier: "serverless", CloudProvider: "GCP"},
+	}}
+	plan, err := NewPlan(s, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []ActionType{ActionCreate, ActionAddKeyspace, ActionAddRegion}
+	actual := actionTypes(plan)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v but was %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %v but was %v", expected, actual)
+		}
+	}
+	if plan.Actions[2].Region != "us-west1" {
+		t.Errorf("expected us-west1 but was %v", plan.Actions[2].Region)
+	}
+}
+
+func TestPlanUnchanged(t *testing.T) {
+	s := Spec{Databases: []Database{
+		{Name: "app", Keyspaces: []string{"ks1"}, Regions: []string{"us-east1"}, Tier: "serverless", CloudProvider: "GCP"},
+	}}
+	plan, err := NewPlan(s, []astra.Database{existingDb("abc", "app", "us-east1", "ks1", "other")}, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Errorf("expected no actions but was %v", actionTypes(plan))
+	}
+	if len(plan.Unchanged) != 1 || plan.Unchanged[0] != "app" {
+		t.Errorf("expected app to be unchanged but was %v", plan.Unchanged)
+	}
+}
+
+func TestPlanUpdate(t *testing.T) {
+	s := Spec{Databases: []Database{
+		{Name: "app", Keyspaces: []string{"ks1", "ks2"}, Regions: []string{"us-west1"}, Tier: "serverless", CloudProvider: "GCP"},
+	}}
+	existing := existingDb("abc", "app", "us-east1", "ks1")
+	existing.Info.Datacenters = &[]astra.Datacenter{
+		{Id: astra.StringPtr("abc-1"), Region: "us-east1", Status: "ACTIVE"},
+	}
+	plan, err := NewPlan(s, []astra.Database{existing}, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	actual := actionTypes(plan)
+	if len(actual) != 2 || actual[0] != ActionAddKeyspace || actual[1] != ActionAddRegion {
+		t.Errorf("expected add keyspace and add region but was %v", actual)
+	}
+	if len(plan.Warnings) != 1 {
+		t.Errorf("expected a warning for the region not in the spec but was %v", plan.Warnings)
+	}
+
+	plan, err = NewPlan(s, []astra.Database{existing}, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	last := plan.Actions[len(plan.Actions)-1]
+	if last.Type != ActionRemoveRegion || last.DatacenterID != "abc-1" {
+		t.Errorf("expected removal of datacenter abc-1 but was %v", last)
+	}
+}
+
+func TestPlanResizeClassic(t *testing.T) {
+	three := 3
+	s := Spec{Databases: []Database{
+		{Name: "app", Keyspaces: []string{"ks1"}, Regions: []string{"us-east1"}, Tier: "C10", CloudProvider: "GCP", CapacityUnits: &three},
+	}}
+	existing := existingDb("abc", "app", "us-east1", "ks1")
+	tier := astra.Tier("C10")
+	cu := 1
+	existing.Info.Tier = &tier
+	existing.Info.CapacityUnits = &cu
+	plan, err := NewPlan(s, []astra.Database{existing}, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Type != ActionResize || plan.Actions[0].CapacityUnits != 3 {
+		t.Errorf("expected a resize to 3 but was %v", plan.Actions)
+	}
+}
+
+func TestPlanKeepsCapacityWithoutCapacityUnits(t *testing.T) {
+	s := Spec{Databases: []Database{
+		{Name: "app", Keyspaces: []string{"ks1"}, Regions: []string{"us-east1"}, Tier: "C10", CloudProvider: "GCP"},
+	}}
+	existing := existingDb("abc", "app", "us-east1", "ks1")
+	tier := astra.Tier("C10")
+	cu := 3
+	existing.Info.Tier = &tier
+	existing.Info.CapacityUnits = &cu
+	plan, err := NewPlan(s, []astra.Database{existing}, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Errorf("expected no resize when the spec has no capacity units but was %v", plan.Actions)
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	existing := []astra.Database{existingDb("abc", "old", "us-east1", "ks1")}
+	plan, err := NewPlan(Spec{}, existing, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Errorf("expected no deletion without prune but was %v", actionTypes(plan))
+	}
+	plan, err = NewPlan(Spec{}, existing, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Type != ActionDelete || plan.Actions[0].ID != "abc" {
+		t.Errorf("expected deletion of abc but was %v", plan.Actions)
+	}
+}
+
+func TestPlanDuplicateNames(t *testing.T) {
+	existing := []astra.Database{existingDb("abc", "app", "us-east1", "ks1"), existingDb("def", "app", "us-east1", "ks1")}
+	if _, err := NewPlan(Spec{}, existing, false); err == nil {
+		t.Error("expected an error for two databases with the same name")
+	}
+}
@@ -0,0 +1,151 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package spec describes databases declaratively and computes the changes needed to reach that description
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultTier matches the default of db create
+	DefaultTier = "serverless"
+	// DefaultCloudProvider matches the default of db create
+	DefaultCloudProvider = "GCP"
+	// DefaultCapacityUnits matches the capacity db create uses
+	DefaultCapacityUnits = 1
+)
+
+// Spec is the desired state of the databases of an account
+type Spec struct {
+	Databases []Database `json:"databases" yaml:"databases"`
+}
+
+// Database is the desired state of one database. The first keyspace and the first region are the ones the
+// database is created with, the others are added afterwards. Without capacity units the capacity of an existing
+// database is left as it is
+type Database struct {
+	Name          string   `json:"name" yaml:"name"`
+	Keyspaces     []string `json:"keyspaces" yaml:"keyspaces"`
+	CloudProvider string   `json:"cloudProvider" yaml:"cloudProvider"`
+	Regions       []string `json:"regions" yaml:"regions"`
+	Tier          string   `json:"tier" yaml:"tier"`
+	CapacityUnits *int     `json:"capacityUnits,omitempty" yaml:"capacityUnits,omitempty"`
+}
+
+// ReadFile parses the spec at the path, "-" reads from stdin
+func ReadFile(path string) (Spec, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return Spec{}, fmt.Errorf("unable to read spec file '%v' with error '%v'", path, err)
+	}
+	return Parse(b)
+}
+
+// Parse reads a spec in yaml or json (json being valid yaml), applies the defaults and validates it
+func Parse(b []byte) (Spec, error) {
+	var s Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&s); err != nil && err != io.EOF {
+		return Spec{}, fmt.Errorf("unable to parse spec with error '%v'", err)
+	}
+	for i := range s.Databases {
+		s.Databases[i].applyDefaults()
+	}
+	if err := s.Validate(); err != nil {
+		return Spec{}, err
+	}
+	return s, nil
+}
+
+func (d *Database) applyDefaults() {
+	if d.Tier == "" {
+		d.Tier = DefaultTier
+	}
+	if d.CloudProvider == "" {
+		d.CloudProvider = DefaultCloudProvider
+	}
+}
+
+// Validate checks every database has a unique name, at least one keyspace and at least one region
+func (s Spec) Validate() error {
+	names := make(map[string]bool)
+	for i, d := range s.Databases {
+		if d.Name == "" {
+			return fmt.Errorf("database %v in spec has no name", i+1)
+		}
+		if names[d.Name] {
+			return fmt.Errorf("database %v is in the spec more than once", d.Name)
+		}
+		names[d.Name] = true
+		if len(d.Keyspaces) == 0 {
+			return fmt.Errorf("database %v needs at least one keyspace", d.Name)
+		}
+		if len(d.Regions) == 0 {
+			return fmt.Errorf("database %v needs at least one region", d.Name)
+		}
+		if d.CapacityUnits != nil && *d.CapacityUnits < 1 {
+			return fmt.Errorf("database %v needs at least 1 capacity unit", d.Name)
+		}
+	}
+	return nil
+}
+
+// FromDatabase describes an existing database as a spec, leaving out everything set by the server such as the
+// status and the endpoints
+func FromDatabase(db astra.Database) Database {
+	d := Database{
+		Keyspaces: Keyspaces(db),
+	}
+	if db.Info.Name != nil {
+		d.Name = *db.Info.Name
+	}
+	if db.Info.CloudProvider != nil {
+		d.CloudProvider = string(*db.Info.CloudProvider)
+	}
+	if db.Info.Tier != nil {
+		d.Tier = string(*db.Info.Tier)
+	}
+	if db.Info.CapacityUnits != nil {
+		capacityUnits := *db.Info.CapacityUnits
+		d.CapacityUnits = &capacityUnits
+	}
+	var others []string
+	for region := range Datacenters(db) {
+		if db.Info.Region == nil || region != *db.Info.Region {
+			others = append(others, region)
+		}
+	}
+	sort.Strings(others)
+	if db.Info.Region != nil {
+		d.Regions = append(d.Regions, *db.Info.Region)
+	}
+	d.Regions = append(d.Regions, others...)
+	return d
+}
@@ -0,0 +1,66 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package spec describes databases declaratively and computes the changes needed to reach that description
+package spec
+
+import (
+	"testing"
+)
+
+func TestParseYaml(t *testing.T) {
+	s, err := Parse([]byte(`
+databases:
+  - name: app
+    keyspaces: [ks1, ks2]
+    regions: [us-east1]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(s.Databases) != 1 {
+		t.Fatalf("expected 1 database but was %v", len(s.Databases))
+	}
+	d := s.Databases[0]
+	if d.Tier != DefaultTier || d.CloudProvider != DefaultCloudProvider || d.CapacityUnits != nil {
+		t.Errorf("expected defaults to be applied but was %v", d)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	s, err := Parse([]byte(`{"databases":[{"name":"app","keyspaces":["ks"],"regions":["us-east-1"],"cloudProvider":"AWS","tier":"C10","capacityUnits":3}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	d := s.Databases[0]
+	if d.CloudProvider != "AWS" || d.Tier != "C10" || d.CapacityUnits == nil || *d.CapacityUnits != 3 {
+		t.Errorf("unexpected database %v", d)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": `{"databases":[{"name":"app","keyspace":"ks","regions":["r"]}]}`,
+		"no name":       `{"databases":[{"keyspaces":["ks"],"regions":["r"]}]}`,
+		"no keyspace":   `{"databases":[{"name":"app","regions":["r"]}]}`,
+		"no region":     `{"databases":[{"name":"app","keyspaces":["ks"]}]}`,
+		"duplicate":     `{"databases":[{"name":"app","keyspaces":["ks"],"regions":["r"]},{"name":"app","keyspaces":["ks"],"regions":["r"]}]}`,
+		"no capacity":   `{"databases":[{"name":"app","keyspaces":["ks"],"regions":["r"],"capacityUnits":0}]}`,
+	}
+	for name, txt := range cases {
+		if _, err := Parse([]byte(txt)); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxDocumentPageSize is the largest page the Document API returns
+const MaxDocumentPageSize = 20
+
+// Collection is a collection of json documents in a namespace
+type Collection struct {
+	Name             string `json:"name"`
+	UpgradeAvailable bool   `json:"upgradeAvailable"`
+}
+
+// Documents is a page of documents by id, PageState fetches the next page
+type Documents struct {
+	PageState string                     `json:"pageState,omitempty"`
+	Data      map[string]json.RawMessage `json:"data"`
+}
+
+// IDs are the ids of the documents of the page in order
+func (d Documents) IDs() []string {
+	ids := make([]string, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SearchOptions filter, narrow down and page the documents of a search
+type SearchOptions struct {
+	Where     string
+	Fields    []string
+	PageSize  int
+	PageState string
+}
+
+// CollectionsPath is the path of the collections of a namespace or, with a name, of a collection
+func CollectionsPath(namespace string, collection ...string) string {
+	parts := []string{"/api/rest/v2/namespaces", url.PathEscape(namespace), "collections"}
+	for _, c := range collection {
+		parts = append(parts, url.PathEscape(c))
+	}
+	return strings.Join(parts, "/")
+}
+
+// DocumentPath is the path of a document or, with path segments, of a part of it
+func DocumentPath(namespace, collection, id string, path ...string) string {
+	parts := []string{CollectionsPath(namespace, collection), url.PathEscape(id)}
+	for _, p := range path {
+		parts = append(parts, url.PathEscape(p))
+	}
+	return strings.Join(parts, "/")
+}
+
+// ListCollections returns the collections of the namespace
+func (c *Client) ListCollections(namespace string) ([]Collection, error) {
+	var out struct {
+		Data []Collection `json:"data"`
+	}
+	err := c.do(http.MethodGet, CollectionsPath(namespace), nil, &out)
+	return out.Data, err
+}
+
+// CreateCollection adds an empty collection to the namespace
+func (c *Client) CreateCollection(namespace, name string) error {
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return fmt.Errorf("unable to encode collection with error %v", err)
+	}
+	return c.do(http.MethodPost, CollectionsPath(namespace), body, nil)
+}
+
+// DeleteCollection deletes the collection and all of its documents
+func (c *Client) DeleteCollection(namespace, name string) error {
+	return c.do(http.MethodDelete, CollectionsPath(namespace, name), nil, nil)
+}
+
+// GetDocument returns the document, or the part of it under path
+func (c *Client) GetDocument(namespace, collection, id string, path []string) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(http.MethodGet, DocumentPath(namespace, collection, id, path...)+"?raw=true", nil, &out)
+	return out, err
+}
+
+// AddDocument stores the document under a new id and returns the id
+func (c *Client) AddDocument(namespace, collection string, doc []byte) (string, error) {
+	var out struct {
+		DocumentID string `json:"documentId"`
+	}
+	err := c.do(http.MethodPost, CollectionsPath(namespace, collection), doc, &out)
+	return out.DocumentID, err
+}
+
+// ReplaceDocument stores the document under the id, replacing the previous one
+func (c *Client) ReplaceDocument(namespace, collection, id string, doc []byte) error {
+	return c.do(http.MethodPut, DocumentPath(namespace, collection, id), doc, nil)
+}
+
+// UpdateDocument merges the fields passed into the document of the id
+func (c *Client) UpdateDocument(namespace, collection, id string, doc []byte) error {
+	return c.do(http.MethodPatch, DocumentPath(namespace, collection, id), doc, nil)
+}
+
+// DeleteDocument deletes the document of the id
+func (c *Client) DeleteDocument(namespace, collection, id string) error {
+	return c.do(http.MethodDelete, DocumentPath(namespace, collection, id), nil, nil)
+}
+
+// SearchDocuments returns a page of the documents of the collection matching the where clause, all of them without one
+func (c *Client) SearchDocuments(namespace, collection string, opts SearchOptions) (Documents, error) {
+	if opts.Where != "" && !json.Valid([]byte(opts.Where)) {
+		return Documents{}, fmt.Errorf("where clause is not valid json: %v", opts.Where)
+	}
+	var docs Documents
+	path, err := opts.encode()
+	if err != nil {
+		return docs, err
+	}
+	err = c.do(http.MethodGet, CollectionsPath(namespace, collection)+path, nil, &docs)
+	return docs, err
+}
+
+// encode the options as query parameters, the Document API takes fields as a json array
+func (o SearchOptions) encode() (string, error) {
+	params := url.Values{}
+	if o.Where != "" {
+		params.Set("where", o.Where)
+	}
+	if len(o.Fields) > 0 {
+		fields, err := json.Marshal(o.Fields)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode fields with error %v", err)
+		}
+		params.Set("fields", string(fields))
+	}
+	if o.PageSize > 0 {
+		params.Set("page-size", strconv.Itoa(o.PageSize))
+	}
+	if o.PageState != "" {
+		params.Set("page-state", o.PageState)
+	}
+	if len(params) == 0 {
+		return "", nil
+	}
+	return "?" + params.Encode(), nil
+}
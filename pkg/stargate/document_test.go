@@ -0,0 +1,131 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListCollections(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"data":[{"name":"cars","upgradeAvailable":false}]}`)
+	collections, err := client.ListCollections("ns1")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []Collection{{Name: "cars"}}
+	if !reflect.DeepEqual(collections, expected) {
+		t.Errorf("expected %v but was %v", expected, collections)
+	}
+	if (*last)[1] != "/api/rest/v2/namespaces/ns1/collections" {
+		t.Errorf("unexpected uri %v", (*last)[1])
+	}
+}
+
+func TestCreateAndDeleteCollection(t *testing.T) {
+	client, last := restServer(t, http.StatusCreated, "")
+	if err := client.CreateCollection("ns1", "cars"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodPost, "/api/rest/v2/namespaces/ns1/collections", `{"name":"cars"}`}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+	if err := client.DeleteCollection("ns1", "cars"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = []string{http.MethodDelete, "/api/rest/v2/namespaces/ns1/collections/cars", ""}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+}
+
+func TestGetDocument(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"make":"vw"}`)
+	doc, err := client.GetDocument("ns1", "cars", "a 1", []string{"engine", "size"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(doc) != `{"make":"vw"}` {
+		t.Errorf("unexpected document %s", doc)
+	}
+	if (*last)[1] != "/api/rest/v2/namespaces/ns1/collections/cars/a%201/engine/size?raw=true" {
+		t.Errorf("unexpected uri %v", (*last)[1])
+	}
+}
+
+func TestAddDocument(t *testing.T) {
+	client, last := restServer(t, http.StatusCreated, `{"documentId":"generated"}`)
+	id, err := client.AddDocument("ns1", "cars", []byte(`{"make":"vw"}`))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if id != "generated" {
+		t.Errorf("expected generated but was %v", id)
+	}
+	expected := []string{http.MethodPost, "/api/rest/v2/namespaces/ns1/collections/cars", `{"make":"vw"}`}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+}
+
+func TestChangeDocument(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"documentId":"1"}`)
+	if err := client.ReplaceDocument("ns1", "cars", "1", []byte(`{"make":"vw"}`)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if (*last)[0] != http.MethodPut || (*last)[1] != "/api/rest/v2/namespaces/ns1/collections/cars/1" {
+		t.Errorf("unexpected request %v", *last)
+	}
+	if err := client.UpdateDocument("ns1", "cars", "1", []byte(`{"color":"red"}`)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if (*last)[0] != http.MethodPatch || (*last)[2] != `{"color":"red"}` {
+		t.Errorf("unexpected request %v", *last)
+	}
+	if err := client.DeleteDocument("ns1", "cars", "1"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if (*last)[0] != http.MethodDelete || (*last)[1] != "/api/rest/v2/namespaces/ns1/collections/cars/1" {
+		t.Errorf("unexpected request %v", *last)
+	}
+}
+
+func TestSearchDocuments(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"pageState":"next","data":{"b":{"make":"vw"},"a":{"make":"fiat"}}}`)
+	docs, err := client.SearchDocuments("ns1", "cars", SearchOptions{Where: `{"make":{"$eq":"vw"}}`, Fields: []string{"make"}, PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "/api/rest/v2/namespaces/ns1/collections/cars?fields=%5B%22make%22%5D&page-size=2&where=%7B%22make%22%3A%7B%22%24eq%22%3A%22vw%22%7D%7D"
+	if (*last)[1] != expected {
+		t.Errorf("expected %v but was %v", expected, (*last)[1])
+	}
+	if docs.PageState != "next" || !reflect.DeepEqual(docs.IDs(), []string{"a", "b"}) || string(docs.Data["a"]) != `{"make":"fiat"}` {
+		t.Errorf("unexpected documents %v", docs)
+	}
+}
+
+func TestSearchDocumentsInvalidWhere(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{}`)
+	if _, err := client.SearchDocuments("ns1", "cars", SearchOptions{Where: `{make:`}); err == nil {
+		t.Error("expected error for invalid json")
+	}
+	if *last != nil {
+		t.Errorf("expected no request but was %v", *last)
+	}
+}
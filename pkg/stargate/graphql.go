@@ -0,0 +1,81 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GraphQLSchemaPath is the endpoint of the GraphQL API that creates and changes keyspaces and tables
+const GraphQLSchemaPath = "/api/graphql-schema"
+
+// GraphQLPath is the endpoint of the GraphQL API that queries and changes the tables of the keyspace
+func GraphQLPath(keyspace string) string {
+	return "/api/graphql/" + url.PathEscape(keyspace)
+}
+
+// GraphQLRequest is a GraphQL document with its variables
+type GraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLError is an error of a GraphQL response, a response can have data and errors
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is where in the document an error is
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLResponse is the data and the errors of a GraphQL request
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// Err joins the messages of the errors of the response, nil when there are none
+func (r GraphQLResponse) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, e := range r.Errors {
+		messages = append(messages, e.Message)
+	}
+	return fmt.Errorf("%v error(s) in response: %v", len(r.Errors), strings.Join(messages, "; "))
+}
+
+// GraphQL sends the request to the endpoint of path, errors in the response are returned with it and not as an error
+func (c *Client) GraphQL(path string, request GraphQLRequest) (GraphQLResponse, error) {
+	var out GraphQLResponse
+	body, err := json.Marshal(request)
+	if err != nil {
+		return out, fmt.Errorf("unable to encode request with error %v", err)
+	}
+	err = c.do(http.MethodPost, path, body, &out)
+	return out, err
+}
@@ -0,0 +1,58 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGraphQLPath(t *testing.T) {
+	if path := GraphQLPath("ks 1"); path != "/api/graphql/ks%201" {
+		t.Errorf("unexpected path %v", path)
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"data":{"users":{"values":[{"name":"ann"}]}}}`)
+	resp, err := client.GraphQL(GraphQLPath("ks1"), GraphQLRequest{Query: "query { users { values { name } } }", Variables: map[string]interface{}{"id": 1}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodPost, "/api/graphql/ks1", `{"query":"query { users { values { name } } }","variables":{"id":1}}`}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+	if string(resp.Data) != `{"users":{"values":[{"name":"ann"}]}}` || resp.Err() != nil {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	client, _ := restServer(t, http.StatusOK, `{"errors":[{"message":"unknown field","locations":[{"line":1,"column":3}]},{"message":"bad type"}]}`)
+	resp, err := client.GraphQL(GraphQLSchemaPath, GraphQLRequest{Query: "{ nope }"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "2 error(s) in response: unknown field; bad type"
+	if resp.Err() == nil || resp.Err().Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, resp.Err())
+	}
+	if resp.Errors[0].Locations[0] != (GraphQLLocation{Line: 1, Column: 3}) {
+		t.Errorf("unexpected locations %v", resp.Errors[0].Locations)
+	}
+}
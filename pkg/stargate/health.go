@@ -0,0 +1,38 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import "net/http"
+
+// RESTHealthPath lists the keyspaces, a cheap read that goes through the REST API to the database
+const RESTHealthPath = "/api/rest/v2/schemas/keyspaces"
+
+// GraphQLHealthQuery lists the keyspaces through the GraphQL schema API
+const GraphQLHealthQuery = "{ keyspaces { name } }"
+
+// CheckREST returns nil when the REST API answers a request for the keyspaces
+func (c *Client) CheckREST() error {
+	return c.do(http.MethodGet, RESTHealthPath, nil, nil)
+}
+
+// CheckGraphQL returns nil when the GraphQL schema API answers a query for the keyspaces without errors
+func (c *Client) CheckGraphQL() error {
+	resp, err := c.GraphQL(GraphQLSchemaPath, GraphQLRequest{Query: GraphQLHealthQuery})
+	if err != nil {
+		return err
+	}
+	return resp.Err()
+}
@@ -0,0 +1,76 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckREST(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"data":[{"name":"ks1"}]}`)
+	if err := client.CheckREST(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodGet, "/api/rest/v2/schemas/keyspaces", ""}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+}
+
+func TestCheckRESTUnauthorized(t *testing.T) {
+	client, _ := restServer(t, http.StatusUnauthorized, `{"description":"Role unauthorized for operation","code":401}`)
+	err := client.CheckREST()
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("expected a 401 error but was %v", err)
+	}
+}
+
+func TestCheckGraphQL(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"data":{"keyspaces":[{"name":"ks1"}]}}`)
+	if err := client.CheckGraphQL(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodPost, "/api/graphql-schema", `{"query":"{ keyspaces { name } }"}`}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+}
+
+func TestCheckGraphQLErrors(t *testing.T) {
+	client, _ := restServer(t, http.StatusOK, `{"errors":[{"message":"not ready"}]}`)
+	err := client.CheckGraphQL()
+	if err == nil || err.Error() != "1 error(s) in response: not ready" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSetTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	t.Cleanup(ts.Close)
+	client := NewClient(ts.URL, "AstraCS:abc")
+	client.SetTimeout(10 * time.Millisecond)
+	if err := client.CheckREST(); err == nil {
+		t.Error("expected the request to time out")
+	}
+}
@@ -0,0 +1,121 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Rows is a page of rows returned by the REST API, PageState fetches the next page
+type Rows struct {
+	Count     int                      `json:"count"`
+	PageState string                   `json:"pageState,omitempty"`
+	Data      []map[string]interface{} `json:"data"`
+}
+
+// QueryOptions narrow down and page the rows returned
+type QueryOptions struct {
+	Fields    []string
+	PageSize  int
+	PageState string
+}
+
+// RowsPath is the path of a table or, with key values, of the rows of a primary key prefix
+func RowsPath(keyspace, table string, key ...string) string {
+	parts := []string{"/api/rest/v2/keyspaces", url.PathEscape(keyspace), url.PathEscape(table)}
+	for _, k := range key {
+		parts = append(parts, url.PathEscape(k))
+	}
+	return strings.Join(parts, "/")
+}
+
+// GetRows returns the rows of the primary key, key values are in primary key order and can be a prefix
+func (c *Client) GetRows(keyspace, table string, key []string, opts QueryOptions) (Rows, error) {
+	var rows Rows
+	err := c.do(http.MethodGet, RowsPath(keyspace, table, key...)+opts.encode(nil), nil, &rows)
+	return rows, err
+}
+
+// ListRows returns a page of all the rows of the table
+func (c *Client) ListRows(keyspace, table string, opts QueryOptions) (Rows, error) {
+	var rows Rows
+	err := c.do(http.MethodGet, RowsPath(keyspace, table)+"/rows"+opts.encode(nil), nil, &rows)
+	return rows, err
+}
+
+// QueryRows returns the rows matching the where clause, a json document like {"name":{"$eq":"ann"}}
+func (c *Client) QueryRows(keyspace, table, where string, opts QueryOptions) (Rows, error) {
+	if !json.Valid([]byte(where)) {
+		return Rows{}, fmt.Errorf("where clause is not valid json: %v", where)
+	}
+	var rows Rows
+	err := c.do(http.MethodGet, RowsPath(keyspace, table)+opts.encode(url.Values{"where": {where}}), nil, &rows)
+	return rows, err
+}
+
+// AddRow inserts the row, it has to contain the primary key
+func (c *Client) AddRow(keyspace, table string, row []byte) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.do(http.MethodPost, RowsPath(keyspace, table), row, &out)
+	return out, err
+}
+
+// ReplaceRow sets every column of the row of the primary key, columns left out are removed
+func (c *Client) ReplaceRow(keyspace, table string, key []string, row []byte) (map[string]interface{}, error) {
+	return c.writeRow(http.MethodPut, keyspace, table, key, row)
+}
+
+// UpdateRow sets only the columns passed on the row of the primary key
+func (c *Client) UpdateRow(keyspace, table string, key []string, row []byte) (map[string]interface{}, error) {
+	return c.writeRow(http.MethodPatch, keyspace, table, key, row)
+}
+
+func (c *Client) writeRow(method, keyspace, table string, key []string, row []byte) (map[string]interface{}, error) {
+	var out struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err := c.do(method, RowsPath(keyspace, table, key...), row, &out)
+	return out.Data, err
+}
+
+// DeleteRows deletes the rows of the primary key
+func (c *Client) DeleteRows(keyspace, table string, key []string) error {
+	return c.do(http.MethodDelete, RowsPath(keyspace, table, key...), nil, nil)
+}
+
+func (o QueryOptions) encode(params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+	if len(o.Fields) > 0 {
+		params.Set("fields", strings.Join(o.Fields, ","))
+	}
+	if o.PageSize > 0 {
+		params.Set("page-size", strconv.Itoa(o.PageSize))
+	}
+	if o.PageState != "" {
+		params.Set("page-state", o.PageState)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
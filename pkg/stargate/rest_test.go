@@ -0,0 +1,134 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// restServer answers every request with the response and records the method, uri and body of the last one
+func restServer(t *testing.T, status int, response string) (*Client, *[]string) {
+	var last []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = []string{r.Method, r.URL.RequestURI(), string(body)}
+		w.WriteHeader(status)
+		if _, err := io.WriteString(w, response); err != nil {
+			t.Logf("unable to write response %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return NewClient(ts.URL+"/", "AstraCS:abc"), &last
+}
+
+func TestGetRows(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"count":1,"pageState":"next","data":[{"id":1,"name":"ann"}]}`)
+	rows, err := client.GetRows("ks1", "users", []string{"1", "a b"}, QueryOptions{Fields: []string{"id", "name"}, PageSize: 5})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodGet, "/api/rest/v2/keyspaces/ks1/users/1/a%20b?fields=id%2Cname&page-size=5", ""}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+	if rows.Count != 1 || rows.PageState != "next" || rows.Data[0]["name"] != "ann" {
+		t.Errorf("unexpected rows %v", rows)
+	}
+}
+
+func TestListRows(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"count":1,"data":[{"id":1}]}`)
+	rows, err := client.ListRows("ks1", "users", QueryOptions{PageSize: 100, PageState: "p2"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if (*last)[1] != "/api/rest/v2/keyspaces/ks1/users/rows?page-size=100&page-state=p2" {
+		t.Errorf("unexpected uri %v", (*last)[1])
+	}
+	if rows.Count != 1 {
+		t.Errorf("unexpected rows %v", rows)
+	}
+}
+
+func TestListRowsBigint(t *testing.T) {
+	client, _ := restServer(t, http.StatusOK, `{"count":1,"data":[{"id":9007199254740993}]}`)
+	rows, err := client.ListRows("ks1", "users", QueryOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	b, err := json.Marshal(rows.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `[{"id":9007199254740993}]` {
+		t.Errorf("expected the bigint to keep its digits but was %s", b)
+	}
+}
+
+func TestQueryRows(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"count":0,"data":[]}`)
+	if _, err := client.QueryRows("ks1", "users", `{"name":{"$eq":"ann"}}`, QueryOptions{PageState: "abc"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "/api/rest/v2/keyspaces/ks1/users?page-state=abc&where=%7B%22name%22%3A%7B%22%24eq%22%3A%22ann%22%7D%7D"
+	if (*last)[1] != expected {
+		t.Errorf("expected %v but was %v", expected, (*last)[1])
+	}
+}
+
+func TestQueryRowsInvalidWhere(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{}`)
+	if _, err := client.QueryRows("ks1", "users", `{name:`, QueryOptions{}); err == nil {
+		t.Error("expected error for invalid json")
+	}
+	if *last != nil {
+		t.Errorf("expected no request but was %v", *last)
+	}
+}
+
+func TestWriteRows(t *testing.T) {
+	client, last := restServer(t, http.StatusOK, `{"data":{"name":"bob"}}`)
+	data, err := client.UpdateRow("ks1", "users", []string{"1"}, []byte(`{"name":"bob"}`))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodPatch, "/api/rest/v2/keyspaces/ks1/users/1", `{"name":"bob"}`}
+	if !reflect.DeepEqual(*last, expected) || data["name"] != "bob" {
+		t.Errorf("expected %v but was %v %v", expected, *last, data)
+	}
+	if _, err := client.ReplaceRow("ks1", "users", []string{"1"}, []byte(`{"name":"bob"}`)); err != nil || (*last)[0] != http.MethodPut {
+		t.Errorf("expected PUT but was %v %v", *last, err)
+	}
+}
+
+func TestDeleteRows(t *testing.T) {
+	client, last := restServer(t, http.StatusNoContent, "")
+	if err := client.DeleteRows("ks1", "users", []string{"1"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{http.MethodDelete, "/api/rest/v2/keyspaces/ks1/users/1", ""}
+	if !reflect.DeepEqual(*last, expected) {
+		t.Errorf("expected %v but was %v", expected, *last)
+	}
+}
@@ -0,0 +1,174 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+// TokenHeader carries the application token on every request
+const TokenHeader = "X-Cassandra-Token"
+
+// DefaultTimeout bounds every request
+const DefaultTimeout = 30 * time.Second
+
+// appsDomains are the domains of the database APIs by environment
+var appsDomains = map[string]string{
+	"dev":  "apps.astra-dev.datastax.com",
+	"test": "apps.astra-test.datastax.com",
+	"prod": "apps.astra.datastax.com",
+}
+
+// BaseURL is https://<id>-<region>.apps.astra.datastax.com, the root of the APIs of the database. The data endpoint
+// returned with the database is used when there is one, otherwise it is built from the id and region for the environment
+func BaseURL(db astra.Database, env string) (string, error) {
+	if db.DataEndpointUrl != nil && *db.DataEndpointUrl != "" {
+		return strings.TrimSuffix(strings.TrimSuffix(*db.DataEndpointUrl, "/"), "/api/rest"), nil
+	}
+	if db.Info.Region == nil || *db.Info.Region == "" {
+		return "", fmt.Errorf("database '%v' has no region to build its data endpoint from", db.Id)
+	}
+	domain, ok := appsDomains[env]
+	if !ok {
+		domain = appsDomains["prod"]
+	}
+	return fmt.Sprintf("https://%v-%v.%v", db.Id, *db.Info.Region, domain), nil
+}
+
+// Client sends authenticated requests to the APIs of one database
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient calls the APIs under baseURL with the application token
+func NewClient(baseURL, token string) *Client {
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, http: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// SetTimeout bounds every request of the client to timeout instead of DefaultTimeout
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.http.Timeout = timeout
+}
+
+// Error is an error response of an API
+type Error struct {
+	Status      int
+	Description string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v (status %v)", e.Description, e.Status)
+}
+
+// Request describes a call for printing in dry run
+func Request(method, url string, body []byte) string {
+	if len(body) == 0 {
+		return fmt.Sprintf("%v %v", method, url)
+	}
+	return fmt.Sprintf("%v %v %s", method, url, body)
+}
+
+// URL is the full url of the path
+func (c *Client) URL(path string) string {
+	return c.baseURL + path
+}
+
+// do sends the request and decodes the json response into out, out can be nil for empty responses
+func (c *Client) do(method, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, c.URL(path), reader)
+	if err != nil {
+		return fmt.Errorf("unable to create request with error %v", err)
+	}
+	req.Header.Set(TokenHeader, c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to call %v with error %v", c.URL(path), err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read response with error %v", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp.StatusCode, respBody)
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := decode(respBody, out); err != nil {
+		return fmt.Errorf("unable to parse response with error %v", err)
+	}
+	return nil
+}
+
+// decode is json.Unmarshal keeping numbers as json.Number, as a float64 loses the digits of bigint, counter and varint
+// values past 2^53
+func decode(data []byte, out interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// responseError reads the description of the error body, the APIs use description or errors[].message
+func responseError(status int, body []byte) error {
+	var parsed struct {
+		Description string `json:"description"`
+		Message     string `json:"message"`
+		Errors      []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	description := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		switch {
+		case parsed.Description != "":
+			description = parsed.Description
+		case parsed.Message != "":
+			description = parsed.Message
+		case len(parsed.Errors) > 0:
+			description = parsed.Errors[0].Message
+		}
+	}
+	if description == "" {
+		description = http.StatusText(status)
+	}
+	return &Error{Status: status, Description: description}
+}
@@ -0,0 +1,91 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package stargate calls the Stargate APIs of a database: REST, Document and GraphQL
+package stargate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datastax/astra-client-go/v2/astra"
+)
+
+func TestBaseURL(t *testing.T) {
+	region := "us-east1"
+	endpoint := "https://abc-us-east1.apps.astra.datastax.com/api/rest"
+	cases := []struct {
+		name     string
+		db       astra.Database
+		env      string
+		expected string
+	}{
+		{"data endpoint", astra.Database{Id: "abc", DataEndpointUrl: &endpoint}, "prod", "https://abc-us-east1.apps.astra.datastax.com"},
+		{"prod", astra.Database{Id: "abc", Info: astra.DatabaseInfo{Region: &region}}, "prod", "https://abc-us-east1.apps.astra.datastax.com"},
+		{"dev", astra.Database{Id: "abc", Info: astra.DatabaseInfo{Region: &region}}, "dev", "https://abc-us-east1.apps.astra-dev.datastax.com"},
+	}
+	for _, c := range cases {
+		actual, err := BaseURL(c.db, c.env)
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", c.name, err)
+		}
+		if actual != c.expected {
+			t.Errorf("%v: expected %v but was %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestBaseURLNoRegion(t *testing.T) {
+	if _, err := BaseURL(astra.Database{Id: "abc"}, "prod"); err == nil {
+		t.Error("expected error without region")
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		{`{"description":"table not found","code":400}`, "table not found"},
+		{`{"errors":[{"message":"Validation error"}]}`, "Validation error"},
+		{`not json`, "not json"},
+		{``, "Bad Request"},
+	}
+	for _, c := range cases {
+		err := responseError(http.StatusBadRequest, []byte(c.body))
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.Description != c.expected || apiErr.Status != http.StatusBadRequest {
+			t.Errorf("expected %q but was %v", c.expected, err)
+		}
+	}
+}
+
+func TestTokenHeader(t *testing.T) {
+	var token string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get(TokenHeader)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	err := NewClient(ts.URL, "AstraCS:abc").do(http.MethodGet, "/api/rest/v2/keyspaces", nil, nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized but was %v", err)
+	}
+	if token != "AstraCS:abc" {
+		t.Errorf("expected token header but was %q", token)
+	}
+}
@@ -0,0 +1,194 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package test is for test utilies and mocks
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// BundleOptions changes the generated secure bundle, the zero value is a valid bundle
+type BundleOptions struct {
+	Host     string    // defaults to a host on astra.datastax.com
+	Port     int       // port of the metadata service, defaults to 29080
+	Keyspace string    // defaults to ks1
+	NotAfter time.Time // expiry of the client certificate, defaults to a year from now
+	Omit     []string  // files left out of the zip, for example ca.crt
+	// ServerNames are other names the certificate of ServerBundle is issued for, such as the host ids a proxy is
+	// reached by
+	ServerNames []string
+}
+
+// SecureBundle generates a zip laid out like an Astra secure connect bundle with a new CA and client certificate
+func SecureBundle(opts BundleOptions) ([]byte, error) {
+	zipContent, _, _, err := secureBundle(opts)
+	return zipContent, err
+}
+
+// ServerBundle generates a secure bundle and the TLS config of a server on 127.0.0.1 or localhost signed by the CA
+// of the bundle which requires the client certificate of the bundle, so a client using the bundle can connect to it
+func ServerBundle(opts BundleOptions) ([]byte, *tls.Config, error) {
+	zipContent, caCert, caKey, err := secureBundle(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     append([]string{"localhost"}, opts.ServerNames...),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	serverDer, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return zipContent, &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverDer}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// secureBundle returns the zip with the CA that signed the client certificate
+func secureBundle(opts BundleOptions) ([]byte, *x509.Certificate, *ecdsa.PrivateKey, error) {
+	if opts.Host == "" {
+		opts.Host = "2c3bc0d6-5e3e-4d77-81c8-d95a35bdc58b-us-east1.db.astra.datastax.com"
+	}
+	if opts.Port == 0 {
+		opts.Port = 29080
+	}
+	if opts.Keyspace == "" {
+		opts.Keyspace = "ks1"
+	}
+	now := time.Now()
+	if opts.NotAfter.IsZero() {
+		opts.NotAfter = now.AddDate(1, 0, 0)
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca", Organization: []string{"DataStax"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(5, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	caCert, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     opts.NotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDer, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	config, err := json.MarshalIndent(map[string]interface{}{
+		"host":               opts.Host,
+		"port":               opts.Port,
+		"cql_port":           29042,
+		"keyspace":           opts.Keyspace,
+		"localDC":            "dc-1",
+		"caCertLocation":     "./ca.crt",
+		"keyLocation":        "./key",
+		"certLocation":       "./cert",
+		"keyStoreLocation":   "./identity.jks",
+		"keyStorePassword":   "keystorepass",
+		"trustStoreLocation": "./trustStore.jks",
+		"trustStorePassword": "truststorepass",
+		"pfxCertPassword":    "pfxpass",
+	}, "", "  ")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"config.json", config},
+		{"ca.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer})},
+		{"cert", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientDer})},
+		{"key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})},
+		{"cqlshrc", []byte(fmt.Sprintf("[connection]\nhostname = %v\nport = 29042\nssl = true\n\n[ssl]\ncertfile = ./ca.crt\nvalidate = true\nuserkey = ./key\nusercert = ./cert\n", opts.Host))},
+	}
+	omit := make(map[string]bool)
+	for _, name := range opts.Omit {
+		omit[name] = true
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		if omit[f.name] {
+			continue
+		}
+		fw, err := w.Create(f.name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if _, err := fw.Write(f.content); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, nil, err
+	}
+	return buf.Bytes(), caCert, caKey, nil
+}
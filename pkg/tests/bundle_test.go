@@ -0,0 +1,46 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package test is for test utilies and mocks
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestSecureBundleOmit(t *testing.T) {
+	b, err := SecureBundle(BundleOptions{Omit: []string{"cqlshrc"}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	expected := []string{"config.json", "ca.crt", "cert", "key"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %v but was %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("expected %v but was %v", expected[i], names[i])
+		}
+	}
+}
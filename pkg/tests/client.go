@@ -22,11 +22,12 @@ const LoginError = "unable to login with error no db"
 
 // MockClient is used for testing
 type MockClient struct {
-	ErrorQueue []error
-	calls      []interface{}
-	Databases  []astraops.Database
-	Tiers      []astraops.AvailableRegionCombination
-	Bundle     astraops.CredsURL
+	ErrorQueue  []error
+	calls       []interface{}
+	Databases   []astraops.Database
+	Tiers       []astraops.AvailableRegionCombination
+	Bundle      astraops.CredsURL
+	Datacenters []astraops.Datacenter
 }
 
 // getError pops the next error stored off the stack
@@ -117,3 +118,27 @@ func (c *MockClient) GetSecureBundle(id string) (astraops.CredsURL, error) {
 func (c *MockClient) GetTierInfo() ([]astraops.AvailableRegionCombination, error) {
 	return c.Tiers, c.getError()
 }
+
+// ListDatacenters returns the next error and the datacenters stored, the id and all flag are stored
+func (c *MockClient) ListDatacenters(id string, all bool) ([]astraops.Datacenter, error) {
+	c.calls = append(c.calls, []interface{}{id, all})
+	return c.Datacenters, c.getError()
+}
+
+// AddDatacenters returns the next error, the id and datacenters are stored
+func (c *MockClient) AddDatacenters(id string, dcs []astraops.Datacenter) error {
+	c.calls = append(c.calls, []interface{}{id, dcs})
+	return c.getError()
+}
+
+// TerminateDatacenter returns the next error, the id and datacenter id are stored
+func (c *MockClient) TerminateDatacenter(id string, datacenterID string) error {
+	c.calls = append(c.calls, []interface{}{id, datacenterID})
+	return c.getError()
+}
+
+// AddKeyspaceToDb returns the next error, the id and keyspace are stored
+func (c *MockClient) AddKeyspaceToDb(id string, keyspace string) error {
+	c.calls = append(c.calls, []interface{}{id, keyspace})
+	return c.getError()
+}
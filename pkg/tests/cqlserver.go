@@ -0,0 +1,368 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package test is for test utilies and mocks
+package test
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// CQLResult is what the stand-in answers to a query. A message is sent as an error, columns as rows of text
+// and a change as a schema change of the table, otherwise the result is void
+type CQLResult struct {
+	Columns      []string
+	Rows         [][]string
+	ErrorCode    int32 // defaults to a syntax error when there is a message
+	ErrorMessage string
+	Change       string // CREATED, UPDATED or DROPPED
+	Keyspace     string
+	Table        string
+}
+
+// CQLServer is a stand-in for a Cassandra compatible database speaking the native protocol v4. It supports the
+// password authenticator and simple queries. The queries a driver sends about the node itself are answered by the
+// stand-in, every other query is answered by the handler
+type CQLServer struct {
+	username string
+	password string
+	handler  func(query string) CQLResult
+	listener net.Listener
+	mu       sync.Mutex
+	queries  []string
+	prepared []string
+	names    []string
+	conns    map[net.Conn]bool
+	wg       sync.WaitGroup
+}
+
+// NewCQLServer listens on a free port of 127.0.0.1, over TLS when tlsConfig is set. An empty username turns authentication off
+func NewCQLServer(username, password string, tlsConfig *tls.Config, handler func(query string) CQLResult) (*CQLServer, error) {
+	var l net.Listener
+	var err error
+	if tlsConfig != nil {
+		l, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		handler = func(string) CQLResult { return CQLResult{} }
+	}
+	s := &CQLServer{username: username, password: password, handler: handler, listener: l, conns: make(map[net.Conn]bool)}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Addr is the host:port the server listens on
+func (s *CQLServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Queries returns every query the handler answered in order
+func (s *CQLServer) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.queries...)
+}
+
+// ServerNames returns the TLS server name (SNI) of every connection in order
+func (s *CQLServer) ServerNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.names...)
+}
+
+// Close stops listening, closes the open connections and waits for them to be done
+func (s *CQLServer) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *CQLServer) accept() {
+	defer s.wg.Done()
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[c] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(c)
+			c.Close()
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *CQLServer) serve(c net.Conn) {
+	if tlsConn, ok := c.(*tls.Conn); ok {
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.names = append(s.names, tlsConn.ConnectionState().ServerName)
+		s.mu.Unlock()
+	}
+	authenticated := s.username == ""
+	for {
+		header := make([]byte, 9)
+		if _, err := io.ReadFull(c, header); err != nil {
+			return
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header[5:]))
+		if _, err := io.ReadFull(c, body); err != nil {
+			return
+		}
+		stream := header[2:4]
+		var opcode byte
+		var resp []byte
+		switch header[4] {
+		case 0x05:
+			opcode, resp = 0x06, cqlStringMultimap(nil, map[string][]string{"CQL_VERSION": {"3.4.4"}, "PROTOCOL_VERSIONS": {"4/v4"}})
+		case 0x0B:
+			opcode = 0x02
+		case 0x01:
+			opcode = 0x02
+			if !authenticated {
+				opcode, resp = 0x03, cqlString(nil, "org.apache.cassandra.auth.PasswordAuthenticator")
+			}
+		case 0x0F:
+			if string(cqlBytesValue(body)) == "\x00"+s.username+"\x00"+s.password {
+				authenticated = true
+				opcode, resp = 0x10, cqlInt(nil, -1)
+			} else {
+				opcode, resp = cqlError(0x0100, "Provided username and/or password are incorrect")
+			}
+		case 0x07:
+			if !authenticated {
+				opcode, resp = cqlError(0x000A, "not authenticated")
+				break
+			}
+			opcode, resp = s.query(body)
+		case 0x09:
+			opcode, resp = s.prepare(body)
+		case 0x0A:
+			if !authenticated {
+				opcode, resp = cqlError(0x000A, "not authenticated")
+				break
+			}
+			opcode, resp = s.execute(body)
+		default:
+			opcode, resp = cqlError(0x000A, "unsupported opcode")
+		}
+		out := append([]byte{0x84, 0, stream[0], stream[1], opcode}, cqlInt(nil, int32(len(resp)))...)
+		if _, err := c.Write(append(out, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// prepare remembers the statement, the driver prepares DML statements and then executes them by id
+func (s *CQLServer) prepare(body []byte) (byte, []byte) {
+	query, ok := cqlLongString(body)
+	if !ok {
+		return cqlError(0x000A, "short prepare")
+	}
+	s.mu.Lock()
+	s.prepared = append(s.prepared, query)
+	id := len(s.prepared) - 1
+	s.mu.Unlock()
+	resp := cqlInt(nil, 0x0004)
+	resp = cqlString(resp, string([]byte{byte(id >> 8), byte(id)}))
+	// no bound variables and no partition key
+	resp = cqlInt(resp, 0)
+	resp = cqlInt(resp, 0)
+	resp = cqlInt(resp, 0)
+	// the columns of the result come with the rows
+	resp = cqlInt(resp, 0x0004)
+	return 0x08, cqlInt(resp, 0)
+}
+
+func (s *CQLServer) execute(body []byte) (byte, []byte) {
+	if len(body) < 4 || binary.BigEndian.Uint16(body) != 2 {
+		return cqlError(0x000A, "short execute")
+	}
+	id := int(binary.BigEndian.Uint16(body[2:]))
+	s.mu.Lock()
+	known := id < len(s.prepared)
+	var query string
+	if known {
+		query = s.prepared[id]
+	}
+	s.mu.Unlock()
+	if !known {
+		return cqlError(0x000A, "unknown prepared statement")
+	}
+	return s.run(query)
+}
+
+func (s *CQLServer) query(body []byte) (byte, []byte) {
+	query, ok := cqlLongString(body)
+	if !ok {
+		return cqlError(0x000A, "short query")
+	}
+	return s.run(query)
+}
+
+func (s *CQLServer) run(query string) (byte, []byte) {
+	if opcode, resp, ok := driverQuery(query); ok {
+		return opcode, resp
+	}
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+	result := s.handler(query)
+	switch {
+	case result.ErrorMessage != "":
+		code := result.ErrorCode
+		if code == 0 {
+			code = 0x2000
+		}
+		return cqlError(code, result.ErrorMessage)
+	case result.Columns != nil:
+		types := make([]byte, len(result.Columns))
+		for i := range types {
+			types[i] = cqlVarchar
+		}
+		return 0x08, cqlRows(result.Keyspace, result.Table, result.Columns, types, result.Rows)
+	case result.Change != "":
+		resp := cqlInt(nil, 0x0005)
+		resp = cqlString(resp, result.Change)
+		resp = cqlString(resp, "TABLE")
+		resp = cqlString(resp, result.Keyspace)
+		return 0x08, cqlString(resp, result.Table)
+	case strings.HasPrefix(query, "USE "):
+		return 0x08, cqlString(cqlInt(nil, 0x0003), strings.Trim(strings.TrimPrefix(query, "USE "), `"`))
+	default:
+		return 0x08, cqlInt(nil, 0x0001)
+	}
+}
+
+// type ids of the columns the stand-in returns
+const (
+	cqlUUID    = 0x0C
+	cqlVarchar = 0x0D
+)
+
+// hostID and schemaVersion are the uuids of the stand-in node
+const (
+	hostID        = "\x8a\x2f\x3b\x60\x1f\x3e\x4d\x6b\x9a\x53\x3b\x5c\x2e\x2f\x1d\x11"
+	schemaVersion = "\x2c\x3b\xc0\xd6\x5e\x3e\x4d\x77\x81\xc8\xd9\x5a\x35\xbd\xc5\x8b"
+)
+
+// driverQuery answers what a driver asks about the node when it connects and after a schema change, a single node
+// without peers
+func driverQuery(query string) (byte, []byte, bool) {
+	switch {
+	case query == "SELECT schema_version FROM system.local WHERE key='local'":
+		return 0x08, cqlRows("system", "local", []string{"schema_version"}, []byte{cqlUUID}, [][]string{{schemaVersion}}), true
+	case strings.HasPrefix(query, "SELECT * FROM system.local"):
+		columns := []string{"key", "host_id", "schema_version", "data_center", "rack", "release_version"}
+		types := []byte{cqlVarchar, cqlUUID, cqlUUID, cqlVarchar, cqlVarchar, cqlVarchar}
+		return 0x08, cqlRows("system", "local", columns, types, [][]string{{"local", hostID, schemaVersion, "dc-1", "rack-1", "3.11.0"}}), true
+	case strings.HasPrefix(query, "SELECT * FROM system.peers"):
+		return 0x08, cqlRows("system", "peers", []string{"host_id"}, []byte{cqlUUID}, nil), true
+	case query == "SELECT * FROM system_schema.keyspaces":
+		return 0x08, cqlRows("system_schema", "keyspaces", []string{"keyspace_name"}, []byte{cqlVarchar}, nil), true
+	}
+	return 0, nil, false
+}
+
+// cqlRows is a rows result, the values are the bytes of the strings
+func cqlRows(keyspace, table string, columns []string, types []byte, rows [][]string) []byte {
+	resp := cqlInt(nil, 0x0002)
+	resp = cqlInt(resp, 0x0001)
+	resp = cqlInt(resp, int32(len(columns)))
+	resp = cqlString(resp, keyspace)
+	resp = cqlString(resp, table)
+	for i, c := range columns {
+		resp = cqlString(resp, c)
+		resp = append(resp, 0x00, types[i])
+	}
+	resp = cqlInt(resp, int32(len(rows)))
+	for _, row := range rows {
+		for _, v := range row {
+			resp = cqlInt(resp, int32(len(v)))
+			resp = append(resp, v...)
+		}
+	}
+	return resp
+}
+
+func cqlError(code int32, message string) (byte, []byte) {
+	return 0x00, cqlString(cqlInt(nil, code), message)
+}
+
+func cqlInt(b []byte, v int32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func cqlString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s)>>8), byte(len(s))), s...)
+}
+
+func cqlStringMultimap(b []byte, m map[string][]string) []byte {
+	b = append(b, byte(len(m)>>8), byte(len(m)))
+	for k, values := range m {
+		b = cqlString(b, k)
+		b = append(b, byte(len(values)>>8), byte(len(values)))
+		for _, v := range values {
+			b = cqlString(b, v)
+		}
+	}
+	return b
+}
+
+func cqlLongString(b []byte) (string, bool) {
+	if len(b) < 4 {
+		return "", false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if int(n) > len(b)-4 {
+		return "", false
+	}
+	return string(b[4 : 4+n]), true
+}
+
+func cqlBytesValue(b []byte) []byte {
+	if len(b) < 4 {
+		return nil
+	}
+	n := int32(binary.BigEndian.Uint32(b))
+	if n < 0 || int(n) > len(b)-4 {
+		return nil
+	}
+	return b[4 : 4+n]
+}
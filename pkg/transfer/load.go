@@ -0,0 +1,169 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package transfer moves rows between files and tables: reading and writing csv and json lines records, mapping
+// fields to columns and loading with a pool of concurrent inserts
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress counts the rows moved so far
+type Progress struct {
+	Rows     int
+	Rejected int
+	Elapsed  time.Duration
+}
+
+// RowsPerSecond is the throughput of the rows moved, rejected rows included
+func (p Progress) RowsPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Rows+p.Rejected) / p.Elapsed.Seconds()
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("%v rows, %v rejected in %v (%.0f rows/s)", p.Rows, p.Rejected, p.Elapsed.Round(time.Millisecond), p.RowsPerSecond())
+}
+
+// LoadOptions bound the records read ahead and the inserts sent at a time. The REST API inserts one row per request so
+// rows are not grouped into batches, ChunkSize only limits how far reading gets ahead of the inserts
+type LoadOptions struct {
+	// ChunkSize is the most records read and not yet reported, progress is reported every ChunkSize records
+	ChunkSize int
+	// Parallel is the number of inserts sent at a time
+	Parallel int
+}
+
+// loaded is a record with its position in the file and the error that rejects it
+type loaded struct {
+	seq    int
+	record Record
+	err    error
+}
+
+// Load inserts the rows of the records with Parallel inserts at a time, a new insert starts as soon as one finishes.
+// Records that fail to insert or to parse are passed to reject in file order followed by the progress every
+// ChunkSize records. Reading stops at the first error that is not a RecordError
+func Load(reader Reader, mapping Mapping, opts LoadOptions, insert func(map[string]interface{}) error,
+	reject func(Record, error) error, progress func(Progress)) (Progress, error) {
+	if opts.ChunkSize < 1 {
+		opts.ChunkSize = 1
+	}
+	if opts.Parallel < 1 {
+		opts.Parallel = 1
+	}
+	start := time.Now()
+	jobs := make(chan loaded)
+	done := make(chan loaded)
+	// a slot is taken for every record read and given back once it is reported, the records waiting for a slower
+	// one before them are bounded by ChunkSize
+	window := make(chan struct{}, opts.ChunkSize)
+	stop := make(chan struct{})
+	readErr := make(chan error, 1)
+	var workers sync.WaitGroup
+	for i := 0; i < opts.Parallel; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for l := range jobs {
+				l.err = insert(mapping.Row(l.record.Fields))
+				done <- l
+			}
+		}()
+	}
+	go func() {
+		readRecords(reader, jobs, done, window, stop, readErr)
+		close(jobs)
+		workers.Wait()
+		close(done)
+	}()
+	var p Progress
+	pending := make(map[int]loaded)
+	next := 0
+	for l := range done {
+		pending[l.seq] = l
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-window
+			if r.err == nil {
+				p.Rows++
+			} else {
+				p.Rejected++
+				if rejectErr := reject(r.record, r.err); rejectErr != nil {
+					close(stop)
+					go drain(done)
+					return p, fmt.Errorf("unable to write rejected record of line %v with error %v", r.record.Line, rejectErr)
+				}
+			}
+			if (p.Rows+p.Rejected)%opts.ChunkSize == 0 {
+				p.Elapsed = time.Since(start)
+				progress(p)
+			}
+		}
+	}
+	p.Elapsed = time.Since(start)
+	if (p.Rows+p.Rejected)%opts.ChunkSize != 0 {
+		progress(p)
+	}
+	if err := <-readErr; !errors.Is(err, io.EOF) {
+		return p, err
+	}
+	return p, nil
+}
+
+// readRecords sends the records that parsed to the inserts and the ones that did not straight to done, the error
+// that ended reading is sent to readErr unless reading was stopped
+func readRecords(reader Reader, jobs, done chan<- loaded, window chan struct{}, stop <-chan struct{}, readErr chan<- error) {
+	for seq := 0; ; seq++ {
+		select {
+		case <-stop:
+			return
+		case window <- struct{}{}:
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		record, err := reader.Read()
+		var recordErr *RecordError
+		switch {
+		case errors.As(err, &recordErr):
+			done <- loaded{seq: seq, record: Record{Line: recordErr.Line, Raw: recordErr.Raw}, err: recordErr.Err}
+		case err != nil:
+			readErr <- err
+			return
+		default:
+			jobs <- loaded{seq: seq, record: record}
+		}
+	}
+}
+
+// drain discards the records still being inserted after loading stopped early so the inserts can finish
+func drain(done <-chan loaded) {
+	for range done {
+	}
+}
@@ -0,0 +1,183 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package transfer moves rows between files and tables: reading and writing csv and json lines records, mapping
+// fields to columns and loading in concurrent batches
+package transfer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+)
+
+func TestLoad(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("uid,name\n1,ann\n2,bob\n3,bad\n4,dan\n5,eve\n"), pkg.CSVFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var running, maxRunning int
+	var inserted []string
+	insert := func(row map[string]interface{}) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		running--
+		if row["name"] == "bad" {
+			return errors.New("invalid row")
+		}
+		inserted = append(inserted, row["id"].(string))
+		return nil
+	}
+	var rejected []int
+	reject := func(record Record, err error) error {
+		rejected = append(rejected, record.Line)
+		return nil
+	}
+	var reports []Progress
+	p, err := Load(reader, Mapping{"uid": "id", "name": "name"}, LoadOptions{ChunkSize: 2, Parallel: 2}, insert, reject, func(p Progress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p.Rows != 4 || p.Rejected != 1 || len(inserted) != 4 {
+		t.Errorf("unexpected progress %v with inserted %v", p, inserted)
+	}
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 inserts at a time but was %v", maxRunning)
+	}
+	if len(rejected) != 1 || rejected[0] != 4 {
+		t.Errorf("expected line 4 rejected but was %v", rejected)
+	}
+	if len(reports) != 3 || reports[0].Rows != 2 || reports[1].Rejected != 1 {
+		t.Errorf("expected a report every 2 records but was %v", reports)
+	}
+}
+
+func TestLoadRejectsUnparseableRecords(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("{\"id\":1}\nnot json\n{\"id\":3}\n"), pkg.JSONLinesFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	var rejected []Record
+	var causes []string
+	p, err := Load(reader, nil, LoadOptions{ChunkSize: 10, Parallel: 1}, func(map[string]interface{}) error {
+		count++
+		return nil
+	}, func(record Record, err error) error {
+		rejected = append(rejected, record)
+		causes = append(causes, err.Error())
+		return nil
+	}, func(Progress) {})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p.Rows != 2 || p.Rejected != 1 || count != 2 {
+		t.Errorf("expected the other rows to be loaded but was %v", p)
+	}
+	if len(rejected) != 1 || rejected[0].Line != 2 || rejected[0].Raw != "not json" || rejected[0].Fields != nil {
+		t.Errorf("expected line 2 rejected with its text but was %v", rejected)
+	}
+	if len(causes) != 1 || !strings.HasPrefix(causes[0], "unable to parse json object with error") {
+		t.Errorf("unexpected causes %v", causes)
+	}
+}
+
+func TestLoadStopsAtUnreadableRecord(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("id,name\n1,ann\n2,\"bob\n"), pkg.CSVFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	p, err := Load(reader, nil, LoadOptions{ChunkSize: 10, Parallel: 1}, func(map[string]interface{}) error {
+		count++
+		return nil
+	}, func(Record, error) error { return nil }, func(Progress) {})
+	if err == nil || !strings.HasPrefix(err.Error(), "unable to read csv with error") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if p.Rows != 1 || count != 1 {
+		t.Errorf("expected the rows read before to be loaded but was %v", p)
+	}
+}
+
+func TestLoadRejectFails(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("{\"id\":1}\n"), pkg.JSONLinesFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(reader, nil, LoadOptions{}, func(map[string]interface{}) error {
+		return errors.New("invalid")
+	}, func(Record, error) error { return errors.New("disk full") }, func(Progress) {})
+	expected := "unable to write rejected record of line 1 with error disk full"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%v' but was '%v'", expected, err)
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	p := Progress{Rows: 90, Rejected: 10, Elapsed: 2 * time.Second}
+	if p.String() != "90 rows, 10 rejected in 2s (50 rows/s)" {
+		t.Errorf("unexpected progress %v", p.String())
+	}
+	if (Progress{}).RowsPerSecond() != 0 {
+		t.Error("expected no throughput without elapsed time")
+	}
+}
+
+func TestLoadDoesNotWaitForSlowestRow(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("id\n1\n2\n3\n4\n"), pkg.CSVFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	others := make(chan struct{}, 4)
+	insert := func(row map[string]interface{}) error {
+		if row["id"] != "1" {
+			others <- struct{}{}
+			return nil
+		}
+		// the first row only finishes once the other rows went through the second insert
+		for i := 0; i < 3; i++ {
+			select {
+			case <-others:
+			case <-time.After(5 * time.Second):
+				return errors.New("the other rows waited for the first one")
+			}
+		}
+		return nil
+	}
+	p, err := Load(reader, nil, LoadOptions{ChunkSize: 10, Parallel: 2}, insert, func(record Record, err error) error {
+		t.Errorf("unexpected reject of line %v with error %v", record.Line, err)
+		return nil
+	}, func(Progress) {})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p.Rows != 4 {
+		t.Errorf("expected 4 rows but was %v", p)
+	}
+}
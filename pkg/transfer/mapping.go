@@ -0,0 +1,90 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package transfer moves rows between files and tables: reading and writing csv and json lines records, mapping
+// fields to columns and loading in concurrent batches
+package transfer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mapping maps the fields of a file to the columns of a table, an empty mapping uses the field names as columns
+type Mapping map[string]string
+
+// ParseMapping reads field=column pairs
+func ParseMapping(pairs []string) (Mapping, error) {
+	mapping := make(Mapping)
+	columns := make(map[string]string)
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("--map %q is not field=column", pair)
+		}
+		field, column := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if other, ok := columns[column]; ok {
+			return nil, fmt.Errorf("--map maps both %v and %v to column %v", other, field, column)
+		}
+		mapping[field] = column
+		columns[column] = field
+	}
+	return mapping, nil
+}
+
+// Row is the row of the fields of a record, only mapped fields are kept when there is a mapping
+func (m Mapping) Row(fields map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return fields
+	}
+	row := make(map[string]interface{}, len(m))
+	for field, value := range fields {
+		if column, ok := m[field]; ok {
+			row[column] = value
+		}
+	}
+	return row
+}
+
+// Fields are the fields of a record of the row, only mapped columns are kept when there is a mapping
+func (m Mapping) Fields(row map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return row
+	}
+	fields := make(map[string]interface{}, len(m))
+	for field, column := range m {
+		if value, ok := row[column]; ok {
+			fields[field] = value
+		}
+	}
+	return fields
+}
+
+// FieldNames are the fields of the columns, columns that are not mapped are left out when there is a mapping
+func (m Mapping) FieldNames(columns []string) []string {
+	if len(m) == 0 {
+		return columns
+	}
+	byColumn := make(map[string]string, len(m))
+	for field, column := range m {
+		byColumn[column] = field
+	}
+	var fields []string
+	for _, column := range columns {
+		if field, ok := byColumn[column]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
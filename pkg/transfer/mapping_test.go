@@ -0,0 +1,73 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package transfer moves rows between files and tables: reading and writing csv and json lines records, mapping
+// fields to columns and loading in concurrent batches
+package transfer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping([]string{"user_id=id", " full name = name"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := Mapping{"user_id": "id", "full name": "name"}
+	if !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("expected %v but was %v", expected, mapping)
+	}
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	cases := map[string][]string{
+		`--map "id" is not field=column`:       {"id"},
+		`--map "=id" is not field=column`:      {"=id"},
+		"--map maps both a and b to column id": {"a=id", "b=id"},
+	}
+	for expected, pairs := range cases {
+		if _, err := ParseMapping(pairs); err == nil || err.Error() != expected {
+			t.Errorf("expected '%v' but was '%v'", expected, err)
+		}
+	}
+}
+
+func TestMappingRowAndFields(t *testing.T) {
+	mapping := Mapping{"user_id": "id", "full_name": "name"}
+	row := mapping.Row(map[string]interface{}{"user_id": "1", "full_name": "ann", "ignored": "x"})
+	if !reflect.DeepEqual(row, map[string]interface{}{"id": "1", "name": "ann"}) {
+		t.Errorf("unexpected row %v", row)
+	}
+	fields := mapping.Fields(map[string]interface{}{"id": 1, "name": "ann", "age": 3})
+	if !reflect.DeepEqual(fields, map[string]interface{}{"user_id": 1, "full_name": "ann"}) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+	names := mapping.FieldNames([]string{"age", "id", "name"})
+	if !reflect.DeepEqual(names, []string{"user_id", "full_name"}) {
+		t.Errorf("unexpected field names %v", names)
+	}
+}
+
+func TestEmptyMappingKeepsNames(t *testing.T) {
+	var mapping Mapping
+	fields := map[string]interface{}{"id": 1}
+	if !reflect.DeepEqual(mapping.Row(fields), fields) || !reflect.DeepEqual(mapping.Fields(fields), fields) {
+		t.Error("expected the fields as they are")
+	}
+	if names := mapping.FieldNames([]string{"id"}); !reflect.DeepEqual(names, []string{"id"}) {
+		t.Errorf("unexpected field names %v", names)
+	}
+}
@@ -0,0 +1,314 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package transfer moves rows between files and tables: reading and writing csv and json lines records, mapping
+// fields to columns and loading in concurrent batches
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+)
+
+// maxLine is the longest json line read, rows are expected to be far smaller
+const maxLine = 16 * 1024 * 1024
+
+// Record is a record of a file with the line it starts on. A record that could not be parsed has no fields and
+// its text in Raw instead
+type Record struct {
+	Line   int
+	Fields map[string]interface{}
+	Raw    string
+}
+
+// RecordError is returned by Read for a record that could not be parsed, reading goes on with the next record
+type RecordError struct {
+	Line int
+	Raw  string
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+}
+
+// Reader reads the records of a file one at a time, Read returns io.EOF after the last one. Header is the csv header,
+// json lines have none
+type Reader interface {
+	Read() (Record, error)
+	Header() []string
+}
+
+// Writer writes records to a file, Flush has to be called after the last one. WriteRaw writes the text of a record
+// that could not be parsed as it is
+type Writer interface {
+	Write(fields map[string]interface{}) error
+	WriteRaw(raw string) error
+	Flush() error
+}
+
+// Format is the format passed or, without one, the format of the file extension
+func Format(format, file string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".csv":
+			format = pkg.CSVFormat
+		case ".jsonl", ".ndjson":
+			format = pkg.JSONLinesFormat
+		default:
+			return "", fmt.Errorf("unable to tell the format of '%v', pass --format csv or jsonl", file)
+		}
+	}
+	if format != pkg.CSVFormat && format != pkg.JSONLinesFormat {
+		return "", fmt.Errorf("--format %q is not valid option, use csv or jsonl", format)
+	}
+	return format, nil
+}
+
+// NewReader reads csv with a header line, or json lines with an object per line
+func NewReader(r io.Reader, format string) (Reader, error) {
+	if format == pkg.CSVFormat {
+		return newCSVReader(r)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	return &jsonLinesReader{scanner: scanner}, nil
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	header []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	// records with another number of fields than the header are rejected one by one in Read
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("csv file is empty, expected a header line")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read csv header with error %v", err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(h)
+	}
+	return &csvReader{reader: reader, header: header}, nil
+}
+
+func (c *csvReader) Header() []string {
+	return c.header
+}
+
+// Read returns the values of the header fields, empty values are left out so the columns are not set. A record with
+// another number of fields than the header is a RecordError
+func (c *csvReader) Read() (Record, error) {
+	values, err := c.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, err
+		}
+		return Record{}, fmt.Errorf("unable to read csv with error %v", err)
+	}
+	line, _ := c.reader.FieldPos(0)
+	if len(values) != len(c.header) {
+		return Record{}, &RecordError{
+			Line: line,
+			Raw:  csvLine(values),
+			Err:  fmt.Errorf("found %v fields but the header has %v", len(values), len(c.header)),
+		}
+	}
+	fields := make(map[string]interface{})
+	for i, v := range values {
+		if i < len(c.header) && v != "" {
+			fields[c.header[i]] = v
+		}
+	}
+	return Record{Line: line, Fields: fields}, nil
+}
+
+// csvLine is the values written as a csv line without the line break
+func csvLine(values []string) string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(values); err != nil {
+		return strings.Join(values, ",")
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+type jsonLinesReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (j *jsonLinesReader) Header() []string {
+	return nil
+}
+
+// Read returns the object of the next line that is not blank, a line that is not a json object is a RecordError
+func (j *jsonLinesReader) Read() (Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		text := bytes.TrimSpace(j.scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := decode(text, &fields); err != nil {
+			return Record{}, &RecordError{Line: j.line, Raw: string(text), Err: fmt.Errorf("unable to parse json object with error %v", err)}
+		}
+		return Record{Line: j.line, Fields: fields}, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("unable to read line %v with error %v", j.line+1, err)
+	}
+	return Record{}, io.EOF
+}
+
+// decode is json.Unmarshal keeping numbers as json.Number, as a float64 loses the digits of bigint, counter and varint
+// values past 2^53
+func decode(data []byte, out interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// NewWriter writes csv with a header of the columns, or json lines with an object per line. Csv needs the columns
+func NewWriter(w io.Writer, format string, columns []string) Writer {
+	if format == pkg.CSVFormat {
+		return &csvWriter{out: w, writer: csv.NewWriter(w), columns: columns}
+	}
+	return &jsonLinesWriter{writer: bufio.NewWriter(w)}
+}
+
+type csvWriter struct {
+	out         io.Writer
+	writer      *csv.Writer
+	columns     []string
+	wroteHeader bool
+}
+
+// Write writes the header before the first record, missing and null values are empty
+func (c *csvWriter) Write(fields map[string]interface{}) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	values := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		values[i] = FormatValue(fields[column])
+	}
+	return c.writer.Write(values)
+}
+
+// WriteRaw writes the line after the header
+func (c *csvWriter) WriteRaw(raw string) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.out, raw+"\n")
+	return err
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.wroteHeader {
+		return nil
+	}
+	c.wroteHeader = true
+	return c.writer.Write(c.columns)
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type jsonLinesWriter struct {
+	writer *bufio.Writer
+}
+
+func (j *jsonLinesWriter) Write(fields map[string]interface{}) error {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("unable to encode record with error %v", err)
+	}
+	if _, err := j.writer.Write(b); err != nil {
+		return err
+	}
+	return j.writer.WriteByte('\n')
+}
+
+func (j *jsonLinesWriter) WriteRaw(raw string) error {
+	if _, err := j.writer.WriteString(raw); err != nil {
+		return err
+	}
+	return j.writer.WriteByte('\n')
+}
+
+func (j *jsonLinesWriter) Flush() error {
+	return j.writer.Flush()
+}
+
+// FormatValue is text as is, nothing for null and json for everything else
+func FormatValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(b)
+	}
+}
+
+// Columns are the names of the fields of the rows sorted
+func Columns(rows []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for c := range row {
+			if !seen[c] {
+				seen[c] = true
+				columns = append(columns, c)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
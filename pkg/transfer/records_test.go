@@ -0,0 +1,259 @@
+//  Copyright 2022 DataStax
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package transfer moves rows between files and tables: reading and writing csv and json lines records, mapping
+// fields to columns and loading in concurrent batches
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datastax-labs/astra-cli/pkg"
+)
+
+// readAll returns every record of the reader
+func readAll(t *testing.T, reader Reader) []Record {
+	var records []Record
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		file     string
+		expected string
+	}{
+		{"", "users.csv", pkg.CSVFormat},
+		{"", "users.JSONL", pkg.JSONLinesFormat},
+		{"", "users.ndjson", pkg.JSONLinesFormat},
+		{pkg.CSVFormat, "-", pkg.CSVFormat},
+	}
+	for _, c := range cases {
+		format, err := Format(c.format, c.file)
+		if err != nil || format != c.expected {
+			t.Errorf("expected %v for %v but was %v %v", c.expected, c.file, format, err)
+		}
+	}
+	if _, err := Format("", "users.txt"); err == nil || err.Error() != "unable to tell the format of 'users.txt', pass --format csv or jsonl" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := Format("xml", "users.csv"); err == nil || err.Error() != `--format "xml" is not valid option, use csv or jsonl` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("id, name\n1,ann\n2,\"bob, jr\"\n3,\n"), pkg.CSVFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []Record{
+		{Line: 2, Fields: map[string]interface{}{"id": "1", "name": "ann"}},
+		{Line: 3, Fields: map[string]interface{}{"id": "2", "name": "bob, jr"}},
+		{Line: 4, Fields: map[string]interface{}{"id": "3"}},
+	}
+	if records := readAll(t, reader); !reflect.DeepEqual(records, expected) {
+		t.Errorf("expected %v but was %v", expected, records)
+	}
+	if !reflect.DeepEqual(reader.Header(), []string{"id", "name"}) {
+		t.Errorf("unexpected header %v", reader.Header())
+	}
+}
+
+func TestCSVReaderErrors(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), pkg.CSVFormat); err == nil || err.Error() != "csv file is empty, expected a header line" {
+		t.Errorf("unexpected error %v", err)
+	}
+	reader, err := NewReader(strings.NewReader("id,name\n1,\"ann, jr\",extra\n2\n3,cat\n4,\"dan\n"), pkg.CSVFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var recordErr *RecordError
+	if _, err := reader.Read(); !errors.As(err, &recordErr) || err.Error() != "line 2: found 3 fields but the header has 2" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if recordErr.Raw != "1,\"ann, jr\",extra" {
+		t.Errorf("unexpected raw record %q", recordErr.Raw)
+	}
+	if _, err := reader.Read(); !errors.As(err, &recordErr) || recordErr.Line != 3 || recordErr.Raw != "2" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if record, err := reader.Read(); err != nil || record.Line != 4 {
+		t.Errorf("expected reading to go on after the rejected records but was %v %v", record, err)
+	}
+	if _, err := reader.Read(); err == nil || !strings.HasPrefix(err.Error(), "unable to read csv with error") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestJSONLinesReader(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("{\"id\":1,\"tags\":[\"a\"]}\n\n  {\"id\":2}\n"), pkg.JSONLinesFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []Record{
+		{Line: 1, Fields: map[string]interface{}{"id": json.Number("1"), "tags": []interface{}{"a"}}},
+		{Line: 3, Fields: map[string]interface{}{"id": json.Number("2")}},
+	}
+	if records := readAll(t, reader); !reflect.DeepEqual(records, expected) {
+		t.Errorf("expected %v but was %v", expected, records)
+	}
+}
+
+func TestJSONLinesReaderInvalidLine(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("{\"id\":1}\n[1]\n"), pkg.JSONLinesFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var recordErr *RecordError
+	if _, err := reader.Read(); !errors.As(err, &recordErr) || !strings.HasPrefix(err.Error(), "line 2: unable to parse json object") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if recordErr.Raw != "[1]" {
+		t.Errorf("unexpected raw record %q", recordErr.Raw)
+	}
+	if _, err := reader.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected the end of the file but was %v", err)
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{pkg.CSVFormat, "id,name\n1,ann\n2,bob,extra\n"},
+		{pkg.JSONLinesFormat, "{\"id\":\"1\",\"name\":\"ann\"}\n2,bob,extra\n"},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		writer := NewWriter(&out, c.format, []string{"id", "name"})
+		if err := writer.Write(map[string]interface{}{"id": "1", "name": "ann"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.WriteRaw("2,bob,extra"); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != c.expected {
+			t.Errorf("expected %q for %v but was %q", c.expected, c.format, out.String())
+		}
+	}
+	var out bytes.Buffer
+	writer := NewWriter(&out, pkg.CSVFormat, []string{"id", "name"})
+	if err := writer.WriteRaw("2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "id,name\n2\n" {
+		t.Errorf("expected the header before the first raw record but was %q", out.String())
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out, pkg.CSVFormat, []string{"id", "name", "tags"})
+	rows := []map[string]interface{}{
+		{"id": 1.0, "name": "ann, a", "tags": []interface{}{"a"}},
+		{"id": 2.0, "name": nil},
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,name,tags\n1,\"ann, a\",\"[\"\"a\"\"]\"\n2,,\n"
+	if out.String() != expected {
+		t.Errorf("expected %q but was %q", expected, out.String())
+	}
+}
+
+func TestJSONLinesWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out, pkg.JSONLinesFormat, nil)
+	if err := writer.Write(map[string]interface{}{"name": "ann", "id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "{\"id\":1,\"name\":\"ann\"}\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestColumns(t *testing.T) {
+	columns := Columns([]map[string]interface{}{{"name": "ann", "id": 1}, {"age": 3}})
+	if !reflect.DeepEqual(columns, []string{"age", "id", "name"}) {
+		t.Errorf("unexpected columns %v", columns)
+	}
+}
+
+func TestJSONLinesRoundTripBigint(t *testing.T) {
+	line := `{"id":9007199254740993,"score":1.25}`
+	reader, err := NewReader(strings.NewReader(line+"\n"), pkg.JSONLinesFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	records := readAll(t, reader)
+	var out bytes.Buffer
+	writer := NewWriter(&out, pkg.JSONLinesFormat, nil)
+	if err := writer.Write(records[0].Fields); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out.String() != line+"\n" {
+		t.Errorf("expected %v but was %v", line, out.String())
+	}
+	if v := FormatValue(records[0].Fields["id"]); v != "9007199254740993" {
+		t.Errorf("expected the csv value 9007199254740993 but was %v", v)
+	}
+}
+
+func TestJSONLinesReaderTrailingData(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(`{"id":1} {"id":2}`+"\n"), pkg.JSONLinesFormat)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := reader.Read(); err == nil {
+		t.Error("expected an error for two objects on one line")
+	}
+}